@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+
+	"gorm.io/gorm"
+
+	intdb "github.com/Karielka/Hackaton_MAX/internal/db"
+	"github.com/Karielka/Hackaton_MAX/internal/fixtures"
+	"github.com/Karielka/Hackaton_MAX/internal/importer"
+	"github.com/Karielka/Hackaton_MAX/internal/migrate"
+	"github.com/Karielka/Hackaton_MAX/services"
+)
+
+// Подкоманды: ./app <команда> [флаги]. Без аргументов запускается бот.
+
+const usage = `usage: app [command]
+
+commands:
+  (none)                                   run the bot (the schema must be migrated)
+  migrate up | down [-n N] | to <version> | status
+                                           apply, roll back or list schema migrations
+  fixtures [-force]                        load sample data; only with APP_ENV=development unless -force
+  import-staff [-dry-run] [-format csv|xlsx] <file>
+                                           import institutes, faculties, departments and teachers
+  import-timetable [-dry-run] [-format ics|json] [-semester name] <file>
+                                           replace a semester timetable with the file contents
+`
+
+// runCommand выполняет подкоманду и возвращает код выхода
+func runCommand(name string, args []string) int {
+	switch name {
+	case "migrate":
+		return cmdMigrate(args)
+	case "fixtures":
+		return cmdFixtures(args)
+	case "import-staff":
+		return cmdImportStaff(args)
+	case "import-timetable":
+		return cmdImportTimetable(args)
+	case "-h", "-help", "--help", "help":
+		fmt.Print(usage)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", name, usage)
+		return 2
+	}
+}
+
+// cmdImportStaff - таблица сотрудников в базу; с -dry-run только показывает diff.
+// Код 1 — файл не принят или в отчёте есть ошибки строк.
+func cmdImportStaff(args []string) int {
+	fs := flag.NewFlagSet("import-staff", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "show changes without saving them")
+	format := fs.String("format", "", "csv or xlsx (default: by file extension)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+	path := fs.Arg(0)
+	if *format == "" {
+		*format = importer.FormatByName(path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	db := intdb.Connect()
+	if err := checkSchema(context.Background(), db); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	rep, err := importer.ImportStaff(context.Background(), db, data, *format, importer.StaffOptions{DryRun: *dryRun})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	rep.WriteText(os.Stdout)
+	if len(rep.Errors) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// cmdImportTimetable - расписание семестра из .ics или JSON; с -dry-run только показывает diff
+func cmdImportTimetable(args []string) int {
+	fs := flag.NewFlagSet("import-timetable", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "show changes without saving them")
+	format := fs.String("format", "", "ics or json (default: by file extension)")
+	semester := fs.String("semester", "", "semester name, e.g. \"Осень 2025\" (required for .ics)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+	path := fs.Arg(0)
+	if *format == "" {
+		*format = importer.FormatByName(path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	db := intdb.Connect()
+	if err := checkSchema(context.Background(), db); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	rep, err := importer.ImportTimetable(context.Background(), db, data, *format, importer.TimetableOptions{
+		DryRun: *dryRun, Semester: *semester, Location: services.Location,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	rep.WriteText(os.Stdout)
+	if len(rep.Errors) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// cmdMigrate - версионные миграции схемы (internal/migrate)
+func cmdMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+	fs := flag.NewFlagSet("migrate "+args[0], flag.ContinueOnError)
+	steps := fs.Int("n", 1, "how many migrations to roll back")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	ctx := context.Background()
+	db := intdb.Connect()
+	m, err := migrate.New(db)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	var done []migrate.Migration
+	switch {
+	case args[0] == "up" && fs.NArg() == 0:
+		done, err = m.Up(ctx)
+	case args[0] == "down" && fs.NArg() == 0:
+		done, err = m.Down(ctx, *steps)
+	case args[0] == "to" && fs.NArg() == 1:
+		version, perr := strconv.ParseInt(fs.Arg(0), 10, 64)
+		if perr != nil {
+			fmt.Fprintf(os.Stderr, "bad version %q\n", fs.Arg(0))
+			return 2
+		}
+		done, err = m.To(ctx, version)
+	case args[0] == "status" && fs.NArg() == 0:
+		list, err := m.Status(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		for _, st := range list {
+			applied := "pending"
+			if st.AppliedAt != nil {
+				applied = "applied " + st.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%s\t%s\n", st.Version, st.Name, applied)
+		}
+		return 0
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	for _, mg := range done {
+		fmt.Printf("%s %04d_%s\n", args[0], mg.Version, mg.Name)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if len(done) == 0 {
+		fmt.Println("nothing to do")
+	}
+	return 0
+}
+
+// cmdFixtures - демонстрационные данные. Без -force работает только при APP_ENV=development,
+// иначе выходит с кодом 0, ничего не делая: так шаг можно оставить в docker-compose.
+func cmdFixtures(args []string) int {
+	fs := flag.NewFlagSet("fixtures", flag.ContinueOnError)
+	force := fs.Bool("force", false, "load even if APP_ENV is not development")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+	if env := os.Getenv("APP_ENV"); env != "development" && !*force {
+		fmt.Printf("fixtures skipped: APP_ENV=%q (want development or -force)\n", env)
+		return 0
+	}
+
+	ctx := context.Background()
+	db := intdb.Connect()
+	if err := checkSchema(ctx, db); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := fixtures.LoadSample(db.WithContext(ctx)); err != nil {
+		fmt.Fprintln(os.Stderr, "fixtures:", err)
+		return 1
+	}
+	fmt.Println("sample fixtures loaded")
+	return 0
+}
+
+// checkSchema - ошибка, если в базе применены не все миграции
+func checkSchema(ctx context.Context, db *gorm.DB) error {
+	m, err := migrate.New(db)
+	if err != nil {
+		return err
+	}
+	list, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(list, func(st migrate.Status) bool { return st.AppliedAt != nil }) {
+		return errors.New("database is not migrated: run \"app migrate up\"")
+	}
+	for _, st := range list {
+		if st.AppliedAt == nil {
+			return fmt.Errorf("database schema is out of date (migration %04d_%s is pending): run \"app migrate up\"", st.Version, st.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Karielka/Hackaton_MAX/internal/calendar"
+)
+
+// Подписка на расписание (calendar.TeacherPath, calendar.GroupPath) открыта без токена:
+// календарные приложения не умеют слать Authorization, а расписание и так публичное.
+func registerCalendars(s *Server) {
+	s.mux.HandleFunc("GET /calendar/teacher/{file}", s.calendar(calendar.ForTeacher))
+	s.mux.HandleFunc("GET /calendar/group/{file}", s.calendar(calendar.ForGroup))
+}
+
+// calendar - GET …/{id}.ics: расписание текущего семестра в формате iCalendar
+func (s *Server) calendar(load calendar.Loader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		raw, ok := strings.CutSuffix(r.PathValue("file"), ".ics")
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if !ok || err != nil || id == 0 {
+			writeError(w, http.StatusNotFound, "not found")
+			return
+		}
+		loc := s.loc
+		if loc == nil {
+			loc = time.Local
+		}
+		cal, err := load(s.db.WithContext(r.Context()), uint(id), time.Now().In(loc), loc)
+		if errors.Is(err, calendar.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "not found")
+			return
+		}
+		if err != nil {
+			writeInternal(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="%d.ics"`, id))
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		_, _ = w.Write(cal.Encode())
+	}
+}
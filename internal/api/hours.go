@@ -0,0 +1,288 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// Режим работы мест и деканатов, общие праздники:
+//
+//	GET/PUT /places/{id}/hours         недельный режим и особые дни места
+//	GET/PUT /dean-offices/{id}/hours   то же для деканата
+//	GET/PUT /holidays                  особые дни для всех сразу
+//
+// PUT заменяет всё целиком.
+func registerHours(s *Server) {
+	place := hoursOwner{col: "place_id", model: &models.Place{}}
+	dean := hoursOwner{col: "dean_office_id", model: &models.DeanOffice{}}
+	s.handle("GET", "/places/{id}/hours", s.getHours(place))
+	s.handle("PUT", "/places/{id}/hours", s.putHours(place))
+	s.handle("GET", "/dean-offices/{id}/hours", s.getHours(dean))
+	s.handle("PUT", "/dean-offices/{id}/hours", s.putHours(dean))
+	s.handle("GET", "/holidays", s.getHolidays)
+	s.handle("PUT", "/holidays", s.putHolidays)
+}
+
+// hoursOwner - чей режим работы: колонка ссылки и модель для проверки существования
+type hoursOwner struct {
+	col   string
+	model any
+}
+
+// hoursBody - режим работы в запросах и ответах.
+// В PUT вместо weekly можно передать text в формате поля schedule («Пн-Пт 9:00 - 17:00»).
+type hoursBody struct {
+	Text       string          `json:"text,omitempty"`
+	Weekly     []weeklyBody    `json:"weekly"`
+	Exceptions []exceptionBody `json:"exceptions"`
+}
+
+type weeklyBody struct {
+	Weekday int    `json:"weekday"` // 1 — Пн … 7 — Вс
+	Open    string `json:"open"`    // «9:00»
+	Close   string `json:"close"`   // «17:00», не позже «24:00»
+}
+
+type exceptionBody struct {
+	Date   string `json:"date"` // 2006-01-02
+	Closed bool   `json:"closed"`
+	Open   string `json:"open,omitempty"`
+	Close  string `json:"close,omitempty"`
+	Note   string `json:"note,omitempty"`
+}
+
+func (s *Server) getHours(owner hoursOwner) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := s.hoursOwnerID(w, r, owner)
+		if !ok {
+			return
+		}
+		db := s.db.WithContext(r.Context())
+		var weekly []models.OpeningHours
+		if err := db.Where(owner.col+" = ?", id).Order("weekday, open_minute").Find(&weekly).Error; err != nil {
+			writeInternal(w, r, err)
+			return
+		}
+		var exceptions []models.HoursException
+		if err := db.Where(owner.col+" = ?", id).Order("date").Find(&exceptions).Error; err != nil {
+			writeInternal(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, newHoursBody(weekly, exceptions))
+	}
+}
+
+func (s *Server) putHours(owner hoursOwner) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := s.hoursOwnerID(w, r, owner)
+		if !ok {
+			return
+		}
+		var body hoursBody
+		if err := decodeBody(w, r, &body); err != nil {
+			writeError(w, http.StatusBadRequest, "bad json: "+err.Error())
+			return
+		}
+		weekly, exceptions, ok := checkHours(w, body, true)
+		if !ok {
+			return
+		}
+		for i := range weekly {
+			setHoursOwner(&weekly[i].PlaceID, &weekly[i].DeanOfficeID, owner.col, id)
+		}
+		for i := range exceptions {
+			setHoursOwner(&exceptions[i].PlaceID, &exceptions[i].DeanOfficeID, owner.col, id)
+		}
+		err := s.db.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where(owner.col+" = ?", id).Delete(&models.OpeningHours{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where(owner.col+" = ?", id).Delete(&models.HoursException{}).Error; err != nil {
+				return err
+			}
+			return createHours(tx, weekly, exceptions)
+		})
+		if err != nil {
+			writeInternal(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, newHoursBody(weekly, exceptions))
+	}
+}
+
+func (s *Server) getHolidays(w http.ResponseWriter, r *http.Request) {
+	var exceptions []models.HoursException
+	if err := s.db.WithContext(r.Context()).Where("place_id IS NULL AND dean_office_id IS NULL").
+		Order("date").Find(&exceptions).Error; err != nil {
+		writeInternal(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"exceptions": newHoursBody(nil, exceptions).Exceptions})
+}
+
+func (s *Server) putHolidays(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Exceptions []exceptionBody `json:"exceptions"`
+	}
+	if err := decodeBody(w, r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, "bad json: "+err.Error())
+		return
+	}
+	_, exceptions, ok := checkHours(w, hoursBody{Exceptions: body.Exceptions}, false)
+	if !ok {
+		return
+	}
+	err := s.db.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("place_id IS NULL AND dean_office_id IS NULL").Delete(&models.HoursException{}).Error; err != nil {
+			return err
+		}
+		return createHours(tx, nil, exceptions)
+	})
+	if err != nil {
+		writeInternal(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"exceptions": newHoursBody(nil, exceptions).Exceptions})
+}
+
+// hoursOwnerID - id из пути, если такая запись есть; ответ об ошибке уже отправлен, если ok == false
+func (s *Server) hoursOwnerID(w http.ResponseWriter, r *http.Request, owner hoursOwner) (uint, bool) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return 0, false
+	}
+	var n int64
+	if err := s.db.WithContext(r.Context()).Model(owner.model).Where("id = ?", id).Count(&n).Error; err != nil {
+		writeInternal(w, r, err)
+		return 0, false
+	}
+	if n == 0 {
+		writeError(w, http.StatusNotFound, "not found")
+		return 0, false
+	}
+	return id, true
+}
+
+// checkHours проверяет тело и переводит его в модели; при ошибках отвечает 422 с полями
+func checkHours(w http.ResponseWriter, body hoursBody, withWeekly bool) ([]models.OpeningHours, []models.HoursException, bool) {
+	v := &validator{}
+	var weekly []models.OpeningHours
+	if withWeekly {
+		if strings.TrimSpace(body.Text) != "" {
+			if len(body.Weekly) > 0 {
+				v.fail("text", "pass either text or weekly")
+			}
+			parsed, err := models.ParseOpeningHours(body.Text)
+			if err != nil {
+				v.fail("text", err.Error())
+			}
+			weekly = parsed
+		}
+		for i, wb := range body.Weekly {
+			field := fmt.Sprintf("weekly[%d]", i)
+			if wb.Weekday < 1 || wb.Weekday > 7 {
+				v.fail(field+".weekday", "must be 1 (Mon) .. 7 (Sun)")
+			}
+			opens, closes, ok := checkInterval(v, field, wb.Open, wb.Close)
+			if ok {
+				weekly = append(weekly, models.OpeningHours{Weekday: wb.Weekday, OpenMinute: opens, CloseMinute: closes})
+			}
+		}
+	}
+
+	exceptions := make([]models.HoursException, 0, len(body.Exceptions))
+	for i, eb := range body.Exceptions {
+		field := fmt.Sprintf("exceptions[%d]", i)
+		e := models.HoursException{Closed: eb.Closed, Note: strings.TrimSpace(eb.Note)}
+		date, err := time.Parse(time.DateOnly, strings.TrimSpace(eb.Date))
+		if err != nil {
+			v.fail(field+".date", "must be YYYY-MM-DD")
+		}
+		e.Date = date
+		if !eb.Closed {
+			e.OpenMinute, e.CloseMinute, _ = checkInterval(v, field, eb.Open, eb.Close)
+		}
+		exceptions = append(exceptions, e)
+	}
+
+	if len(v.fields) > 0 {
+		writeJSON(w, http.StatusUnprocessableEntity, errorBody{Error: "validation failed", Fields: v.fields})
+		return nil, nil, false
+	}
+	return weekly, exceptions, true
+}
+
+// checkInterval - «9:00»–«17:00» в минутах от полуночи
+func checkInterval(v *validator, field, from, to string) (int, int, bool) {
+	opens, ok1 := parseClock(from)
+	if !ok1 {
+		v.fail(field+".open", "must be a time H:MM")
+	}
+	closes, ok2 := parseClock(to)
+	if !ok2 {
+		v.fail(field+".close", "must be a time H:MM, at most 24:00")
+	}
+	if ok1 && ok2 && closes <= opens {
+		v.fail(field+".close", "must be later than open")
+		return 0, 0, false
+	}
+	return opens, closes, ok1 && ok2
+}
+
+// parseClock - «9:00» или «24:00» в минуты от полуночи
+func parseClock(s string) (int, bool) {
+	h, m, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok || len(m) != 2 {
+		return 0, false
+	}
+	hh, err1 := strconv.Atoi(h)
+	mm, err2 := strconv.Atoi(m)
+	if err1 != nil || err2 != nil || hh < 0 || mm < 0 || mm > 59 || hh*60+mm > 24*60 {
+		return 0, false
+	}
+	return hh*60 + mm, true
+}
+
+func setHoursOwner(placeID, deanOfficeID **uint, col string, id uint) {
+	if col == "place_id" {
+		*placeID = &id
+	} else {
+		*deanOfficeID = &id
+	}
+}
+
+func createHours(tx *gorm.DB, weekly []models.OpeningHours, exceptions []models.HoursException) error {
+	if len(weekly) > 0 {
+		if err := tx.Create(&weekly).Error; err != nil {
+			return err
+		}
+	}
+	if len(exceptions) > 0 {
+		return tx.Create(&exceptions).Error
+	}
+	return nil
+}
+
+func newHoursBody(weekly []models.OpeningHours, exceptions []models.HoursException) hoursBody {
+	out := hoursBody{Weekly: []weeklyBody{}, Exceptions: []exceptionBody{}}
+	for _, h := range weekly {
+		out.Weekly = append(out.Weekly, weeklyBody{
+			Weekday: h.Weekday, Open: models.FormatMinute(h.OpenMinute), Close: models.FormatMinute(h.CloseMinute),
+		})
+	}
+	for _, e := range exceptions {
+		eb := exceptionBody{Date: e.Date.Format(time.DateOnly), Closed: e.Closed, Note: e.Note}
+		if !e.Closed {
+			eb.Open, eb.Close = models.FormatMinute(e.OpenMinute), models.FormatMinute(e.CloseMinute)
+		}
+		out.Exceptions = append(out.Exceptions, eb)
+	}
+	return out
+}
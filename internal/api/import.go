@@ -0,0 +1,114 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/Karielka/Hackaton_MAX/internal/importer"
+)
+
+// максимальный размер загружаемого файла
+const maxImportBytes = 10 << 20
+
+// форматы по Content-Type тела запроса
+var importContentTypes = map[string]string{
+	"text/csv":   importer.FormatCSV,
+	"text/plain": importer.FormatCSV,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": importer.FormatXLSX,
+	"text/calendar":    importer.FormatICS,
+	"application/json": importer.FormatJSON,
+}
+
+func registerImports(s *Server) {
+	s.handle("POST", "/import/staff", s.importStaff)
+	s.handle("POST", "/import/timetable", s.importTimetable)
+}
+
+// importStaff - POST /import/staff?dry_run=true: таблица сотрудников (CSV или XLSX).
+// Ответ — importer.Report; ошибки строк не мешают применить остальные.
+func (s *Server) importStaff(w http.ResponseWriter, r *http.Request) {
+	data, format, dryRun, ok := readImport(w, r)
+	if !ok {
+		return
+	}
+	rep, err := importer.ImportStaff(r.Context(), s.db, data, format, importer.StaffOptions{DryRun: dryRun})
+	writeReport(w, r, rep, err)
+}
+
+// importTimetable - POST /import/timetable?dry_run=true&semester=…: расписание (.ics или JSON)
+func (s *Server) importTimetable(w http.ResponseWriter, r *http.Request) {
+	data, format, dryRun, ok := readImport(w, r)
+	if !ok {
+		return
+	}
+	rep, err := importer.ImportTimetable(r.Context(), s.db, data, format, importer.TimetableOptions{
+		DryRun: dryRun, Semester: r.URL.Query().Get("semester"), Location: s.loc,
+	})
+	writeReport(w, r, rep, err)
+}
+
+func writeReport(w http.ResponseWriter, r *http.Request, rep *importer.Report, err error) {
+	if errors.Is(err, importer.ErrBadFile) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		writeInternal(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, rep)
+}
+
+// readImport - файл из тела запроса или поля file формы multipart/form-data,
+// его формат (?format=, иначе по Content-Type или имени файла) и ?dry_run.
+// Если ok == false, ответ с ошибкой уже отправлен.
+func readImport(w http.ResponseWriter, r *http.Request) (data []byte, format string, dryRun bool, ok bool) {
+	if raw := r.URL.Query().Get("dry_run"); raw != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			writeError(w, http.StatusBadRequest, "dry_run must be true or false")
+			return nil, "", false, false
+		}
+	}
+
+	data, format, err := readUpload(w, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return nil, "", false, false
+	}
+	if f := r.URL.Query().Get("format"); f != "" {
+		format = f
+	}
+	if format == "" {
+		writeError(w, http.StatusBadRequest, "unknown file format: pass ?format= or a matching Content-Type")
+		return nil, "", false, false
+	}
+	return data, format, dryRun, true
+}
+
+func readUpload(w http.ResponseWriter, r *http.Request) ([]byte, string, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+	ctype, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	if ctype == "multipart/form-data" {
+		f, hdr, err := r.FormFile("file")
+		if err != nil {
+			return nil, "", errors.New("multipart form must contain a file field")
+		}
+		defer f.Close()
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return nil, "", err
+		}
+		return data, importer.FormatByName(hdr.Filename), nil
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, importContentTypes[ctype], nil
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// Меню столовых и буфетов по дням:
+//
+//	GET    /places/{id}/menus          опубликованные меню начиная с сегодня (?from=2006-01-02)
+//	GET    /places/{id}/menus/{date}   меню на день
+//	PUT    /places/{id}/menus/{date}   опубликовать или заменить меню дня целиком
+//	DELETE /places/{id}/menus/{date}   снять меню
+func registerMenus(s *Server) {
+	s.handle("GET", "/places/{id}/menus", s.listMenus)
+	s.handle("GET", "/places/{id}/menus/{date}", s.getMenu)
+	s.handle("PUT", "/places/{id}/menus/{date}", s.putMenu)
+	s.handle("DELETE", "/places/{id}/menus/{date}", s.deleteMenu)
+}
+
+// menuBody - меню в запросах и ответах; в PUT читаются только dishes
+type menuBody struct {
+	PlaceID uint          `json:"place_id"`
+	Date    string        `json:"date"`
+	Dishes  []models.Dish `json:"dishes"`
+}
+
+func newMenuBody(m models.Menu) menuBody {
+	dishes := m.Dishes
+	if dishes == nil {
+		dishes = []models.Dish{}
+	}
+	return menuBody{PlaceID: m.PlaceID, Date: m.Date.Format(time.DateOnly), Dishes: dishes}
+}
+
+func (s *Server) listMenus(w http.ResponseWriter, r *http.Request) {
+	place, ok := s.menuPlace(w, r)
+	if !ok {
+		return
+	}
+	from := s.today()
+	if raw := r.URL.Query().Get("from"); raw != "" {
+		var err error
+		if from, err = time.Parse(time.DateOnly, raw); err != nil {
+			writeError(w, http.StatusBadRequest, "from must be a date YYYY-MM-DD")
+			return
+		}
+	}
+	var menus []models.Menu
+	err := s.db.WithContext(r.Context()).
+		Preload("Dishes", func(q *gorm.DB) *gorm.DB { return q.Order("position, id") }).
+		Where("place_id = ? AND date >= ?", place.ID, from.Format(time.DateOnly)).
+		Order("date").Limit(maxLimit).Find(&menus).Error
+	if err != nil {
+		writeInternal(w, r, err)
+		return
+	}
+	items := make([]menuBody, len(menus))
+	for i, m := range menus {
+		items[i] = newMenuBody(m)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+func (s *Server) getMenu(w http.ResponseWriter, r *http.Request) {
+	place, day, ok := s.menuTarget(w, r)
+	if !ok {
+		return
+	}
+	menu, found, err := models.FindMenu(s.db.WithContext(r.Context()), place.ID, day)
+	if err != nil {
+		writeInternal(w, r, err)
+		return
+	}
+	if !found {
+		writeError(w, http.StatusNotFound, "menu is not published")
+		return
+	}
+	writeJSON(w, http.StatusOK, newMenuBody(menu))
+}
+
+func (s *Server) putMenu(w http.ResponseWriter, r *http.Request) {
+	place, day, ok := s.menuTarget(w, r)
+	if !ok {
+		return
+	}
+	var body menuBody
+	if err := decodeBody(w, r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, "bad json: "+err.Error())
+		return
+	}
+
+	v := &validator{}
+	if len(body.Dishes) == 0 {
+		v.fail("dishes", "required (use DELETE to unpublish the menu)")
+	}
+	for i := range body.Dishes {
+		d := &body.Dishes[i]
+		v.required(fmt.Sprintf("dishes[%d].name", i), &d.Name)
+		if d.PriceKop < 0 {
+			v.fail(fmt.Sprintf("dishes[%d].price_kop", i), "must not be negative")
+		}
+		v.optional(&d.Category, &d.Weight, &d.Allergens)
+	}
+	if len(v.fields) > 0 {
+		writeJSON(w, http.StatusUnprocessableEntity, errorBody{Error: "validation failed", Fields: v.fields})
+		return
+	}
+
+	menu, err := models.ReplaceMenu(s.db.WithContext(r.Context()), place.ID, day, body.Dishes)
+	if err != nil {
+		writeInternal(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newMenuBody(menu))
+}
+
+func (s *Server) deleteMenu(w http.ResponseWriter, r *http.Request) {
+	place, day, ok := s.menuTarget(w, r)
+	if !ok {
+		return
+	}
+	db := s.db.WithContext(r.Context())
+	menu, found, err := models.FindMenu(db, place.ID, day)
+	if err != nil {
+		writeInternal(w, r, err)
+		return
+	}
+	if !found {
+		writeError(w, http.StatusNotFound, "menu is not published")
+		return
+	}
+	// блюда удаляются каскадом
+	if err := db.Delete(&models.Menu{}, menu.ID).Error; err != nil {
+		writeInternal(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// menuPlace - столовая или буфет по {id}; ответ об ошибке уже отправлен, если ok == false
+func (s *Server) menuPlace(w http.ResponseWriter, r *http.Request) (models.Place, bool) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return models.Place{}, false
+	}
+	var place models.Place
+	err := s.db.WithContext(r.Context()).First(&place, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		writeError(w, http.StatusNotFound, "not found")
+		return models.Place{}, false
+	}
+	if err != nil {
+		writeInternal(w, r, err)
+		return models.Place{}, false
+	}
+	if !place.HasMenu() {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("places of type %q have no menu", place.Type))
+		return models.Place{}, false
+	}
+	return place, true
+}
+
+// menuTarget - место и день из пути …/menus/{date}
+func (s *Server) menuTarget(w http.ResponseWriter, r *http.Request) (models.Place, time.Time, bool) {
+	day, err := time.Parse(time.DateOnly, r.PathValue("date"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "date must be YYYY-MM-DD")
+		return models.Place{}, time.Time{}, false
+	}
+	place, ok := s.menuPlace(w, r)
+	return place, day, ok
+}
+
+// today - текущая дата в часовом поясе университета
+func (s *Server) today() time.Time {
+	loc := s.loc
+	if loc == nil {
+		loc = time.Local
+	}
+	now := time.Now().In(loc)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,292 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Пагинация списков: ?limit=&offset=
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+// resource - CRUD над моделью T по пути Prefix/<path>:
+//
+//	GET    /<path>        список с фильтрами и пагинацией
+//	GET    /<path>/{id}   одна запись
+//	POST   /<path>        создать
+//	PUT    /<path>/{id}   заменить целиком
+//	PATCH  /<path>/{id}   изменить переданные поля
+//	DELETE /<path>/{id}   удалить
+type resource[T any] struct {
+	path     string
+	order    string            // сортировка списка
+	filters  map[string]filter // параметр запроса -> условие
+	validate func(v *validator, m *T)
+}
+
+// filter добавляет условие по значению параметра запроса
+type filter func(q *gorm.DB, val string) (*gorm.DB, error)
+
+// listResponse - страница списка
+type listResponse[T any] struct {
+	Items  []T   `json:"items"`
+	Total  int64 `json:"total"`
+	Limit  int   `json:"limit"`
+	Offset int   `json:"offset"`
+}
+
+func (res *resource[T]) register(s *Server) {
+	h := &resourceHandler[T]{resource: res, db: s.db}
+	s.handle("GET", "/"+res.path, h.list)
+	s.handle("POST", "/"+res.path, h.create)
+	s.handle("GET", "/"+res.path+"/{id}", h.get)
+	s.handle("PUT", "/"+res.path+"/{id}", h.replace)
+	s.handle("PATCH", "/"+res.path+"/{id}", h.patch)
+	s.handle("DELETE", "/"+res.path+"/{id}", h.delete)
+}
+
+type resourceHandler[T any] struct {
+	*resource[T]
+	db *gorm.DB
+}
+
+func (h *resourceHandler[T]) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, err := intParam(query.Get("limit"), defaultLimit)
+	if err != nil || limit < 1 || limit > maxLimit {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("limit must be 1..%d", maxLimit))
+		return
+	}
+	offset, err := intParam(query.Get("offset"), 0)
+	if err != nil || offset < 0 {
+		writeError(w, http.StatusBadRequest, "offset must be a non-negative integer")
+		return
+	}
+
+	q := h.db.WithContext(r.Context()).Model(new(T))
+	for key, vals := range query {
+		if key == "limit" || key == "offset" {
+			continue
+		}
+		f, ok := h.filters[key]
+		if !ok {
+			writeError(w, http.StatusBadRequest, "unknown filter: "+key+" (allowed: "+h.filterNames()+")")
+			return
+		}
+		if q, err = f(q, vals[0]); err != nil {
+			writeError(w, http.StatusBadRequest, key+": "+err.Error())
+			return
+		}
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		writeInternal(w, r, err)
+		return
+	}
+	items := []T{}
+	if err := q.Order(h.order).Offset(offset).Limit(limit).Find(&items).Error; err != nil {
+		writeInternal(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, listResponse[T]{Items: items, Total: total, Limit: limit, Offset: offset})
+}
+
+func (h *resourceHandler[T]) get(w http.ResponseWriter, r *http.Request) {
+	m, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, m)
+}
+
+func (h *resourceHandler[T]) create(w http.ResponseWriter, r *http.Request) {
+	var m T
+	if err := decodeBody(w, r, &m); err != nil {
+		writeError(w, http.StatusBadRequest, "bad json: "+err.Error())
+		return
+	}
+	setID(&m, 0) // id назначает база
+	if !h.check(w, r, &m) {
+		return
+	}
+	if err := h.db.WithContext(r.Context()).Omit(clause.Associations).Create(&m).Error; err != nil {
+		writeInternal(w, r, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("%s/%s/%d", Prefix, h.path, getID(&m)))
+	writeJSON(w, http.StatusCreated, m)
+}
+
+// replace - PUT: непереданные поля становятся пустыми
+func (h *resourceHandler[T]) replace(w http.ResponseWriter, r *http.Request) {
+	cur, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+	var m T
+	h.update(w, r, &m, getID(cur))
+}
+
+// patch - PATCH: поля из тела поверх текущей записи
+func (h *resourceHandler[T]) patch(w http.ResponseWriter, r *http.Request) {
+	m, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+	h.update(w, r, m, getID(m))
+}
+
+func (h *resourceHandler[T]) update(w http.ResponseWriter, r *http.Request, m *T, id uint) {
+	if err := decodeBody(w, r, m); err != nil {
+		writeError(w, http.StatusBadRequest, "bad json: "+err.Error())
+		return
+	}
+	setID(m, id) // id из пути главнее тела
+	if !h.check(w, r, m) {
+		return
+	}
+	if err := h.db.WithContext(r.Context()).Omit(clause.Associations).Save(m).Error; err != nil {
+		writeInternal(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, m)
+}
+
+func (h *resourceHandler[T]) delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	res := h.db.WithContext(r.Context()).Delete(new(T), id)
+	if res.Error != nil {
+		writeInternal(w, r, res.Error)
+		return
+	}
+	if res.RowsAffected == 0 {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// load - запись по {id}; ответ об ошибке уже отправлен, если ok == false
+func (h *resourceHandler[T]) load(w http.ResponseWriter, r *http.Request) (*T, bool) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return nil, false
+	}
+	m := new(T)
+	err := h.db.WithContext(r.Context()).First(m, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		writeError(w, http.StatusNotFound, "not found")
+		return nil, false
+	}
+	if err != nil {
+		writeInternal(w, r, err)
+		return nil, false
+	}
+	return m, true
+}
+
+// check прогоняет валидацию; при ошибках отвечает 422 с полями
+func (h *resourceHandler[T]) check(w http.ResponseWriter, r *http.Request, m *T) bool {
+	v := &validator{db: h.db.WithContext(r.Context()), selfID: getID(m)}
+	h.validate(v, m)
+	if v.err != nil {
+		writeInternal(w, r, v.err)
+		return false
+	}
+	if len(v.fields) > 0 {
+		writeJSON(w, http.StatusUnprocessableEntity, errorBody{Error: "validation failed", Fields: v.fields})
+		return false
+	}
+	return true
+}
+
+func (h *resourceHandler[T]) filterNames() string {
+	names := make([]string, 0, len(h.filters))
+	for k := range h.filters {
+		names = append(names, k)
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	slices.Sort(names)
+	return strings.Join(names, ", ")
+}
+
+// ---- фильтры ----
+
+// eqID - точное совпадение числовой колонки (обычно ссылки *_id)
+func eqID(col string) filter {
+	return func(q *gorm.DB, val string) (*gorm.DB, error) {
+		id, err := strconv.ParseUint(val, 10, 64)
+		if err != nil {
+			return nil, errors.New("must be a positive integer")
+		}
+		return q.Where(col+" = ?", id), nil
+	}
+}
+
+// eq - точное совпадение строковой колонки
+func eq(col string) filter {
+	return func(q *gorm.DB, val string) (*gorm.DB, error) {
+		return q.Where(col+" = ?", val), nil
+	}
+}
+
+// contains - подстрока без учёта регистра хотя бы в одной из колонок
+func contains(cols ...string) filter {
+	return func(q *gorm.DB, val string) (*gorm.DB, error) {
+		val = strings.TrimSpace(val)
+		if val == "" {
+			return q, nil
+		}
+		like := "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(strings.ToLower(val)) + "%"
+		conds := make([]string, len(cols))
+		args := make([]any, len(cols))
+		for i, c := range cols {
+			conds[i] = "lower(" + c + ") LIKE ? ESCAPE '\\'"
+			args[i] = like
+		}
+		return q.Where(strings.Join(conds, " OR "), args...), nil
+	}
+}
+
+// ---- утилиты ----
+
+func pathID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil || id == 0 {
+		writeError(w, http.StatusBadRequest, "id must be a positive integer")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func intParam(raw string, def int) (int, error) {
+	if raw == "" {
+		return def, nil
+	}
+	return strconv.Atoi(raw)
+}
+
+// getID/setID - поле ID модели (у всех моделей API оно uint)
+func getID[T any](m *T) uint {
+	return uint(reflect.ValueOf(m).Elem().FieldByName("ID").Uint())
+}
+
+func setID[T any](m *T, id uint) {
+	reflect.ValueOf(m).Elem().FieldByName("ID").SetUint(uint64(id))
+}
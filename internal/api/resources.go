@@ -0,0 +1,271 @@
+package api
+
+import (
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/Karielka/Hackaton_MAX/internal/media"
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// Типы мест (models.Place.Type), которые понимает бот
+var placeTypes = []string{"canteen", "buffet", "copy"}
+
+func registerResources(s *Server) {
+	(&resource[models.Institute]{
+		path:    "institutes",
+		order:   "name",
+		filters: map[string]filter{"q": contains("name")},
+		validate: func(v *validator, m *models.Institute) {
+			v.required("name", &m.Name)
+			v.unique("name", &models.Institute{}, "name", m.Name)
+		},
+	}).register(s)
+
+	(&resource[models.Faculty]{
+		path:  "faculties",
+		order: "name",
+		filters: map[string]filter{
+			"q":            contains("name"),
+			"institute_id": eqID("institute_id"),
+		},
+		validate: func(v *validator, m *models.Faculty) {
+			v.required("name", &m.Name)
+			v.unique("name", &models.Faculty{}, "name", m.Name)
+			v.ref("institute_id", &models.Institute{}, m.InstituteID, true)
+		},
+	}).register(s)
+
+	(&resource[models.Department]{
+		path:  "departments",
+		order: "name",
+		filters: map[string]filter{
+			"q":          contains("name"),
+			"faculty_id": eqID("faculty_id"),
+		},
+		validate: func(v *validator, m *models.Department) {
+			v.required("name", &m.Name)
+			v.unique("name", &models.Department{}, "name", m.Name)
+			v.ref("faculty_id", &models.Faculty{}, m.FacultyID, true)
+			v.optional(&m.Office)
+		},
+	}).register(s)
+
+	(&resource[models.Teacher]{
+		path:  "teachers",
+		order: "full_name",
+		filters: map[string]filter{
+			"q":             contains("full_name", "subject"),
+			"department_id": eqID("department_id"),
+			"email":         eq("email"),
+		},
+		validate: func(v *validator, m *models.Teacher) {
+			v.required("full_name", &m.FullName)
+			v.ref("department_id", &models.Department{}, m.DepartmentID, true)
+			v.email("email", &m.Email)
+			v.optional(&m.Subject, &m.OfficeRoom, &m.Phone, &m.OfficeHours)
+		},
+	}).register(s)
+
+	(&resource[models.DeanOffice]{
+		path:    "dean-offices",
+		order:   "id",
+		filters: map[string]filter{"faculty_id": eqID("faculty_id")},
+		validate: func(v *validator, m *models.DeanOffice) {
+			if v.ref("faculty_id", &models.Faculty{}, m.FacultyID, true) {
+				v.unique("faculty_id", &models.DeanOffice{}, "faculty_id", m.FacultyID)
+			}
+			v.url("docs_link", &m.DocsLink)
+			v.optional(&m.Schedule, &m.Contacts)
+		},
+	}).register(s)
+
+	(&resource[models.Campus]{
+		path:  "campuses",
+		order: "short_name",
+		filters: map[string]filter{
+			"q":            contains("short_name", "full_name", "address"),
+			"institute_id": eqID("institute_id"),
+		},
+		validate: func(v *validator, m *models.Campus) {
+			v.required("short_name", &m.ShortName)
+			v.required("full_name", &m.FullName)
+			v.image("image_url", &m.ImageURL)
+			v.image("map_image_url", &m.MapImageURL)
+			v.ref("institute_id", &models.Institute{}, m.InstituteID, false)
+			v.optional(&m.Address, &m.Metro, &m.Description)
+			v.coords(m.Latitude, m.Longitude)
+		},
+	}).register(s)
+
+	(&resource[models.Place]{
+		path:  "places",
+		order: "campus_id, type, name",
+		filters: map[string]filter{
+			"q":         contains("name", "location"),
+			"campus_id": eqID("campus_id"),
+			"type":      eq("type"),
+		},
+		validate: func(v *validator, m *models.Place) {
+			v.required("name", &m.Name)
+			v.ref("campus_id", &models.Campus{}, m.CampusID, true)
+			v.oneOf("type", &m.Type, placeTypes)
+			v.url("menu_url", &m.MenuURL)
+			v.optional(&m.Location, &m.Schedule, &m.MenuToday)
+		},
+	}).register(s)
+
+	(&resource[models.FAQCategory]{
+		path:    "faq-categories",
+		order:   "position, name",
+		filters: map[string]filter{"q": contains("name")},
+		validate: func(v *validator, m *models.FAQCategory) {
+			v.required("name", &m.Name)
+			v.unique("name", &models.FAQCategory{}, "name", m.Name)
+		},
+	}).register(s)
+
+	(&resource[models.FAQ]{
+		path:  "faqs",
+		order: "id",
+		filters: map[string]filter{
+			"q":           contains("question", "answer"),
+			"category_id": eqID("category_id"),
+		},
+		validate: func(v *validator, m *models.FAQ) {
+			v.required("question", &m.Question)
+			v.required("answer", &m.Answer)
+			if m.CategoryID != nil {
+				v.ref("category_id", &models.FAQCategory{}, *m.CategoryID, true)
+			}
+		},
+	}).register(s)
+}
+
+// validator копит ошибки по полям; строки по пути нормализует (обрезает пробелы).
+// err — сбой базы во время проверки, это не ошибка клиента.
+type validator struct {
+	db     *gorm.DB
+	selfID uint // id проверяемой записи (0 при создании) — для проверок уникальности
+	fields map[string]string
+	err    error
+}
+
+func (v *validator) fail(field, msg string) {
+	if v.fields == nil {
+		v.fields = map[string]string{}
+	}
+	if _, ok := v.fields[field]; !ok {
+		v.fields[field] = msg
+	}
+}
+
+func (v *validator) optional(vals ...*string) {
+	for _, s := range vals {
+		*s = strings.TrimSpace(*s)
+	}
+}
+
+func (v *validator) required(field string, s *string) bool {
+	*s = strings.TrimSpace(*s)
+	if *s == "" {
+		v.fail(field, "required")
+		return false
+	}
+	return true
+}
+
+// url - пусто или абсолютная ссылка http(s)
+func (v *validator) url(field string, s *string) {
+	*s = strings.TrimSpace(*s)
+	if *s == "" {
+		return
+	}
+	u, err := url.Parse(*s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.fail(field, "must be an http(s) URL")
+	}
+}
+
+// image - пусто, ссылка http(s) или путь к файлу в MEDIA_DIR (internal/media)
+func (v *validator) image(field string, s *string) {
+	*s = strings.TrimSpace(*s)
+	if *s == "" {
+		return
+	}
+	if err := media.Validate(*s); err != nil {
+		v.fail(field, err.Error())
+	}
+}
+
+// email - пусто или один адрес без имени
+func (v *validator) email(field string, s *string) {
+	*s = strings.TrimSpace(*s)
+	if *s == "" {
+		return
+	}
+	if a, err := mail.ParseAddress(*s); err != nil || a.Address != *s {
+		v.fail(field, "must be an email address")
+	}
+}
+
+func (v *validator) oneOf(field string, s *string, options []string) {
+	*s = strings.TrimSpace(*s)
+	if !slices.Contains(options, *s) {
+		v.fail(field, "must be one of: "+strings.Join(options, ", "))
+	}
+}
+
+// coords - широта и долгота задаются вместе и в допустимых пределах
+func (v *validator) coords(lat, lon *float64) {
+	if (lat == nil) != (lon == nil) {
+		v.fail("latitude", "latitude and longitude must be set together")
+		return
+	}
+	if lat != nil && (*lat < -90 || *lat > 90) {
+		v.fail("latitude", "must be -90..90")
+	}
+	if lon != nil && (*lon < -180 || *lon > 180) {
+		v.fail("longitude", "must be -180..180")
+	}
+}
+
+// ref - ссылка на существующую запись model; 0 допустим, только если !required
+func (v *validator) ref(field string, model any, id uint, required bool) bool {
+	if id == 0 {
+		if required {
+			v.fail(field, "required")
+			return false
+		}
+		return true
+	}
+	var n int64
+	if err := v.db.Model(model).Where("id = ?", id).Count(&n).Error; err != nil {
+		v.err = err
+		return false
+	}
+	if n == 0 {
+		v.fail(field, "references a missing record")
+		return false
+	}
+	return true
+}
+
+// unique - значение col не занято другой записью
+func (v *validator) unique(field string, model any, col string, val any) {
+	if s, ok := val.(string); ok && s == "" {
+		return // об обязательности скажет required
+	}
+	var n int64
+	if err := v.db.Model(model).Where(col+" = ? AND id <> ?", val, v.selfID).Count(&n).Error; err != nil {
+		v.err = err
+		return
+	}
+	if n > 0 {
+		v.fail(field, "already exists")
+	}
+}
@@ -0,0 +1,141 @@
+// Package api - встроенный HTTP-сервер: проверка здоровья, REST API для сотрудников,
+// которые ведут справочники университета без доступа к SQL, и подписка на расписание (.ics).
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// Prefix - общий префикс REST API
+const Prefix = "/api/v1"
+
+// максимальный размер тела запроса
+const maxBodyBytes = 1 << 20
+
+type Server struct {
+	db    *gorm.DB
+	token string         // ADMIN_API_TOKEN; пустой — API выключен, работает только /healthz
+	loc   *time.Location // часовой пояс университета (импорт и выгрузка расписаний)
+	mux   *http.ServeMux
+}
+
+// New собирает сервер; ресурсы регистрируются сразу
+func New(db *gorm.DB, token string, loc *time.Location) *Server {
+	s := &Server{db: db, token: token, loc: loc, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /healthz", s.health)
+	registerResources(s)
+	registerMenus(s)
+	registerHours(s)
+	registerImports(s)
+	registerCalendars(s)
+	return s
+}
+
+// Handler - корневой обработчик (удобно для httptest)
+func (s *Server) Handler() http.Handler { return s.mux }
+
+// handle регистрирует маршрут API под Prefix с проверкой токена
+func (s *Server) handle(method, path string, h http.HandlerFunc) {
+	s.mux.Handle(method+" "+Prefix+path, s.auth(h))
+}
+
+// Run слушает addr до отмены ctx, затем даёт запросам 5 секунд на завершение
+func (s *Server) Run(ctx context.Context, addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.ListenAndServe() }()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			return err
+		}
+		if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
+
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	sqlDB, err := s.db.DB()
+	if err == nil {
+		err = sqlDB.PingContext(r.Context())
+	}
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, "database is unavailable")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// auth - Authorization: Bearer <ADMIN_API_TOKEN>
+func (s *Server) auth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.token == "" {
+			writeError(w, http.StatusServiceUnavailable, "admin api is disabled: ADMIN_API_TOKEN is not set")
+			return
+		}
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+			writeError(w, http.StatusUnauthorized, "invalid or missing token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// errorBody - тело ответа с ошибкой; Fields — ошибки валидации по полям
+type errorBody struct {
+	Error  string            `json:"error"`
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Err(err).Msg("api: encode response")
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, errorBody{Error: msg})
+}
+
+// writeInternal логирует причину, наружу отдаёт общее сообщение
+func writeInternal(w http.ResponseWriter, r *http.Request, err error) {
+	log.Err(err).Str("method", r.Method).Str("path", r.URL.Path).Msg("api")
+	writeError(w, http.StatusInternalServerError, "internal error")
+}
+
+// decodeBody читает JSON в dst; неизвестные поля — ошибка (опечатки не теряются молча)
+func decodeBody(w http.ResponseWriter, r *http.Request, dst any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("body must contain a single JSON object")
+	}
+	return nil
+}
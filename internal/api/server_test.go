@@ -0,0 +1,268 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/Karielka/Hackaton_MAX/internal/fixtures"
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+const testToken = "secret"
+
+func openDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(t.TempDir()+"/api.db?_pragma=foreign_keys(1)"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := models.AutoMigrate(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := fixtures.LoadSample(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// call выполняет запрос к обработчику; token "" — без заголовка Authorization
+func call(t *testing.T, h http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, r)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+		t.Fatalf("decode %q: %v", rec.Body.String(), err)
+	}
+	return v
+}
+
+func TestAuth(t *testing.T) {
+	db := openDB(t)
+	h := New(db, testToken, time.UTC).Handler()
+
+	for _, tc := range []struct {
+		name, token string
+		want        int
+	}{
+		{"missing", "", http.StatusUnauthorized},
+		{"wrong", "other", http.StatusUnauthorized},
+		{"prefix", testToken + "x", http.StatusUnauthorized},
+		{"ok", testToken, http.StatusOK},
+	} {
+		rec := call(t, h, "GET", Prefix+"/faculties", tc.token, "")
+		if rec.Code != tc.want {
+			t.Errorf("%s: status %d, want %d", tc.name, rec.Code, tc.want)
+		}
+		if tc.want == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%s: no WWW-Authenticate header", tc.name)
+		}
+	}
+
+	// пустой токен выключает API, но не /healthz
+	off := New(db, "", time.UTC).Handler()
+	if rec := call(t, off, "GET", Prefix+"/faculties", "anything", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("disabled api: status %d, want 503", rec.Code)
+	}
+	if rec := call(t, off, "GET", "/healthz", "", ""); rec.Code != http.StatusOK {
+		t.Errorf("healthz: status %d, want 200", rec.Code)
+	}
+}
+
+func TestValidation(t *testing.T) {
+	h := New(openDB(t), testToken, time.UTC).Handler()
+
+	for _, tc := range []struct {
+		name, method, path, body string
+		fields                   map[string]string
+	}{
+		{"required and ref", "POST", "/teachers", `{"full_name":"  ","department_id":999}`,
+			map[string]string{"full_name": "required", "department_id": "references a missing record"}},
+		{"email", "POST", "/teachers", `{"full_name":"Новиков Н. Н.","department_id":1,"email":"Новиков <n@example.com>"}`,
+			map[string]string{"email": "must be an email address"}},
+		{"unique", "POST", "/faculties", `{"name":"ИУ","institute_id":1}`,
+			map[string]string{"name": "already exists"}},
+		{"one of and url", "POST", "/places", `{"name":"Кафе","campus_id":1,"type":"cafe","menu_url":"ftp://x"}`,
+			map[string]string{"type": "must be one of: canteen, buffet, copy", "menu_url": "must be an http(s) URL"}},
+		{"coords", "PATCH", "/campuses/1", `{"latitude":55.7,"longitude":null}`,
+			map[string]string{"latitude": "latitude and longitude must be set together"}},
+	} {
+		rec := call(t, h, tc.method, Prefix+tc.path, testToken, tc.body)
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: status %d, want 422: %s", tc.name, rec.Code, rec.Body)
+			continue
+		}
+		got := decode[errorBody](t, rec)
+		for field, msg := range tc.fields {
+			if got.Fields[field] != msg {
+				t.Errorf("%s: %s = %q, want %q", tc.name, field, got.Fields[field], msg)
+			}
+		}
+	}
+
+	for _, tc := range []struct{ name, body string }{
+		{"unknown field", `{"name":"Новый","institute_id":1,"nmae":"x"}`},
+		{"two objects", `{"name":"Новый","institute_id":1}{}`},
+		{"not json", `name=Новый`},
+	} {
+		if rec := call(t, h, "POST", Prefix+"/faculties", testToken, tc.body); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", tc.name, rec.Code)
+		}
+	}
+
+	// строки обрезаются до сохранения, id из тела игнорируется
+	rec := call(t, h, "POST", Prefix+"/faculties", testToken, `{"id":1,"name":"  СМ  ","institute_id":1}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", rec.Code, rec.Body)
+	}
+	fac := decode[models.Faculty](t, rec)
+	if fac.Name != "СМ" || fac.ID == 1 {
+		t.Errorf("created %+v", fac)
+	}
+	if loc := rec.Header().Get("Location"); loc != fmt.Sprintf("%s/faculties/%d", Prefix, fac.ID) {
+		t.Errorf("Location %q", loc)
+	}
+}
+
+func TestListParams(t *testing.T) {
+	db := openDB(t)
+	h := New(db, testToken, time.UTC).Handler()
+
+	var total int64
+	if err := db.Model(&models.Teacher{}).Count(&total).Error; err != nil {
+		t.Fatal(err)
+	}
+	if total < 3 {
+		t.Fatalf("fixtures: %d teachers, need at least 3", total)
+	}
+
+	all := decode[listResponse[models.Teacher]](t, call(t, h, "GET", Prefix+"/teachers?limit=100", testToken, ""))
+	page := decode[listResponse[models.Teacher]](t, call(t, h, "GET", Prefix+"/teachers?limit=2&offset=1", testToken, ""))
+	if page.Total != total || page.Limit != 2 || page.Offset != 1 || len(page.Items) != 2 {
+		t.Fatalf("page: total %d limit %d offset %d items %d", page.Total, page.Limit, page.Offset, len(page.Items))
+	}
+	if page.Items[0].ID != all.Items[1].ID || page.Items[1].ID != all.Items[2].ID {
+		t.Errorf("offset does not follow the list order")
+	}
+	if def := decode[listResponse[models.Teacher]](t, call(t, h, "GET", Prefix+"/teachers", testToken, "")); def.Limit != defaultLimit {
+		t.Errorf("default limit %d, want %d", def.Limit, defaultLimit)
+	}
+
+	// фильтры: по ссылке и по подстроке без учёта регистра
+	byDep := decode[listResponse[models.Teacher]](t, call(t, h, "GET", Prefix+"/teachers?department_id=1&limit=100", testToken, ""))
+	if byDep.Total == 0 || byDep.Total == total {
+		t.Errorf("department_id filter: %d of %d", byDep.Total, total)
+	}
+	for _, tt := range byDep.Items {
+		if tt.DepartmentID != 1 {
+			t.Errorf("department_id filter returned teacher %d of department %d", tt.ID, tt.DepartmentID)
+		}
+	}
+	// латиница: lower() в SQLite не знает кириллицы
+	if err := db.Create(&models.Faculty{Name: "Robotics 100%", InstituteID: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+	byName := decode[listResponse[models.Faculty]](t, call(t, h, "GET", Prefix+"/faculties?q=BOTICS+100%25", testToken, ""))
+	if byName.Total != 1 || byName.Items[0].Name != "Robotics 100%" {
+		t.Errorf("q filter: %+v", byName.Items)
+	}
+
+	for _, q := range []string{"limit=0", "limit=101", "limit=x", "offset=-1", "department_id=abc", "salary=1"} {
+		if rec := call(t, h, "GET", Prefix+"/teachers?"+q, testToken, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", q, rec.Code)
+		}
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	h := New(openDB(t), testToken, time.UTC).Handler()
+
+	for _, tc := range []struct {
+		method, path, body string
+		want               int
+	}{
+		{"GET", "/teachers/999999", "", http.StatusNotFound},
+		{"PUT", "/teachers/999999", `{"full_name":"Х","department_id":1}`, http.StatusNotFound},
+		{"PATCH", "/teachers/999999", `{}`, http.StatusNotFound},
+		{"DELETE", "/teachers/999999", "", http.StatusNotFound},
+		{"GET", "/teachers/abc", "", http.StatusBadRequest},
+		{"GET", "/teachers/0", "", http.StatusBadRequest},
+		{"GET", "/places/999999/menus", "", http.StatusNotFound},
+		{"GET", "/nothing", "", http.StatusNotFound},
+	} {
+		rec := call(t, h, tc.method, Prefix+tc.path, testToken, tc.body)
+		if rec.Code != tc.want {
+			t.Errorf("%s %s: status %d, want %d", tc.method, tc.path, rec.Code, tc.want)
+		}
+	}
+
+	// удаление существующей записи и повторное — уже 404
+	rec := call(t, h, "POST", Prefix+"/faq-categories", testToken, `{"name":"Временная"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", rec.Code, rec.Body)
+	}
+	path := fmt.Sprintf("%s/faq-categories/%d", Prefix, decode[models.FAQCategory](t, rec).ID)
+	if rec := call(t, h, "DELETE", path, testToken, ""); rec.Code != http.StatusNoContent {
+		t.Errorf("delete: status %d", rec.Code)
+	}
+	if rec := call(t, h, "DELETE", path, testToken, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("second delete: status %d, want 404", rec.Code)
+	}
+}
+
+func TestCalendarRoute(t *testing.T) {
+	db := openDB(t)
+	var lesson models.Lesson
+	if err := db.First(&lesson).Error; err != nil {
+		t.Fatal(err)
+	}
+	// токен задан, но календарь открыт без него
+	h := New(db, testToken, time.FixedZone("MSK", 3*60*60)).Handler()
+
+	rec := call(t, h, "GET", fmt.Sprintf("/calendar/teacher/%d.ics", lesson.TeacherID), "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("teacher calendar: status %d: %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") {
+		t.Errorf("Content-Type %q", ct)
+	}
+	body := rec.Body.String()
+	if !strings.HasPrefix(body, "BEGIN:VCALENDAR\r\n") || !strings.Contains(body, "BEGIN:VEVENT") {
+		t.Errorf("teacher calendar body: %.200q", body)
+	}
+
+	for _, path := range []string{
+		"/calendar/teacher/999999.ics",
+		"/calendar/group/999999.ics",
+		fmt.Sprintf("/calendar/teacher/%d", lesson.TeacherID),
+		"/calendar/teacher/abc.ics",
+		"/calendar/teacher/0.ics",
+	} {
+		if rec := call(t, h, "GET", path, "", ""); rec.Code != http.StatusNotFound {
+			t.Errorf("%s: status %d, want 404", path, rec.Code)
+		}
+	}
+}
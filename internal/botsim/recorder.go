@@ -0,0 +1,194 @@
+// Package botsim - офлайн-симулятор бота: подменяет MAX API записью отправленных
+// сообщений и прогоняет синтетические апдейты через services.
+//
+// Сценарии в simulator_test.go работают на SQLite (github.com/glebarez/sqlite, без cgo):
+// схема из models.AutoMigrate, данные из fixtures.LoadSample. На SQLite нет того, что
+// требует Postgres: ответов из FAQ полнотекстовым поиском (FAQ_OnMessage пропускает ход)
+// и pg_trgm — поиск по ФИО идёт запасным сравнением префиксов в Go. Это проверяется
+// только на Postgres, например подключив симулятор к базе после migrate up.
+package botsim
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"unsafe"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+)
+
+// Button - кнопка клавиатуры в отправленном сообщении
+type Button struct {
+	Text    string
+	Payload string // для callback-кнопок
+	URL     string // для кнопок-ссылок
+}
+
+// Sent - сообщение, которое бот отправил бы в MAX
+type Sent struct {
+	ChatID      int64
+	UserID      int64
+	Text        string
+	Format      string
+	Keyboard    [][]Button
+	Attachments []any // вложения, кроме клавиатуры (фото, файлы)
+}
+
+// Buttons - все кнопки сообщения одним списком
+func (s Sent) Buttons() []Button {
+	var out []Button
+	for _, row := range s.Keyboard {
+		out = append(out, row...)
+	}
+	return out
+}
+
+// Button ищет кнопку по видимому тексту
+func (s Sent) Button(text string) (Button, bool) {
+	for _, b := range s.Buttons() {
+		if b.Text == text {
+			return b, true
+		}
+	}
+	return Button{}, false
+}
+
+// Upload - файл, который бот загрузил бы в MAX
+type Upload struct {
+	Name  string
+	Data  []byte
+	Token string // токен, который получит вложение в сообщении
+}
+
+// Recorder реализует services.Messenger и запоминает всё отправленное
+type Recorder struct {
+	mu      sync.Mutex
+	sent    []Sent
+	uploads []Upload
+	seq     int
+}
+
+func NewRecorder() *Recorder { return &Recorder{} }
+
+func (r *Recorder) Send(_ context.Context, m *maxbot.Message) (string, error) {
+	s, err := decode(m)
+	if err != nil {
+		return "", err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, s)
+	r.seq++
+	return "mid." + strconv.Itoa(r.seq), nil
+}
+
+func (r *Recorder) NewKeyboardBuilder() *maxbot.Keyboard { return &maxbot.Keyboard{} }
+
+func (r *Recorder) UploadFile(_ context.Context, name string, data []byte) (*schemes.UploadedInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	u := Upload{Name: name, Data: append([]byte(nil), data...), Token: "file." + strconv.Itoa(r.seq)}
+	r.uploads = append(r.uploads, u)
+	return &schemes.UploadedInfo{Token: u.Token}, nil
+}
+
+func (r *Recorder) UploadPhoto(_ context.Context, name string, data []byte) (*schemes.PhotoTokens, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	u := Upload{Name: name, Data: append([]byte(nil), data...), Token: "photo." + strconv.Itoa(r.seq)}
+	r.uploads = append(r.uploads, u)
+	return &schemes.PhotoTokens{Photos: map[string]schemes.PhotoToken{strconv.Itoa(r.seq): {Token: u.Token}}}, nil
+}
+
+// Uploads возвращает копию всех загруженных файлов
+func (r *Recorder) Uploads() []Upload {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Upload(nil), r.uploads...)
+}
+
+// Sent возвращает копию всех отправленных сообщений
+func (r *Recorder) Sent() []Sent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Sent(nil), r.sent...)
+}
+
+// Len - сколько сообщений отправлено
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.sent)
+}
+
+// Reset забывает отправленные сообщения и загруженные файлы
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	r.sent = nil
+	r.uploads = nil
+	r.mu.Unlock()
+}
+
+// decode достаёт адресата и тело из *maxbot.Message.
+// У SDK нет геттеров, поэтому читаем неэкспортируемые поля напрямую.
+func decode(m *maxbot.Message) (Sent, error) {
+	v := reflect.ValueOf(m).Elem()
+	field := func(name string) (reflect.Value, error) {
+		f := v.FieldByName(name)
+		if !f.IsValid() {
+			return reflect.Value{}, fmt.Errorf("botsim: maxbot.Message has no field %q (SDK changed?)", name)
+		}
+		return reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem(), nil
+	}
+
+	userID, err := field("userID")
+	if err != nil {
+		return Sent{}, err
+	}
+	chatID, err := field("chatID")
+	if err != nil {
+		return Sent{}, err
+	}
+	bodyField, err := field("message")
+	if err != nil {
+		return Sent{}, err
+	}
+	body, ok := bodyField.Interface().(*schemes.NewMessageBody)
+	if !ok || body == nil {
+		return Sent{}, fmt.Errorf("botsim: unexpected message body %T", bodyField.Interface())
+	}
+
+	s := Sent{
+		ChatID: chatID.Int(),
+		UserID: userID.Int(),
+		Text:   body.Text,
+		Format: body.Format,
+	}
+	for _, a := range body.Attachments {
+		kb, ok := a.(*schemes.InlineKeyboardAttachmentRequest)
+		if !ok {
+			s.Attachments = append(s.Attachments, a)
+			continue
+		}
+		for _, row := range kb.Payload.Buttons {
+			var out []Button
+			for _, b := range row {
+				btn := Button{Text: b.GetText()}
+				switch b := b.(type) {
+				case schemes.CallbackButton:
+					btn.Payload = b.Payload
+				case schemes.LinkButton:
+					btn.URL = b.Url
+				}
+				out = append(out, btn)
+			}
+			s.Keyboard = append(s.Keyboard, out)
+		}
+	}
+	return s, nil
+}
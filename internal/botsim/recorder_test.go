@@ -0,0 +1,75 @@
+package botsim
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+)
+
+// decode читает неэкспортируемые поля maxbot.Message — если SDK их переименует или сменит тип,
+// упадёт этот тест, а не сценарии в CI.
+func TestMessageLayout(t *testing.T) {
+	typ := reflect.TypeOf(maxbot.Message{})
+	want := map[string]reflect.Type{
+		"userID":  reflect.TypeOf(int64(0)),
+		"chatID":  reflect.TypeOf(int64(0)),
+		"message": reflect.TypeOf(&schemes.NewMessageBody{}),
+	}
+	for name, ft := range want {
+		f, ok := typ.FieldByName(name)
+		if !ok {
+			t.Errorf("maxbot.Message has no field %q", name)
+			continue
+		}
+		if f.Type != ft {
+			t.Errorf("maxbot.Message.%s is %s, want %s", name, f.Type, ft)
+		}
+	}
+}
+
+func TestRecorderSend(t *testing.T) {
+	rec := NewRecorder()
+	kb := rec.NewKeyboardBuilder()
+	kb.AddRow().
+		AddCallback("Да", schemes.POSITIVE, "yes").
+		AddLink("Сайт", schemes.DEFAULT, "https://example.edu")
+	kb.AddRow().AddCallback("Нет", schemes.NEGATIVE, "no")
+	photo := &schemes.PhotoTokens{Photos: map[string]schemes.PhotoToken{"1": {Token: "photo.1"}}}
+
+	m := maxbot.NewMessage().SetUser(42).SetText("Вопрос?").SetFormat("markdown").
+		AddPhoto(photo).AddKeyboard(kb)
+	if _, err := rec.Send(context.Background(), m); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rec.Send(context.Background(), maxbot.NewMessage().SetChat(7).SetText("в чат")); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := rec.Sent()
+	if len(sent) != 2 {
+		t.Fatalf("sent %d messages, want 2", len(sent))
+	}
+	got := sent[0]
+	if got.UserID != 42 || got.ChatID != 0 || got.Text != "Вопрос?" || got.Format != "markdown" {
+		t.Errorf("first message = %+v", got)
+	}
+	wantKB := [][]Button{
+		{{Text: "Да", Payload: "yes"}, {Text: "Сайт", URL: "https://example.edu"}},
+		{{Text: "Нет", Payload: "no"}},
+	}
+	if !reflect.DeepEqual(got.Keyboard, wantKB) {
+		t.Errorf("keyboard = %+v, want %+v", got.Keyboard, wantKB)
+	}
+	if len(got.Attachments) != 1 {
+		t.Errorf("attachments = %+v, want the photo only", got.Attachments)
+	}
+	if b, ok := got.Button("Нет"); !ok || b.Payload != "no" {
+		t.Errorf("Button(%q) = %+v, %v", "Нет", b, ok)
+	}
+	if sent[1].ChatID != 7 || sent[1].UserID != 0 {
+		t.Errorf("second message = %+v, want chat 7", sent[1])
+	}
+}
@@ -0,0 +1,170 @@
+package botsim
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+	"gorm.io/gorm"
+
+	"github.com/Karielka/Hackaton_MAX/services"
+)
+
+// Simulator прогоняет синтетические апдейты через services так же, как main.go,
+// только вместо MAX API сообщения попадают в Recorder.
+//
+// БД — любая *gorm.DB, схему и данные готовит вызывающий: для SQLite models.AutoMigrate +
+// fixtures.LoadSample, для Postgres — migrate up (см. описание пакета).
+type Simulator struct {
+	Recorder *Recorder
+	Ctx      services.Ctx
+
+	router *services.Router
+	clock  int64
+}
+
+func New(db *gorm.DB) (*Simulator, error) {
+	router, err := services.NewRouter()
+	if err != nil {
+		return nil, err
+	}
+	rec := NewRecorder()
+	return &Simulator{
+		Recorder: rec,
+		Ctx: services.Ctx{
+			API:     rec,
+			DB:      db,
+			Dialogs: services.NewDialogs(services.NewMemoryDialogStore(), services.DefaultDialogTTL),
+		},
+		router: router,
+		clock:  time.Now().Unix(),
+	}, nil
+}
+
+// Say - пользователь пишет боту текст (в том числе команды /start, /menu)
+func (s *Simulator) Say(ctx context.Context, userID int64, text string) ([]Sent, error) {
+	upd := &schemes.MessageCreatedUpdate{
+		Update: schemes.Update{UpdateType: schemes.TypeMessageCreated, Timestamp: int(s.tick())},
+		Message: schemes.Message{
+			Sender:    schemes.User{UserId: userID, Name: fmt.Sprintf("user%d", userID)},
+			Recipient: schemes.Recipient{ChatType: schemes.DIALOG},
+			Timestamp: s.clock,
+			Body:      schemes.MessageBody{Mid: fmt.Sprintf("in.%d", s.clock), Text: text},
+		},
+	}
+	return s.capture(func() error { return services.HandleMessage(ctx, s.Ctx, upd) })
+}
+
+// Press - пользователь нажимает callback-кнопку с payload
+func (s *Simulator) Press(ctx context.Context, userID int64, payload string) ([]Sent, error) {
+	upd := &schemes.MessageCallbackUpdate{
+		Update: schemes.Update{UpdateType: schemes.TypeMessageCallback, Timestamp: int(s.tick())},
+		Callback: schemes.Callback{
+			Timestamp:  s.clock,
+			CallbackID: fmt.Sprintf("cb.%d", s.clock),
+			Payload:    payload,
+			User:       schemes.User{UserId: userID, Name: fmt.Sprintf("user%d", userID)},
+		},
+		Message: &schemes.Message{
+			Recipient: schemes.Recipient{UserId: userID, ChatType: schemes.DIALOG},
+		},
+	}
+	return s.capture(func() error { return s.router.Route(ctx, s.Ctx, upd) })
+}
+
+// Tap нажимает кнопку с текстом label из последнего сообщения пользователю
+func (s *Simulator) Tap(ctx context.Context, userID int64, label string) ([]Sent, error) {
+	last, ok := s.Last(userID)
+	if !ok {
+		return nil, fmt.Errorf("botsim: no messages to user %d yet", userID)
+	}
+	btn, ok := last.Button(label)
+	if !ok {
+		return nil, fmt.Errorf("botsim: no button %q in last message %q", label, last.Text)
+	}
+	if btn.Payload == "" {
+		return nil, fmt.Errorf("botsim: button %q is not a callback button", label)
+	}
+	return s.Press(ctx, userID, btn.Payload)
+}
+
+// Last - последнее сообщение, отправленное пользователю
+func (s *Simulator) Last(userID int64) (Sent, bool) {
+	sent := s.Recorder.Sent()
+	for i := len(sent) - 1; i >= 0; i-- {
+		if sent[i].UserID == userID {
+			return sent[i], true
+		}
+	}
+	return Sent{}, false
+}
+
+// Step - шаг сценария: одно действие пользователя и ожидаемый фрагмент ответа
+type Step struct {
+	Say    string // текст сообщения
+	Press  string // payload кнопки
+	Tap    string // текст кнопки из последнего сообщения
+	Expect string // подстрока, которая должна быть в одном из ответов (пусто — не проверяем)
+}
+
+// Run выполняет сценарий шаг за шагом и останавливается на первом расхождении
+func (s *Simulator) Run(ctx context.Context, userID int64, steps ...Step) error {
+	for i, st := range steps {
+		var (
+			replies []Sent
+			err     error
+		)
+		switch {
+		case st.Tap != "":
+			replies, err = s.Tap(ctx, userID, st.Tap)
+		case st.Press != "":
+			replies, err = s.Press(ctx, userID, st.Press)
+		default:
+			replies, err = s.Say(ctx, userID, st.Say)
+		}
+		if err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+		if st.Expect == "" {
+			continue
+		}
+		if !containsText(replies, st.Expect) {
+			return fmt.Errorf("step %d: no reply contains %q, got %s", i+1, st.Expect, describe(replies))
+		}
+	}
+	return nil
+}
+
+// capture возвращает сообщения, отправленные во время fn
+func (s *Simulator) capture(fn func() error) ([]Sent, error) {
+	before := s.Recorder.Len()
+	err := fn()
+	return s.Recorder.Sent()[before:], err
+}
+
+func (s *Simulator) tick() int64 {
+	s.clock++
+	return s.clock
+}
+
+func containsText(replies []Sent, sub string) bool {
+	for _, r := range replies {
+		if strings.Contains(r.Text, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+func describe(replies []Sent) string {
+	if len(replies) == 0 {
+		return "no replies"
+	}
+	texts := make([]string, len(replies))
+	for i, r := range replies {
+		texts[i] = fmt.Sprintf("%q", r.Text)
+	}
+	return strings.Join(texts, ", ")
+}
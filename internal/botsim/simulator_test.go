@@ -0,0 +1,582 @@
+package botsim_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/Karielka/Hackaton_MAX/internal/botsim"
+	"github.com/Karielka/Hackaton_MAX/internal/fixtures"
+	"github.com/Karielka/Hackaton_MAX/models"
+	"github.com/Karielka/Hackaton_MAX/services"
+)
+
+// newSim - симулятор поверх SQLite во временном каталоге с демо-данными
+func newSim(t *testing.T) *botsim.Simulator {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(t.TempDir()+"/bot.db?_pragma=foreign_keys(1)"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := models.AutoMigrate(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := fixtures.LoadSample(db); err != nil {
+		t.Fatal(err)
+	}
+	sim, err := botsim.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sim
+}
+
+// only - проверка, что на действие пришло ровно одно сообщение: only(t)(sim.Say(...))
+func only(t *testing.T) func([]botsim.Sent, error) botsim.Sent {
+	return func(replies []botsim.Sent, err error) botsim.Sent {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(replies) != 1 {
+			t.Fatalf("got %d replies, want 1: %+v", len(replies), replies)
+		}
+		return replies[0]
+	}
+}
+
+func labels(s botsim.Sent) []string {
+	var out []string
+	for _, b := range s.Buttons() {
+		out = append(out, b.Text)
+	}
+	return out
+}
+
+func TestStartFindTeacherCard(t *testing.T) {
+	sim := newSim(t)
+	ctx := context.Background()
+	one := only(t)
+	const user = 1
+
+	replies, err := sim.Say(ctx, user, "/start")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replies) != 2 || !strings.Contains(replies[0].Text, "Давайте познакомимся") {
+		t.Fatalf("/start replies = %+v", replies)
+	}
+	faculty := replies[1]
+	if got, want := labels(faculty), []string{"ИУ", "РК", "Э", "Пропустить"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("faculty buttons = %q, want %q", got, want)
+	}
+
+	group := one(sim.Tap(ctx, user, "Пропустить"))
+	if !strings.Contains(group.Text, "номер группы") {
+		t.Errorf("after skipping faculty: %q", group.Text)
+	}
+
+	menu := one(sim.Say(ctx, user, "/menu"))
+	if b, ok := menu.Button("1) Поиск препода"); !ok || b.Payload != "svc_find_teacher" {
+		t.Fatalf("menu has no teacher search: %q", labels(menu))
+	}
+
+	how := one(sim.Tap(ctx, user, "1) Поиск препода"))
+	if got, want := labels(how), []string{"По факультету", "По кафедре", "По ФИО", "◀️ Назад"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("search buttons = %q, want %q", got, want)
+	}
+
+	ask := one(sim.Tap(ctx, user, "По кафедре"))
+	if ask.Text != "Введите название кафедры:" {
+		t.Errorf("department prompt = %q", ask.Text)
+	}
+
+	list := one(sim.Say(ctx, user, "иу5"))
+	if !strings.HasPrefix(list.Text, "Найдено преподавателей: 3") {
+		t.Errorf("list = %q", list.Text)
+	}
+	card, ok := list.Button("ℹ️ Подробнее: Сидорова О. И.")
+	if !ok {
+		t.Fatalf("no card button in %q", labels(list))
+	}
+
+	got := one(sim.Press(ctx, user, card.Payload))
+	for _, want := range []string{"👤 Сидорова Ольга Иванович", "🏛 Кафедра: ИУ5", "📅 Расписание:"} {
+		if !strings.Contains(got.Text, want) {
+			t.Errorf("card has no %q:\n%s", want, got.Text)
+		}
+	}
+	for _, want := range []string{"✉️ Написать на почту", "📍 Где сейчас", "⭐ В избранное", "◀️ Назад"} {
+		if _, ok := got.Button(want); !ok {
+			t.Errorf("card has no button %q: %q", want, labels(got))
+		}
+	}
+	if b, _ := got.Button("✉️ Написать на почту"); !strings.HasPrefix(b.URL, "mailto:") {
+		t.Errorf("mail button = %+v, want a mailto link", b)
+	}
+}
+
+func TestRunScenario(t *testing.T) {
+	sim := newSim(t)
+	err := sim.Run(context.Background(), 2,
+		botsim.Step{Say: "/menu", Expect: "Выбери раздел"},
+		botsim.Step{Tap: "2) Деканат"},
+		botsim.Step{Say: "ИУ", Expect: "ИУ"},
+		botsim.Step{Tap: "нет такой кнопки"},
+	)
+	if err == nil || !strings.Contains(err.Error(), "step 4") {
+		t.Fatalf("Run error = %v, want a failure on step 4", err)
+	}
+}
+
+func TestTeacherCardCurrentSemester(t *testing.T) {
+	sim := newSim(t)
+	db := sim.Ctx.DB
+	old := models.Semester{
+		Name:      "Весна 2000",
+		StartDate: time.Date(2000, 2, 7, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2000, 6, 30, 0, 0, 0, 0, time.UTC),
+	}
+	if err := db.Create(&old).Error; err != nil {
+		t.Fatal(err)
+	}
+	lesson := models.Lesson{TeacherID: 15, SemesterID: &old.ID, Weekday: 1, StartMinute: 600, EndMinute: 700, Subject: "Устаревший курс"}
+	if err := db.Create(&lesson).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	card := only(t)(sim.Press(context.Background(), 1, "teacher/15"))
+	if strings.Contains(card.Text, "Устаревший курс") {
+		t.Errorf("card shows a lesson from another semester:\n%s", card.Text)
+	}
+	if !strings.Contains(card.Text, "Операционные системы") {
+		t.Errorf("card lost current lessons:\n%s", card.Text)
+	}
+	if _, ok := card.Button("📅 Экспорт в календарь"); !ok {
+		t.Errorf("no calendar export button: %q", labels(card))
+	}
+}
+
+func TestTimetableBeforeStart(t *testing.T) {
+	sim := newSim(t)
+	ctx := context.Background()
+	const user = 3
+
+	// группа выбрана в «Моём расписании» раньше, чем пользователь нажал /start
+	if _, err := sim.Press(ctx, user, "svc_my_timetable"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sim.Say(ctx, user, "ИУ5-31Б"); err != nil {
+		t.Fatal(err)
+	}
+	var u models.User
+	if err := sim.Ctx.DB.Preload("Group").First(&u, user).Error; err != nil {
+		t.Fatal(err)
+	}
+	if u.Group == nil || u.Group.Name != "ИУ5-31Б" || u.DisplayName != "user3" {
+		t.Errorf("user after timetable = %+v", u)
+	}
+
+	replies, err := sim.Say(ctx, user, "/start")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !containsText(replies, "Давайте познакомимся") {
+		t.Errorf("first /start skipped onboarding: %+v", replies)
+	}
+	menu := only(t)(sim.Say(ctx, user, "/start"))
+	if !strings.Contains(menu.Text, "Выбери раздел") {
+		t.Errorf("second /start = %q, want the menu", menu.Text)
+	}
+}
+
+func containsText(replies []botsim.Sent, sub string) bool {
+	for _, r := range replies {
+		if strings.Contains(r.Text, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestOnboardingKeepsChosenFaculty(t *testing.T) {
+	sim := newSim(t)
+	ctx := context.Background()
+	profile := func(user int64) models.User {
+		t.Helper()
+		var u models.User
+		if err := sim.Ctx.DB.Preload("Faculty").Preload("Department").First(&u, user).Error; err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+
+	// факультет выбран явно — группа с чужого факультета его не меняет
+	err := sim.Run(ctx, 4,
+		botsim.Step{Say: "/start", Expect: "С какого вы факультета?"},
+		botsim.Step{Tap: "Э", Expect: "номер группы"},
+		botsim.Step{Say: "ИУ5-31Б", Expect: "Готово"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u := profile(4); u.Faculty == nil || u.Faculty.Name != "Э" || u.Department != nil {
+		t.Errorf("faculty chosen by hand was changed: faculty %+v, department %+v", u.Faculty, u.Department)
+	}
+
+	// факультет пропущен — кафедра и факультет достраиваются по группе
+	err = sim.Run(ctx, 5,
+		botsim.Step{Say: "/start", Expect: "С какого вы факультета?"},
+		botsim.Step{Tap: "Пропустить", Expect: "номер группы"},
+		botsim.Step{Say: "ИУ5-31Б", Expect: "Готово"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u := profile(5); u.Faculty == nil || u.Faculty.Name != "ИУ" || u.Department == nil || u.Department.Name != "ИУ5" {
+		t.Errorf("profile not filled from group: faculty %+v, department %+v", u.Faculty, u.Department)
+	}
+}
+
+func TestUnknownPayload(t *testing.T) {
+	sim := newSim(t)
+	got := only(t)(sim.Press(context.Background(), 7, "no/such/button"))
+	if !strings.Contains(got.Text, "Неизвестная команда") {
+		t.Errorf("unknown payload reply = %q", got.Text)
+	}
+}
+
+func TestTeacherSearchPaging(t *testing.T) {
+	sim := newSim(t)
+	err := sim.Run(context.Background(), 8,
+		botsim.Step{Press: "svc_find_teacher", Expect: "Как будем искать?"},
+		botsim.Step{Tap: "По ФИО"},
+		botsim.Step{Say: "Сидорова", Expect: "стр. 1 из 2"},
+		botsim.Step{Tap: "▶️", Expect: "стр. 2 из 2"},
+		botsim.Step{Tap: "◀️", Expect: "стр. 1 из 2"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Второй деканат того же факультета упирается в уникальный индекс:
+// администратор видит причину словами, а не текст ошибки драйвера
+func TestAdminDuplicateDean(t *testing.T) {
+	sim := newSim(t)
+	const admin = 9
+	sim.Ctx.Admins = map[int64]bool{admin: true}
+	err := sim.Run(context.Background(), admin,
+		botsim.Step{Press: "admin/dean/new", Expect: "шаг 1/4"},
+		botsim.Step{Tap: "ИУ", Expect: "шаг 2/4"},
+		botsim.Step{Tap: "⏭ Пропустить", Expect: "шаг 3/4"},
+		botsim.Step{Tap: "⏭ Пропустить", Expect: "шаг 4/4"},
+		botsim.Step{Tap: "⏭ Пропустить", Expect: "Проверьте данные"},
+		botsim.Step{Tap: "✅ Сохранить", Expect: "Не удалось сохранить: такая запись уже есть"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last, _ := sim.Last(admin)
+	if strings.Contains(strings.ToLower(last.Text), "constraint") {
+		t.Errorf("driver error leaked to chat: %q", last.Text)
+	}
+}
+
+func TestWhereNowBetweenLessons(t *testing.T) {
+	sim := newSim(t)
+	db := sim.Ctx.DB
+	var sem models.Semester
+	if err := db.Order("start_date DESC").First(&sem).Error; err != nil {
+		t.Fatal(err)
+	}
+	s := sem.StartDate.AddDate(0, 0, 21)
+	now := time.Date(s.Year(), s.Month(), s.Day(), 8, 0, 0, 0, services.Location)
+	sim.Ctx.Now = func() time.Time { return now }
+
+	teacher := models.Teacher{FullName: "Пустов Пётр Петрович", DepartmentID: 1}
+	if err := db.Create(&teacher).Error; err != nil {
+		t.Fatal(err)
+	}
+	tomorrow := models.IsoWeekday(now.AddDate(0, 0, 1))
+	lesson := models.Lesson{TeacherID: teacher.ID, SemesterID: &sem.ID, Weekday: tomorrow, StartMinute: 600, EndMinute: 690, Subject: "Матанализ"}
+	if err := db.Create(&lesson).Error; err != nil {
+		t.Fatal(err)
+	}
+	var dep models.Department
+	if err := db.First(&dep, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	got := only(t)(sim.Press(context.Background(), 1, fmt.Sprintf("teacher/%d/now", teacher.ID)))
+	for _, want := range []string{"Сейчас занятия нет", "на кафедре " + dep.Name, "Дальше: завтра, 10:00–11:30", "Матанализ"} {
+		if !strings.Contains(got.Text, want) {
+			t.Errorf("where-now reply lacks %q:\n%s", want, got.Text)
+		}
+	}
+}
+
+// Точное название факультета узнаётся без учёта регистра и ё/е, даже если похожих несколько
+func TestDeanExactFacultyFold(t *testing.T) {
+	sim := newSim(t)
+	var iu models.Faculty
+	if err := sim.Ctx.DB.First(&iu, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Лёгкая промышленность", "Лёгкая промышленность и дизайн"} {
+		if err := sim.Ctx.DB.Create(&models.Faculty{Name: name, InstituteID: iu.InstituteID}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := sim.Run(context.Background(), 10,
+		botsim.Step{Press: "svc_dean_schedule", Expect: "Введите название факультета"},
+		botsim.Step{Say: "ЛЕГКАЯ ПРОМЫШЛЕННОСТЬ", Expect: "Для факультета \"Лёгкая промышленность\" расписание не заполнено"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFavorites(t *testing.T) {
+	sim := newSim(t)
+	ctx := context.Background()
+	const user = 21
+	var teacher models.Teacher
+	if err := sim.Ctx.DB.First(&teacher).Error; err != nil {
+		t.Fatal(err)
+	}
+	var campus models.Campus
+	if err := sim.Ctx.DB.First(&campus).Error; err != nil {
+		t.Fatal(err)
+	}
+	count := func() int64 {
+		t.Helper()
+		var n int64
+		if err := sim.Ctx.DB.Model(&models.UserFavorite{}).Where("user_id = ?", user).Count(&n).Error; err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	err := sim.Run(ctx, user,
+		botsim.Step{Press: services.ServiceFavorites, Expect: "В избранном пока пусто"},
+		botsim.Step{Press: fmt.Sprintf("fav/teacher/%d/add", teacher.ID), Expect: "Добавлено в избранное"},
+		// повторное добавление упирается в уникальный индекс: OnConflict DoNothing, без ошибки
+		botsim.Step{Press: fmt.Sprintf("fav/teacher/%d/add", teacher.ID), Expect: "Это уже есть в избранном"},
+		botsim.Step{Press: fmt.Sprintf("fav/campus/%d/add", campus.ID), Expect: "Добавлено в избранное"},
+		botsim.Step{Press: "fav/teacher/999999/add", Expect: "Не удалось добавить"},
+		botsim.Step{Press: services.ServiceFavorites, Expect: "Избранное"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := count(); n != 2 {
+		t.Fatalf("favorites: %d, want 2", n)
+	}
+	list, _ := sim.Last(user)
+	want := []string{"👤 " + teacher.FullName, "✖️", "🏫 " + campus.FullName, "✖️", "🏠 Главное меню"}
+	if got := labels(list); !reflect.DeepEqual(got, want) {
+		t.Errorf("list buttons %q, want %q", got, want)
+	}
+	if b, _ := list.Button("👤 " + teacher.FullName); b.Payload != fmt.Sprintf("teacher/%d", teacher.ID) {
+		t.Errorf("teacher button opens %q", b.Payload)
+	}
+
+	// удаление возвращает к списку без убранного
+	got := only(t)(sim.Press(ctx, user, fmt.Sprintf("fav/teacher/%d/remove", teacher.ID)))
+	if want := []string{"🏫 " + campus.FullName, "✖️", "🏠 Главное меню"}; !reflect.DeepEqual(labels(got), want) {
+		t.Errorf("after remove %q, want %q", labels(got), want)
+	}
+	got = only(t)(sim.Press(ctx, user, fmt.Sprintf("fav/campus/%d/remove", campus.ID)))
+	if !strings.Contains(got.Text, "пока пусто") || count() != 0 {
+		t.Errorf("after removing all: %q, %d left", got.Text, count())
+	}
+}
+
+func TestFavoritesLimit(t *testing.T) {
+	sim := newSim(t)
+	const user = 22
+	var ids []uint
+	if err := sim.Ctx.DB.Model(&models.Teacher{}).Order("id").Limit(31).Pluck("id", &ids).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) < 31 {
+		t.Fatalf("fixtures: %d teachers, need 31", len(ids))
+	}
+	favs := make([]models.UserFavorite, 30)
+	for i := range favs {
+		favs[i] = models.UserFavorite{UserID: user, TeacherID: &ids[i]}
+	}
+	if err := sim.Ctx.DB.Create(&favs).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	got := only(t)(sim.Press(context.Background(), user, fmt.Sprintf("fav/teacher/%d/add", ids[30])))
+	if !strings.Contains(got.Text, "В избранном уже 30 записей") {
+		t.Errorf("31st favorite: %q", got.Text)
+	}
+	var n int64
+	sim.Ctx.DB.Model(&models.UserFavorite{}).Where("user_id = ?", user).Count(&n)
+	if n != 30 {
+		t.Errorf("favorites after limit: %d, want 30", n)
+	}
+	// чужой лимит не мешает другим пользователям
+	got = only(t)(sim.Press(context.Background(), user+1, fmt.Sprintf("fav/teacher/%d/add", ids[30])))
+	if !strings.Contains(got.Text, "Добавлено") {
+		t.Errorf("other user: %q", got.Text)
+	}
+}
+
+func TestDatedMenu(t *testing.T) {
+	sim := newSim(t)
+	ctx := context.Background()
+	const user = 23
+	now := time.Date(2030, 9, 2, 12, 0, 0, 0, services.Location) // понедельник
+	sim.Ctx.Now = func() time.Time { return now }
+
+	var campus models.Campus
+	if err := sim.Ctx.DB.First(&campus).Error; err != nil {
+		t.Fatal(err)
+	}
+	place := models.Place{CampusID: campus.ID, Type: "canteen", Name: "Столовая тестов", Location: "1 этаж"}
+	if err := sim.Ctx.DB.Create(&place).Error; err != nil {
+		t.Fatal(err)
+	}
+	card := fmt.Sprintf("place/%d", place.ID)
+	menuOn := func(day time.Time) string {
+		return fmt.Sprintf("place/%d/menu/%s", place.ID, day.Format(time.DateOnly))
+	}
+
+	// меню ещё нет
+	got := only(t)(sim.Press(ctx, user, card))
+	if !strings.Contains(got.Text, "Меню на сегодня не опубликовано") {
+		t.Errorf("no menu: %q", got.Text)
+	}
+
+	_, err := models.ReplaceMenu(sim.Ctx.DB, place.ID, now, []models.Dish{
+		{Category: "Супы", Name: "Борщ", PriceKop: 9550, Weight: "250 г", Allergens: "молоко"},
+		{Category: "Напитки", Name: "Компот", PriceKop: 3500},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = only(t)(sim.Press(ctx, user, card))
+	for _, want := range []string{"Меню на сегодня:", "Супы:\n• Борщ, 250 г — 95,50 ₽", "⚠️ Аллергены: молоко", "Напитки:\n• Компот — 35 ₽"} {
+		if !strings.Contains(got.Text, want) {
+			t.Errorf("today menu has no %q:\n%s", want, got.Text)
+		}
+	}
+	// завтрашнее меню не опубликовано — кнопки нет
+	if _, ok := got.Button("📅 Меню на завтра"); ok {
+		t.Errorf("tomorrow button without tomorrow menu")
+	}
+
+	tomorrow := now.AddDate(0, 0, 1)
+	if _, err := models.ReplaceMenu(sim.Ctx.DB, place.ID, tomorrow, []models.Dish{{Name: "Щи", PriceKop: 8000}}); err != nil {
+		t.Fatal(err)
+	}
+	got = only(t)(sim.Press(ctx, user, card))
+	if b, ok := got.Button("📅 Меню на завтра"); !ok || b.Payload != menuOn(tomorrow) {
+		t.Fatalf("tomorrow button %+v in %q", b, labels(got))
+	}
+	err = sim.Run(ctx, user,
+		botsim.Step{Tap: "📅 Меню на завтра", Expect: "Меню на завтра:\n• Щи — 80 ₽"},
+		botsim.Step{Tap: "📋 Меню на сегодня", Expect: "• Борщ"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// день без меню дальше завтрашнего
+	got = only(t)(sim.Press(ctx, user, menuOn(now.AddDate(0, 0, 3))))
+	if !strings.Contains(got.Text, "05.09 не опубликовано") {
+		t.Errorf("later day: %q", got.Text)
+	}
+	if _, ok := got.Button("📋 Меню на сегодня"); !ok {
+		t.Errorf("later day has no way back to today: %q", labels(got))
+	}
+}
+
+// «Открыто сейчас»: свой корпус, затем ближние, при равном расстоянии — кто дольше открыт,
+// корпуса без координат — в конце
+func TestOpenNowByDistance(t *testing.T) {
+	sim := newSim(t)
+	ctx := context.Background()
+	db := sim.Ctx.DB
+	// воскресенье: демо-места работают по будням и в список не попадают
+	now := time.Date(2030, 9, 8, 12, 0, 0, 0, services.Location)
+	sim.Ctx.Now = func() time.Time { return now }
+
+	coords := func(lat float64) (*float64, *float64) { lon := 37.6; return &lat, &lon }
+	campus := func(name string, lat float64) models.Campus {
+		c := models.Campus{ShortName: name, FullName: name}
+		if lat != 0 {
+			c.Latitude, c.Longitude = coords(lat)
+		}
+		if err := db.Create(&c).Error; err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	home, near, far, unknown := campus("Дом", 55.700), campus("Рядом", 55.709), campus("Далеко", 55.745), campus("Без координат", 0)
+	place := func(name string, c models.Campus, open, close int) {
+		p := models.Place{CampusID: c.ID, Type: "copy", Name: name}
+		if err := db.Create(&p).Error; err != nil {
+			t.Fatal(err)
+		}
+		h := models.OpeningHours{PlaceID: &p.ID, Weekday: 7, OpenMinute: open * 60, CloseMinute: close * 60}
+		if err := db.Create(&h).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	place("Копирка дома", home, 9, 13)
+	place("Копирка далеко", far, 9, 20)
+	place("Копирка рядом", near, 9, 14)
+	place("Копирка рядом до вечера", near, 9, 18)
+	place("Копирка без координат", unknown, 9, 23)
+	place("Копирка дома после обеда", home, 15, 18)
+
+	names := func(s botsim.Sent) []string {
+		var out []string
+		for _, l := range labels(s) {
+			if _, name, ok := strings.Cut(l, ". "); ok {
+				out = append(out, name)
+			}
+		}
+		return out
+	}
+
+	// без корпуса в профиле — только по тому, кто дольше открыт
+	got := only(t)(sim.Press(ctx, 24, "open_now/copy"))
+	want := []string{"Копирка без координат", "Копирка далеко", "Копирка рядом до вечера", "Копирка рядом", "Копирка дома"}
+	if !reflect.DeepEqual(names(got), want) {
+		t.Errorf("without campus: %q, want %q", names(got), want)
+	}
+	if !strings.Contains(got.Text, "Укажите свой корпус в профиле") {
+		t.Errorf("no profile hint: %q", got.Text)
+	}
+
+	if err := db.Create(&models.User{ID: 25, CampusID: &home.ID}).Error; err != nil {
+		t.Fatal(err)
+	}
+	got = only(t)(sim.Press(ctx, 25, "open_now/copy"))
+	want = []string{"Копирка дома", "Копирка рядом до вечера", "Копирка рядом", "Копирка далеко", "Копирка без координат"}
+	if !reflect.DeepEqual(names(got), want) {
+		t.Errorf("from home campus: %q, want %q", names(got), want)
+	}
+	for _, note := range []string{"Копирка дома — Дом (ваш корпус)", "Копирка рядом — Рядом, 1,0 км", "Копирка далеко — Далеко, 5,0 км", "Копирка без координат — Без координат\n"} {
+		if !strings.Contains(got.Text, note) {
+			t.Errorf("no %q in:\n%s", note, got.Text)
+		}
+	}
+	if strings.Contains(got.Text, "после обеда") || strings.Contains(got.Text, "Укажите свой корпус") {
+		t.Errorf("closed place or profile hint shown:\n%s", got.Text)
+	}
+}
@@ -0,0 +1,277 @@
+// Package calendar - выгрузка расписания в iCalendar (RFC 5545): файл для бота
+// и подписка по ссылке. Каждое занятие — одно повторяющееся событие на семестр.
+package calendar
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// ErrNotFound - нет такого преподавателя или группы
+var ErrNotFound = errors.New("not found")
+
+// домен в UID событий: UID должен быть глобально уникальным и не меняться между выгрузками
+const uidDomain = "hackaton-max.bot"
+
+// Calendar - расписание для выгрузки
+type Calendar struct {
+	Name     string           // название календаря (X-WR-CALNAME)
+	Semester *models.Semester // nil — семестров нет, календарь пустой
+	Lessons  []models.Lesson  // с подгруженными Campus, Teacher и Groups (что есть, то и попадёт в описание)
+	Location *time.Location   // часовой пояс университета
+	Stamp    time.Time        // DTSTAMP — момент выгрузки
+}
+
+// Loader - ForTeacher или ForGroup
+type Loader func(db *gorm.DB, id uint, now time.Time, loc *time.Location) (*Calendar, error)
+
+// ForTeacher - занятия преподавателя в текущем семестре
+func ForTeacher(db *gorm.DB, teacherID uint, now time.Time, loc *time.Location) (*Calendar, error) {
+	var t models.Teacher
+	if err := db.First(&t, teacherID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch teacher: %w", err)
+	}
+	c, err := newCalendar(db, t.FullName, now, loc)
+	if err != nil || c.Semester == nil {
+		return c, err
+	}
+	err = db.Where("teacher_id = ?", t.ID).
+		Where("semester_id = ? OR semester_id IS NULL", c.Semester.ID).
+		Preload("Campus").Preload("Groups").
+		Order("weekday, start_minute").
+		Find(&c.Lessons).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch lessons: %w", err)
+	}
+	for i := range c.Lessons {
+		c.Lessons[i].Teacher = t
+	}
+	return c, nil
+}
+
+// ForGroup - занятия группы в текущем семестре
+func ForGroup(db *gorm.DB, groupID uint, now time.Time, loc *time.Location) (*Calendar, error) {
+	var g models.Group
+	if err := db.First(&g, groupID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch group: %w", err)
+	}
+	c, err := newCalendar(db, g.Name, now, loc)
+	if err != nil || c.Semester == nil {
+		return c, err
+	}
+	err = db.Model(&models.Lesson{}).
+		Joins("JOIN lesson_groups lg ON lg.lesson_id = lessons.id").
+		Where("lg.group_id = ?", g.ID).
+		Where("lessons.semester_id = ? OR lessons.semester_id IS NULL", c.Semester.ID).
+		Preload("Campus").Preload("Teacher").
+		Order("weekday, start_minute").
+		Find(&c.Lessons).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch lessons: %w", err)
+	}
+	return c, nil
+}
+
+// newCalendar - пустой календарь текущего семестра (последнего начавшегося, иначе ближайшего)
+func newCalendar(db *gorm.DB, name string, now time.Time, loc *time.Location) (*Calendar, error) {
+	c := &Calendar{Name: name, Location: loc, Stamp: now}
+	var sem models.Semester
+	err := db.Where("start_date <= ?", now.Format(time.DateOnly)).Order("start_date DESC").First(&sem).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = db.Order("start_date").First(&sem).Error
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch semester: %w", err)
+	}
+	c.Semester = &sem
+	return c, nil
+}
+
+// Encode - текст календаря (строки через CRLF, длинные строки перенесены)
+func (c *Calendar) Encode() []byte {
+	loc := c.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	w := &writer{}
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:-//Hackaton MAX//University bot//RU")
+	w.line("CALSCALE:GREGORIAN")
+	w.line("METHOD:PUBLISH")
+	w.prop("X-WR-CALNAME", c.Name)
+	w.line("X-WR-TIMEZONE:" + loc.String())
+
+	if c.Semester != nil {
+		start := dateIn(c.Semester.StartDate, loc)
+		writeTimezone(w, loc, start)
+		// последний момент последнего дня семестра; UNTIL при TZID пишется в UTC
+		until := dateIn(c.Semester.EndDate, loc).AddDate(0, 0, 1).Add(-time.Second).UTC()
+		for _, l := range c.Lessons {
+			first, ok := firstOccurrence(l, start, until)
+			if !ok {
+				continue
+			}
+			c.writeEvent(w, l, first, until, loc)
+		}
+	}
+	w.line("END:VCALENDAR")
+	return w.buf.Bytes()
+}
+
+// firstOccurrence - первый день занятия в семестре с учётом дня недели и чётности
+func firstOccurrence(l models.Lesson, start, until time.Time) (time.Time, bool) {
+	for i := 0; i < 14; i++ {
+		d := start.AddDate(0, 0, i)
+		if models.IsoWeekday(d) == l.Weekday && l.OccursIn(models.ParityAt(start, d)) {
+			return d, !d.After(until)
+		}
+	}
+	return time.Time{}, false
+}
+
+func (c *Calendar) writeEvent(w *writer, l models.Lesson, day, until time.Time, loc *time.Location) {
+	at := func(minute int) string {
+		return day.Add(time.Duration(minute) * time.Minute).Format("20060102T150405")
+	}
+	interval := 1
+	if l.WeekParity != models.ParityAny {
+		interval = 2 // чётная или нечётная — через неделю
+	}
+
+	summary := l.Subject
+	if l.LessonType != "" {
+		summary += " (" + models.LessonTypeName(l.LessonType) + ")"
+	}
+	var where []string
+	if l.Room != "" {
+		where = append(where, "ауд. "+l.Room)
+	}
+	if l.Campus != nil && l.Campus.ID != 0 {
+		where = append(where, l.Campus.ShortName)
+		if l.Campus.Address != "" {
+			where = append(where, l.Campus.Address)
+		}
+	}
+	var desc []string
+	if l.Teacher.ID != 0 {
+		desc = append(desc, "Преподаватель: "+l.Teacher.FullName)
+	}
+	if len(l.Groups) > 0 {
+		names := make([]string, len(l.Groups))
+		for i, g := range l.Groups {
+			names[i] = g.Name
+		}
+		desc = append(desc, "Группы: "+strings.Join(names, ", "))
+	}
+	switch l.WeekParity {
+	case models.ParityOdd:
+		desc = append(desc, "По нечётным неделям")
+	case models.ParityEven:
+		desc = append(desc, "По чётным неделям")
+	}
+
+	w.line("BEGIN:VEVENT")
+	w.line(fmt.Sprintf("UID:lesson-%d@%s", l.ID, uidDomain))
+	w.line("DTSTAMP:" + c.Stamp.UTC().Format("20060102T150405Z"))
+	w.line(fmt.Sprintf("DTSTART;TZID=%s:%s", loc.String(), at(l.StartMinute)))
+	w.line(fmt.Sprintf("DTEND;TZID=%s:%s", loc.String(), at(l.EndMinute)))
+	w.line(fmt.Sprintf("RRULE:FREQ=WEEKLY;INTERVAL=%d;UNTIL=%s", interval, until.Format("20060102T150405Z")))
+	w.prop("SUMMARY", summary)
+	if len(where) > 0 {
+		w.prop("LOCATION", strings.Join(where, ", "))
+	}
+	if len(desc) > 0 {
+		w.prop("DESCRIPTION", strings.Join(desc, "\n"))
+	}
+	if l.LessonType != "" {
+		w.prop("CATEGORIES", models.LessonTypeName(l.LessonType))
+	}
+	w.line("END:VEVENT")
+}
+
+// writeTimezone - VTIMEZONE со смещением пояса на начало семестра.
+// В России нет перехода на летнее время, поэтому одного STANDARD достаточно.
+func writeTimezone(w *writer, loc *time.Location, at time.Time) {
+	name, offset := at.Zone()
+	sign := '+'
+	if offset < 0 {
+		sign, offset = '-', -offset
+	}
+	off := fmt.Sprintf("%c%02d%02d", sign, offset/3600, offset%3600/60)
+	w.line("BEGIN:VTIMEZONE")
+	w.line("TZID:" + loc.String())
+	w.line("BEGIN:STANDARD")
+	w.line("DTSTART:19700101T000000")
+	w.line("TZOFFSETFROM:" + off)
+	w.line("TZOFFSETTO:" + off)
+	w.line("TZNAME:" + name)
+	w.line("END:STANDARD")
+	w.line("END:VTIMEZONE")
+}
+
+// dateIn - полночь того же календарного дня в поясе loc (даты семестра хранятся в UTC)
+func dateIn(d time.Time, loc *time.Location) time.Time {
+	return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, loc)
+}
+
+// writer собирает строки календаря
+type writer struct {
+	buf bytes.Buffer
+}
+
+// prop - свойство с текстовым значением (экранируется)
+func (w *writer) prop(name, value string) {
+	w.line(name + ":" + escapeText(value))
+}
+
+// line пишет строку, перенося её по 75 байт без разрыва UTF-8 символов
+func (w *writer) line(s string) {
+	const limit = 75
+	for first := true; ; first = false {
+		n := limit
+		if !first {
+			w.buf.WriteByte(' ')
+			n-- // пробел продолжения тоже считается
+		}
+		if len(s) <= n {
+			w.buf.WriteString(s)
+			w.buf.WriteString("\r\n")
+			return
+		}
+		cut := n
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.buf.WriteString(s[:cut])
+		w.buf.WriteString("\r\n")
+		s = s[cut:]
+	}
+}
+
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// Адреса подписки на HTTP-сервере (internal/api), относительно PUBLIC_URL
+const (
+	TeacherPath = "/calendar/teacher/%d.ics"
+	GroupPath   = "/calendar/group/%d.ics"
+)
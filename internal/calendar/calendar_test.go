@@ -0,0 +1,288 @@
+package calendar
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/Karielka/Hackaton_MAX/internal/fixtures"
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// msk - фиксированный пояс, чтобы тест не зависел от базы tzdata в системе
+var msk = time.FixedZone("MSK", 3*60*60)
+
+// unfold - строки календаря без переносов; заодно проверяет CRLF и длину строк
+func unfold(t *testing.T, data []byte) []string {
+	t.Helper()
+	s := string(data)
+	if !strings.HasSuffix(s, "\r\n") {
+		t.Fatalf("calendar must end with CRLF")
+	}
+	var lines []string
+	for _, raw := range strings.Split(strings.TrimSuffix(s, "\r\n"), "\r\n") {
+		if len(raw) > 75 {
+			t.Errorf("line longer than 75 bytes: %q", raw)
+		}
+		if !utf8.ValidString(raw) {
+			t.Errorf("line splits a UTF-8 character: %q", raw)
+		}
+		if strings.Contains(raw, "\n") {
+			t.Errorf("bare LF in line %q", raw)
+		}
+		if strings.HasPrefix(raw, " ") && len(lines) > 0 {
+			lines[len(lines)-1] += raw[1:]
+			continue
+		}
+		lines = append(lines, raw)
+	}
+	return lines
+}
+
+// events - свойства событий по порядку
+func events(lines []string) []map[string]string {
+	var out []map[string]string
+	var cur map[string]string
+	for _, l := range lines {
+		switch l {
+		case "BEGIN:VEVENT":
+			cur = map[string]string{}
+		case "END:VEVENT":
+			out = append(out, cur)
+			cur = nil
+		default:
+			if cur != nil {
+				name, _, _ := strings.Cut(l, ":")
+				name, _, _ = strings.Cut(name, ";")
+				cur[name] = l
+			}
+		}
+	}
+	return out
+}
+
+func TestEncode(t *testing.T) {
+	campus := &models.Campus{ID: 1, ShortName: "ГУК", Address: "ул. 2-я Бауманская, 5"}
+	c := &Calendar{
+		Name: "Иванов Иван Иванович",
+		// семестр начинается в среду: первая нечётная неделя — с понедельника 2 сентября
+		Semester: &models.Semester{
+			StartDate: time.Date(2030, 9, 4, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2030, 12, 28, 0, 0, 0, 0, time.UTC),
+		},
+		Lessons: []models.Lesson{
+			{
+				ID: 7, Weekday: 1, StartMinute: 10*60 + 15, EndMinute: 11*60 + 50, WeekParity: models.ParityOdd,
+				Subject: "Базы данных; основы, часть 1", LessonType: models.LessonLecture,
+				Room: "А-101", Campus: campus,
+				Teacher: models.Teacher{ID: 3, FullName: "Иванов Иван Иванович"},
+				Groups:  []models.Group{{Name: "ИУ5-31Б"}, {Name: "ИУ5-32Б"}, {Name: "ИУ5-33Б"}, {Name: "ИУ5-34Б"}},
+			},
+			{ID: 8, Weekday: 4, StartMinute: 9 * 60, EndMinute: 10*60 + 35, Subject: "Сети"},
+			{ID: 9, Weekday: 2, StartMinute: 9 * 60, EndMinute: 10*60 + 35, WeekParity: models.ParityEven, Subject: `Физика \ оптика`},
+		},
+		Location: msk,
+		Stamp:    time.Date(2030, 9, 1, 12, 0, 0, 0, time.UTC),
+	}
+	lines := unfold(t, c.Encode())
+	if lines[0] != "BEGIN:VCALENDAR" || lines[len(lines)-1] != "END:VCALENDAR" {
+		t.Errorf("calendar frame: %q … %q", lines[0], lines[len(lines)-1])
+	}
+	for _, want := range []string{"X-WR-CALNAME:Иванов Иван Иванович", "TZID:MSK", "TZOFFSETTO:+0300"} {
+		if !slices.Contains(lines, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+
+	ev := events(lines)
+	if len(ev) != 3 {
+		t.Fatalf("events: %d, want 3", len(ev))
+	}
+	until := "UNTIL=20301228T205959Z" // 23:59:59 по Москве в последний день семестра
+	for _, tc := range []struct {
+		ev   map[string]string
+		want []string
+	}{
+		{ev[0], []string{
+			"UID:lesson-7@" + uidDomain,
+			"DTSTAMP:20300901T120000Z",
+			// 9 сентября — чётная неделя, первая нечётная пара 16-го
+			"DTSTART;TZID=MSK:20300916T101500",
+			"DTEND;TZID=MSK:20300916T115000",
+			"RRULE:FREQ=WEEKLY;INTERVAL=2;" + until,
+			`SUMMARY:Базы данных\; основы\, часть 1 (лекция)`,
+			`LOCATION:ауд. А-101\, ГУК\, ул. 2-я Бауманская\, 5`,
+			`DESCRIPTION:Преподаватель: Иванов Иван Иванович\nГруппы: ИУ5-31Б\, ИУ5-32Б\, ИУ5-33Б\, ИУ5-34Б\nПо нечётным неделям`,
+			"CATEGORIES:лекция",
+		}},
+		{ev[1], []string{
+			"DTSTART;TZID=MSK:20300905T090000",
+			"RRULE:FREQ=WEEKLY;INTERVAL=1;" + until,
+			"SUMMARY:Сети",
+		}},
+		{ev[2], []string{
+			"DTSTART;TZID=MSK:20300910T090000",
+			"RRULE:FREQ=WEEKLY;INTERVAL=2;" + until,
+			`SUMMARY:Физика \\ оптика`,
+			`DESCRIPTION:По чётным неделям`,
+		}},
+	} {
+		for _, want := range tc.want {
+			name, _, _ := strings.Cut(want, ":")
+			name, _, _ = strings.Cut(name, ";")
+			if tc.ev[name] != want {
+				t.Errorf("%s: got %q, want %q", name, tc.ev[name], want)
+			}
+		}
+	}
+	if _, ok := ev[1]["LOCATION"]; ok {
+		t.Errorf("lesson without room must have no LOCATION")
+	}
+	if _, ok := ev[1]["CATEGORIES"]; ok {
+		t.Errorf("lesson without type must have no CATEGORIES")
+	}
+}
+
+func TestEncodeEdges(t *testing.T) {
+	// без семестра — пустой, но корректный календарь
+	lines := unfold(t, (&Calendar{Name: "ИУ5-31Б", Location: msk}).Encode())
+	if len(events(lines)) != 0 || slices.Contains(lines, "BEGIN:VTIMEZONE") {
+		t.Errorf("calendar without semester: %q", lines)
+	}
+
+	// занятие, которое не успевает случиться до конца семестра, пропускается
+	c := &Calendar{
+		Semester: &models.Semester{
+			StartDate: time.Date(2030, 9, 2, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2030, 9, 6, 0, 0, 0, 0, time.UTC),
+		},
+		Lessons: []models.Lesson{
+			{ID: 1, Weekday: 5, StartMinute: 600, EndMinute: 690, Subject: "Пятница"},
+			{ID: 2, Weekday: 6, StartMinute: 600, EndMinute: 690, Subject: "Суббота"},
+			{ID: 3, Weekday: 1, StartMinute: 600, EndMinute: 690, WeekParity: models.ParityEven, Subject: "Чётный понедельник"},
+		},
+		Location: msk,
+	}
+	ev := events(unfold(t, c.Encode()))
+	if len(ev) != 1 || ev[0]["SUMMARY"] != "SUMMARY:Пятница" {
+		t.Errorf("short semester: %v", ev)
+	}
+}
+
+func TestLineFolding(t *testing.T) {
+	w := &writer{}
+	long := "DESCRIPTION:" + strings.Repeat("Щ", 100) // 2 байта на символ
+	w.line(long)
+	got := w.buf.String()
+	for _, raw := range strings.Split(strings.TrimSuffix(got, "\r\n"), "\r\n") {
+		if len(raw) > 75 || !utf8.ValidString(raw) {
+			t.Errorf("bad folded line %q (%d bytes)", raw, len(raw))
+		}
+	}
+	if unfolded := strings.ReplaceAll(strings.TrimSuffix(got, "\r\n"), "\r\n ", ""); unfolded != long {
+		t.Errorf("unfold(fold(s)) != s: %q", unfolded)
+	}
+	if got := escapeText("a\\b;c,d\r\ne\nf"); got != `a\\b\;c\,d\ne\nf` {
+		t.Errorf("escapeText = %q", got)
+	}
+}
+
+func openDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(t.TempDir()+"/calendar.db?_pragma=foreign_keys(1)"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := models.AutoMigrate(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := fixtures.LoadSample(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestLoaders(t *testing.T) {
+	db := openDB(t)
+	now := time.Now()
+
+	var current models.Semester
+	if err := db.Order("start_date DESC").First(&current).Error; err != nil {
+		t.Fatal(err)
+	}
+	var group models.Group
+	if err := db.Where("name = ?", "ИУ5-31Б").First(&group).Error; err != nil {
+		t.Fatal(err)
+	}
+	var lesson models.Lesson
+	if err := db.Joins("JOIN lesson_groups lg ON lg.lesson_id = lessons.id").
+		Where("lg.group_id = ?", group.ID).First(&lesson).Error; err != nil {
+		t.Fatal(err)
+	}
+	teacherID := lesson.TeacherID
+
+	// следующий семестр со своим занятием: в текущий календарь оно попасть не должно
+	next := models.Semester{Name: "Следующий", StartDate: current.EndDate.AddDate(0, 0, 1), EndDate: current.EndDate.AddDate(0, 5, 0)}
+	if err := db.Create(&next).Error; err != nil {
+		t.Fatal(err)
+	}
+	future := models.Lesson{TeacherID: teacherID, SemesterID: &next.ID, Weekday: 7, StartMinute: 600, EndMinute: 690,
+		Subject: "Будущий предмет", Groups: []models.Group{group}}
+	if err := db.Omit("Groups.*").Create(&future).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range map[string]struct {
+		load Loader
+		id   uint
+		cal  string
+	}{
+		"teacher": {ForTeacher, teacherID, ""},
+		"group":   {ForGroup, group.ID, "ИУ5-31Б"},
+	} {
+		c, err := tc.load(db, tc.id, now, msk)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if c.Semester == nil || c.Semester.ID != current.ID {
+			t.Fatalf("%s: semester %+v, want %q", name, c.Semester, current.Name)
+		}
+		if tc.cal != "" && c.Name != tc.cal {
+			t.Errorf("%s: name %q, want %q", name, c.Name, tc.cal)
+		}
+		if len(c.Lessons) == 0 {
+			t.Fatalf("%s: no lessons", name)
+		}
+		for _, l := range c.Lessons {
+			if l.SemesterID != nil && *l.SemesterID != current.ID {
+				t.Errorf("%s: lesson %d from semester %d", name, l.ID, *l.SemesterID)
+			}
+			if l.Teacher.ID == 0 {
+				t.Errorf("%s: lesson %d has no teacher loaded", name, l.ID)
+			}
+			if name == "teacher" && l.TeacherID != teacherID {
+				t.Errorf("teacher: lesson %d of teacher %d", l.ID, l.TeacherID)
+			}
+		}
+
+		// после начала следующего семестра в календаре только его занятия
+		c, err = tc.load(db, tc.id, next.StartDate.AddDate(0, 0, 1), msk)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if c.Semester.ID != next.ID || len(c.Lessons) != 1 || c.Lessons[0].ID != future.ID {
+			t.Errorf("%s: next semester lessons %+v", name, c.Lessons)
+		}
+
+		if _, err := tc.load(db, 999999, now, msk); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: unknown id: err %v, want ErrNotFound", name, err)
+		}
+	}
+}
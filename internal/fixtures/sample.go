@@ -0,0 +1,458 @@
+// Package fixtures - демонстрационные данные для разработки и симулятора.
+//
+// В прод они не попадают: загрузка — отдельный явный шаг (APP_ENV=development или
+// «app fixtures -force»). Ссылки между записями ищутся по естественным ключам
+// (имя корпуса, факультета, кафедры), а не по id, поэтому загрузка повторяема
+// и не зависит от того, какие id выдала база.
+package fixtures
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// LoadSample наполняет БД тестовыми данными одной транзакцией:
+// Institute -> Faculty("ИУ") -> Departments("ИУ1".."ИУ10") -> Teachers(2-3 на кафедру)
+func LoadSample(db *gorm.DB) error {
+	return db.Transaction(loadSample)
+}
+
+func loadSample(db *gorm.DB) error {
+	// 1) Институт (общий)
+	inst := models.Institute{Name: "Институт информатики и систем управления"}
+	if err := db.Where("name = ?", inst.Name).FirstOrCreate(&inst).Error; err != nil {
+		return fmt.Errorf("seed institute: %w", err)
+	}
+	// Добавляем корпуса
+	campuses := []models.Campus{
+		{
+			ShortName:   "ГУК",
+			FullName:    "Главный учебный корпус",
+			Address:     "ул. Студенческая, д. 35",
+			Metro:       "«Университетская» (5 мин. пешком)",
+			ImageURL:    "https://example.com/images/guk.jpg",
+			MapImageURL: "https://example.com/maps/guk_map.jpg",
+			Description: "• Аудитории 100-499\n• Деканат ФМиЕН\n• Столовая №1\n• Библиотека",
+			Latitude:    ptr(55.7658),
+			Longitude:   ptr(37.6854),
+		},
+		{
+			ShortName:   "Корпус 2",
+			FullName:    "Второй учебный корпус",
+			Address:     "ул. Академическая, д. 15",
+			Metro:       "«Научная» (10 мин. пешком)",
+			ImageURL:    "https://example.com/images/corpus2.jpg",
+			MapImageURL: "https://example.com/maps/corpus2_map.jpg",
+			Description: "• Аудитории 500-799\n• Лаборатории физики\n• Буфет №2\n• Спортивный зал",
+			Latitude:    ptr(55.7712),
+			Longitude:   ptr(37.6908),
+		},
+	}
+
+	campusIDs := map[string]uint{}
+	for i := range campuses {
+		if err := db.FirstOrCreate(&campuses[i], models.Campus{ShortName: campuses[i].ShortName}).Error; err != nil {
+			return fmt.Errorf("seed campus %s: %w", campuses[i].ShortName, err)
+		}
+		campusIDs[campuses[i].ShortName] = campuses[i].ID
+	}
+
+	// Добавляем места (столовые, буфеты, копирки); корпус — по короткому названию
+	places := []struct {
+		campus string
+		models.Place
+	}{
+		// Столовые в ГУК
+		{campus: "ГУК", Place: models.Place{
+			Type:     "canteen",
+			Name:     "Столовая №1",
+			Location: "1 этаж, левое крыло",
+			Schedule: "Пн-Пт 9:00 - 17:00",
+		}},
+		// Буфеты в ГУК
+		{campus: "ГУК", Place: models.Place{
+			Type:     "buffet",
+			Name:     "Буфет №1",
+			Location: "2 этаж, центральный холл",
+			Schedule: "Пн-Пт 8:00 - 20:00",
+		}},
+		{campus: "ГУК", Place: models.Place{
+			Type:     "buffet",
+			Name:     "Буфет №2",
+			Location: "3 этаж, правое крыло",
+			Schedule: "Пн-Пт 9:00 - 18:00",
+		}},
+		// Копирки в ГУК
+		{campus: "ГУК", Place: models.Place{
+			Type:      "copy",
+			Name:      "Копировальный центр №1",
+			Location:  "1 этаж, рядом с библиотекой",
+			Schedule:  "Пн-Пт 9:00 - 18:00",
+			MenuToday: "• Печать ч/б (5 руб/стр)\n• Печать цветная (20 руб/стр)\n• Ксерокопия (3 руб/стр)\n• Брошюровка (от 50 руб)",
+		}},
+
+		// Места в Корпусе 2
+		{campus: "Корпус 2", Place: models.Place{
+			Type:     "canteen",
+			Name:     "Столовая №2",
+			Location: "Цокольный этаж",
+			Schedule: "Пн-Пт 10:00 - 16:00",
+		}},
+		{campus: "Корпус 2", Place: models.Place{
+			Type:      "copy",
+			Name:      "Копировальный центр №2",
+			Location:  "2 этаж, каб. 215",
+			Schedule:  "Пн-Пт 10:00 - 17:00",
+			MenuToday: "• Печать ч/б (4 руб/стр)\n• Сканирование (10 руб/стр)\n• Ламинирование (30 руб/стр)",
+		}},
+	}
+
+	placeIDs := map[string]uint{}
+	for _, p := range places {
+		campusID, ok := campusIDs[p.campus]
+		if !ok {
+			return fmt.Errorf("seed place %s: unknown campus %q", p.Name, p.campus)
+		}
+		place := p.Place
+		place.CampusID = campusID
+		if err := db.FirstOrCreate(&place,
+			models.Place{CampusID: place.CampusID, Type: place.Type, Name: place.Name}).Error; err != nil {
+			return fmt.Errorf("seed place %s: %w", place.Name, err)
+		}
+		placeIDs[place.Name] = place.ID
+	}
+	if err := loadSampleMenus(db, placeIDs, time.Now()); err != nil {
+		return err
+	}
+
+	// 2) Факультет "ИУ"
+	faculties := []models.Faculty{
+		{Name: "ИУ", InstituteID: inst.ID},
+		{Name: "Э", InstituteID: inst.ID},
+		{Name: "РК", InstituteID: inst.ID},
+	}
+	for i, f := range faculties {
+		if err := db.Where("name = ?", f.Name).FirstOrCreate(&faculties[i]).Error; err != nil {
+			return fmt.Errorf("seed faculty %s: %w", f.Name, err)
+		}
+	}
+
+	// Текущий семестр — от него считается чётность недель
+	sem := sampleSemester(time.Now())
+	if err := db.Where("name = ?", sem.Name).
+		Attrs(models.Semester{StartDate: sem.StartDate, EndDate: sem.EndDate}).
+		FirstOrCreate(&sem).Error; err != nil {
+		return fmt.Errorf("seed semester: %w", err)
+	}
+
+	// 3) Кафедры ИУ1..ИУ10
+	for _, fac := range faculties {
+		for i := 1; i <= 5; i++ {
+			depName := fmt.Sprintf("%s%d", fac.Name, i)
+			dep := models.Department{Name: depName, FacultyID: fac.ID}
+			if err := db.Where("name = ?", dep.Name).
+				Attrs(models.Department{Office: fmt.Sprintf("каб. %s-3%02d", fac.Name, i)}).
+				FirstOrCreate(&dep).Error; err != nil {
+				return fmt.Errorf("seed department %s: %w", depName, err)
+			}
+
+			// по две группы на кафедру: ИУ1-31Б, ИУ1-32Б
+			groups := make([]models.Group, 2)
+			for g := range groups {
+				groups[g].Name = fmt.Sprintf("%s-3%dБ", depName, g+1)
+				if err := db.Where("name = ?", groups[g].Name).FirstOrCreate(&groups[g]).Error; err != nil {
+					return fmt.Errorf("seed group %s: %w", groups[g].Name, err)
+				}
+			}
+
+			teachers := sampleTeachersFor(depName, dep.ID, i, sem.ID, campuses, groups)
+			for _, t := range teachers {
+				var existing models.Teacher
+				err := db.Where("full_name = ? AND department_id = ?", t.FullName, dep.ID).First(&existing).Error
+				if err == gorm.ErrRecordNotFound {
+					if err := db.Create(&t).Error; err != nil {
+						return fmt.Errorf("seed teacher %s/%s: %w", depName, t.FullName, err)
+					}
+				} else if err != nil {
+					return fmt.Errorf("check teacher %s/%s: %w", depName, t.FullName, err)
+				}
+			}
+		}
+	}
+
+	// --- 5) Деканаты (DeanOffice) для каждого факультета
+	for i, fac := range faculties {
+		office := models.DeanOffice{
+			FacultyID: fac.ID,
+			Schedule: fmt.Sprintf(
+				"Пн–Чт: 10:00–17:00 (обед 13:00–14:00)\nПт: 10:00–16:00\nСб–Вс: выходной\n\nОтветственный секретарь: %s",
+				randomSecretary(fac.Name),
+			),
+			DocsLink: fmt.Sprintf("https://example.edu/%s/dean/docs", strings.ToLower(fac.Name)),
+			Contacts: fmt.Sprintf("Тел.: +7 (495) 000-00-%03d, каб. %s-204", i+101, fac.Name),
+		}
+		if err := db.Where("faculty_id = ?", office.FacultyID).
+			Assign(office). // если перезапускать сид, обновим данные
+			FirstOrCreate(&models.DeanOffice{}).Error; err != nil {
+			return fmt.Errorf("seed dean office for faculty %s: %w", fac.Name, err)
+		}
+	}
+
+	// Режим работы мест и деканатов — из их текстового расписания, плюс общий праздник
+	if err := models.MigrateTextHours(db); err != nil {
+		return fmt.Errorf("seed opening hours: %w", err)
+	}
+	holiday := models.HoursException{Date: nextHoliday(time.Now()), Closed: true, Note: "День народного единства"}
+	if err := db.Where("date >= ? AND date < ? AND place_id IS NULL AND dean_office_id IS NULL",
+		holiday.Date.Format(time.DateOnly), holiday.Date.AddDate(0, 0, 1).Format(time.DateOnly)).
+		FirstOrCreate(&holiday).Error; err != nil {
+		return fmt.Errorf("seed holiday: %w", err)
+	}
+
+	// --- 6) Частые вопросы по темам
+	if err := loadSampleFAQ(db); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// sampleTeachersFor возвращает 3 преподавателя с разными ФИО и расписанием
+func sampleTeachersFor(depName string, depID uint, depIndex int, semID uint, campuses []models.Campus, groups []models.Group) []models.Teacher {
+	firstNames := []string{"Иван", "Пётр", "Анна", "Екатерина", "Сергей", "Мария", "Дмитрий", "Ольга", "Алексей", "Наталья"}
+	lastNames := []string{"Иванов", "Петров", "Сидорова", "Кузнецов", "Смирнова", "Попов", "Лебедев", "Козлова", "Новикова", "Морозов"}
+	middles := []string{"Иванович", "Петрович", "Сергеевна", "Андреевна", "Алексеевич", "Владимировна"}
+
+	// helper для кругового выбора из массивов
+	pick := func(arr []string, i int) string { return arr[i%len(arr)] }
+
+	// слот расписания: день, начало «чч:мм» (пара 1ч40м), чётность, тип
+	type slot struct {
+		weekday int
+		start   string
+		parity  string
+		kind    string
+	}
+
+	makeT := func(i int, subj, room string, slots ...slot) models.Teacher {
+		fn := pick(firstNames, depIndex+i)
+		ln := pick(lastNames, depIndex*2+i)
+		mn := pick(middles, depIndex+i/2)
+		full := fmt.Sprintf("%s %s %s", ln, fn, mn)
+
+		var campusID *uint
+		if len(campuses) > 0 {
+			id := campuses[(depIndex+i)%len(campuses)].ID
+			campusID = &id
+		}
+		sid := semID
+
+		lessons := make([]models.Lesson, 0, len(slots))
+		for _, sl := range slots {
+			var h, m int
+			fmt.Sscanf(sl.start, "%d:%d", &h, &m)
+			lessons = append(lessons, models.Lesson{
+				SemesterID:  &sid,
+				Weekday:     sl.weekday,
+				StartMinute: h*60 + m,
+				EndMinute:   h*60 + m + 100,
+				WeekParity:  sl.parity,
+				Room:        room,
+				CampusID:    campusID,
+				Subject:     subj,
+				LessonType:  sl.kind,
+				Groups:      groups,
+			})
+		}
+
+		return models.Teacher{
+			FullName:     full,
+			Email:        fmt.Sprintf("%s_%s@example.edu", translit(depName), strings.ToLower(ln)),
+			Subject:      subj,
+			DepartmentID: depID,
+			OfficeRoom:   room,
+			Phone:        fmt.Sprintf("+7 (495) 000-%02d-%02d", depIndex+1, i+1),
+			OfficeHours:  pick([]string{"Пн 17:00–18:00", "Ср 15:30–16:30", "Чт 12:00–13:00"}, depIndex+i),
+			Lessons:      lessons,
+		}
+	}
+
+	return []models.Teacher{
+		makeT(0, "Алгоритмы и структуры данных", "А-101",
+			slot{1, "10:15", models.ParityAny, models.LessonLecture}, slot{3, "12:00", models.ParityOdd, models.LessonSeminar}),
+		makeT(1, "Базы данных", "Б-203",
+			slot{2, "14:00", models.ParityAny, models.LessonLecture}, slot{4, "10:15", models.ParityEven, models.LessonLab}),
+		makeT(2, "Операционные системы", "В-317",
+			slot{5, "8:30", models.ParityAny, models.LessonLecture}, slot{3, "15:40", models.ParityEven, models.LessonSeminar}),
+	}
+}
+
+// loadSampleMenus - меню столовых и буфетов на сегодня и завтра (у «Буфета №2» меню нет —
+// видно, как бот отвечает без меню). Уже опубликованные меню не трогаем.
+func loadSampleMenus(db *gorm.DB, placeIDs map[string]uint, now time.Time) error {
+	soup, hot, salad, drink := "Супы", "Горячее", "Салаты", "Напитки"
+	menus := []struct {
+		place  string
+		day    int // 0 — сегодня, 1 — завтра
+		dishes []models.Dish
+	}{
+		{place: "Столовая №1", day: 0, dishes: []models.Dish{
+			{Category: soup, Name: "Борщ со сметаной", PriceKop: 9500, Weight: "250 г", Allergens: "молоко"},
+			{Category: soup, Name: "Куриный суп с лапшой", PriceKop: 8500, Weight: "250 г", Allergens: "глютен"},
+			{Category: hot, Name: "Пюре с котлетой", PriceKop: 14000, Weight: "150/100 г", Allergens: "молоко, глютен"},
+			{Category: hot, Name: "Плов", PriceKop: 12000, Weight: "250 г"},
+			{Category: salad, Name: "Салат «Цезарь»", PriceKop: 11050, Weight: "150 г", Allergens: "яйца, молоко, глютен"},
+			{Category: drink, Name: "Компот", PriceKop: 3500, Weight: "200 мл"},
+		}},
+		{place: "Столовая №1", day: 1, dishes: []models.Dish{
+			{Category: soup, Name: "Щи из свежей капусты", PriceKop: 8500, Weight: "250 г"},
+			{Category: hot, Name: "Гречка с курицей", PriceKop: 13000, Weight: "150/100 г"},
+			{Category: hot, Name: "Макароны по-флотски", PriceKop: 11500, Weight: "250 г", Allergens: "глютен"},
+			{Category: drink, Name: "Морс клюквенный", PriceKop: 4000, Weight: "200 мл"},
+		}},
+		{place: "Буфет №1", day: 0, dishes: []models.Dish{
+			{Name: "Круассан", PriceKop: 5500, Allergens: "глютен, молоко"},
+			{Name: "Сэндвич с курицей", PriceKop: 7900, Weight: "180 г", Allergens: "глютен"},
+			{Name: "Кофе американо", PriceKop: 6000, Weight: "200 мл"},
+			{Name: "Чай", PriceKop: 3000, Weight: "200 мл"},
+			{Name: "Шоколадный батончик", PriceKop: 4500, Allergens: "орехи, молоко"},
+		}},
+		{place: "Столовая №2", day: 0, dishes: []models.Dish{
+			{Category: soup, Name: "Солянка", PriceKop: 9900, Weight: "250 г"},
+			{Category: hot, Name: "Рис с тефтелями", PriceKop: 12500, Weight: "150/100 г", Allergens: "глютен"},
+			{Category: drink, Name: "Чай с лимоном", PriceKop: 2500, Weight: "200 мл"},
+		}},
+	}
+	for _, m := range menus {
+		placeID, ok := placeIDs[m.place]
+		if !ok {
+			return fmt.Errorf("seed menu: unknown place %q", m.place)
+		}
+		day := now.AddDate(0, 0, m.day)
+		_, exists, err := models.FindMenu(db, placeID, day)
+		if err != nil {
+			return fmt.Errorf("seed menu %s: %w", m.place, err)
+		}
+		if exists {
+			continue
+		}
+		if _, err := models.ReplaceMenu(db, placeID, day, m.dishes); err != nil {
+			return fmt.Errorf("seed menu %s: %w", m.place, err)
+		}
+	}
+	return nil
+}
+
+// loadSampleFAQ - темы FAQ и вопросы к ним; существующие вопросы (по тексту) не трогаем
+func loadSampleFAQ(db *gorm.DB) error {
+	topics := []struct {
+		name string
+		faqs [][2]string // вопрос, ответ
+	}{
+		{"Академические вопросы", [][2]string{
+			{"Как записаться на пересдачу?", "Через заявление в деканат вашего факультета. Бланк есть в разделе «Документы» личного кабинета, даты пересдач публикуются на стенде деканата."},
+			{"Где найти учебный план?", "В разделе «Документы» вашего личного кабинета. Там же — рабочие программы дисциплин."},
+			{"Как перевестись в другую группу?", "Напишите заявление на имя декана и согласуйте его с куратором обеих групп."},
+			{"Как оформить академический отпуск?", "Заявление и подтверждающие документы (например, медицинскую справку) подают в деканат. Решение принимается в течение 10 рабочих дней."},
+			{"Что будет, если не сдать сессию вовремя?", "Образуется академическая задолженность. Её нужно ликвидировать в сроки, которые назначит деканат, — обычно в течение следующего семестра."},
+			{"Можно ли сдать экзамен досрочно?", "Да, по заявлению в деканат и с согласия преподавателя."},
+		}},
+		{"Документы и справки", [][2]string{
+			{"Как получить справку об обучении?", "Закажите её в личном кабинете или в деканате. Справка будет готова через 3 рабочих дня."},
+			{"Что делать, если потерял студенческий?", "Обратиться в отдел кадров, ауд. 100 ГУК, с заявлением и фотографией 3×4."},
+		}},
+		{"Общежитие", [][2]string{
+			{"Как заселиться в общежитие?", "Подайте заявление через личный кабинет до 1 августа. После приказа о заселении придите к коменданту с паспортом и медицинской справкой."},
+		}},
+		{"Стипендия", [][2]string{
+			{"Когда приходит стипендия?", "Стипендия перечисляется на карту до 25 числа каждого месяца."},
+			{"Как получить повышенную стипендию?", "Подайте заявление и портфолио достижений в деканат до 15 сентября или до 15 февраля."},
+		}},
+		{"Другое", nil},
+	}
+	for i, t := range topics {
+		cat := models.FAQCategory{Name: t.name, Position: i + 1}
+		if err := db.Where("name = ?", cat.Name).FirstOrCreate(&cat).Error; err != nil {
+			return fmt.Errorf("seed faq category %s: %w", t.name, err)
+		}
+		for _, qa := range t.faqs {
+			faq := models.FAQ{CategoryID: &cat.ID, Question: qa[0], Answer: qa[1]}
+			if err := db.Where("question = ?", faq.Question).FirstOrCreate(&faq).Error; err != nil {
+				return fmt.Errorf("seed faq %q: %w", qa[0], err)
+			}
+		}
+	}
+	return nil
+}
+
+func ptr[T any](v T) *T { return &v }
+
+// nextHoliday - ближайшее 4 ноября не раньше now
+func nextHoliday(now time.Time) time.Time {
+	d := time.Date(now.Year(), time.November, 4, 0, 0, 0, 0, time.UTC)
+	if d.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)) {
+		d = d.AddDate(1, 0, 0)
+	}
+	return d
+}
+
+// sampleSemester - осенний или весенний семестр, в который попадает now
+func sampleSemester(now time.Time) models.Semester {
+	y := now.Year()
+	if now.Month() >= time.February && now.Month() < time.August {
+		return models.Semester{
+			Name:      fmt.Sprintf("Весна %d", y),
+			StartDate: time.Date(y, time.February, 9, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(y, time.June, 30, 0, 0, 0, 0, time.UTC),
+		}
+	}
+	if now.Month() == time.January {
+		y--
+	}
+	return models.Semester{
+		Name:      fmt.Sprintf("Осень %d", y),
+		StartDate: time.Date(y, time.September, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(y+1, time.January, 31, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+// translit — упрощённая замена для email-части
+func translit(dep string) string {
+	repl := map[rune]string{
+		'И': "iu", 'и': "iu",
+		'У': "u", 'у': "u",
+		' ': "", '-': "-",
+		'0': "0", '1': "1", '2': "2", '3': "3", '4': "4",
+		'5': "5", '6': "6", '7': "7", '8': "8", '9': "9",
+	}
+	out := make([]rune, 0, len(dep))
+	for _, r := range dep {
+		if v, ok := repl[r]; ok {
+			for _, rr := range v {
+				out = append(out, rr)
+			}
+		} else {
+			out = append(out, r)
+		}
+	}
+	return string(out)
+}
+
+// randomSecretary возвращает демонстрационного ответственного секретаря
+func randomSecretary(fac string) string {
+	switch fac {
+	case "ИУ":
+		return "Иванова Елена Сергеевна"
+	case "Э":
+		return "Петров Алексей Владимирович"
+	case "РК":
+		return "Сидорова Наталья Ивановна"
+	default:
+		return "Неизвестен"
+	}
+}
@@ -0,0 +1,219 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Karielka/Hackaton_MAX/internal/text"
+)
+
+// Чтение iCalendar (RFC 5545) — только то, что нужно расписанию: VEVENT с
+// DTSTART/DTEND, SUMMARY, LOCATION, DESCRIPTION, CATEGORIES, ORGANIZER и RRULE.
+//
+// Где брать поля занятия:
+//   - предмет — SUMMARY; тип — CATEGORIES или суффикс «(лекция)» в SUMMARY;
+//   - преподаватель — ORGANIZER;CN=…, X-TEACHER или строка «Преподаватель: …» в DESCRIPTION;
+//   - группы — X-GROUPS или строка «Группы: …» в DESCRIPTION (через запятую);
+//   - аудитория и корпус — LOCATION «А-101, ГУК» (корпус — последняя часть).
+
+// icalProp - свойство: имя, параметры и значение
+type icalProp struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// icalEvent - VEVENT; Line — строка BEGIN:VEVENT (для отчёта)
+type icalEvent struct {
+	Line  int
+	Props map[string]icalProp
+}
+
+func (e icalEvent) get(name string) string { return e.Props[name].Value }
+
+// readICS - события календаря
+func readICS(data []byte) ([]icalEvent, error) {
+	lines, err := icalUnfold(data)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		events []icalEvent
+		cur    *icalEvent
+		seen   bool
+	)
+	for _, l := range lines {
+		p, err := icalParseLine(l.text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", l.num, err)
+		}
+		switch {
+		case p.Name == "BEGIN" && strings.EqualFold(p.Value, "VCALENDAR"):
+			seen = true
+		case p.Name == "BEGIN" && strings.EqualFold(p.Value, "VEVENT"):
+			cur = &icalEvent{Line: l.num, Props: map[string]icalProp{}}
+		case p.Name == "END" && strings.EqualFold(p.Value, "VEVENT"):
+			if cur != nil {
+				events = append(events, *cur)
+			}
+			cur = nil
+		case cur != nil:
+			if _, dup := cur.Props[p.Name]; !dup {
+				cur.Props[p.Name] = p
+			}
+		}
+	}
+	if !seen {
+		return nil, fmt.Errorf("not an iCalendar file: BEGIN:VCALENDAR is missing")
+	}
+	return events, nil
+}
+
+type icalLine struct {
+	num  int
+	text string
+}
+
+// icalUnfold склеивает перенесённые строки (продолжение начинается с пробела или табуляции)
+func icalUnfold(data []byte) ([]icalLine, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 64<<10), 1<<20)
+	var out []icalLine
+	num := 0
+	for sc.Scan() {
+		num++
+		t := strings.TrimRight(sc.Text(), "\r")
+		if (strings.HasPrefix(t, " ") || strings.HasPrefix(t, "\t")) && len(out) > 0 {
+			out[len(out)-1].text += t[1:]
+			continue
+		}
+		if t != "" {
+			out = append(out, icalLine{num: num, text: t})
+		}
+	}
+	return out, sc.Err()
+}
+
+// icalParseLine - «NAME;PARAM=a;PARAM2="b:c":value»
+func icalParseLine(s string) (icalProp, error) {
+	inQuote := false
+	colon := -1
+	for i, ch := range s {
+		if ch == '"' {
+			inQuote = !inQuote
+		}
+		if ch == ':' && !inQuote {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return icalProp{}, fmt.Errorf("bad content line %q", s)
+	}
+	head, value := s[:colon], s[colon+1:]
+	parts := strings.Split(head, ";")
+	p := icalProp{Name: strings.ToUpper(parts[0]), Params: map[string]string{}, Value: value}
+	for _, param := range parts[1:] {
+		k, v, _ := strings.Cut(param, "=")
+		p.Params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+	return p, nil
+}
+
+// icalText снимает экранирование TEXT: \n, \, \; \\
+func icalText(s string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
+
+// icalTime - момент DTSTART/DTEND в часовом поясе loc.
+// dateOnly — событие на весь день (VALUE=DATE), для расписания не годится.
+func icalTime(p icalProp, loc *time.Location) (t time.Time, dateOnly bool, err error) {
+	v := p.Value
+	if p.Params["VALUE"] == "DATE" || len(v) == len("20060102") {
+		t, err = time.ParseInLocation("20060102", v, loc)
+		return t, true, err
+	}
+	src := loc
+	if tzid := p.Params["TZID"]; tzid != "" {
+		if l, lerr := time.LoadLocation(tzid); lerr == nil {
+			src = l
+		}
+	}
+	if strings.HasSuffix(v, "Z") {
+		t, err = time.Parse("20060102T150405Z", v)
+	} else {
+		t, err = time.ParseInLocation("20060102T150405", v, src)
+	}
+	return t.In(loc), false, err
+}
+
+// icalRRule - правило повторения: шаг в неделях, дни недели (BYDAY) и UNTIL
+type icalRRule struct {
+	Interval int
+	Weekdays []int     // 1 — Пн … 7 — Вс
+	Until    time.Time // нулевое — без UNTIL
+}
+
+var icalDays = map[string]int{"MO": 1, "TU": 2, "WE": 3, "TH": 4, "FR": 5, "SA": 6, "SU": 7}
+
+// parseRRule понимает только FREQ=WEEKLY (другие для пар не встречаются)
+func parseRRule(s string, loc *time.Location) (*icalRRule, error) {
+	r := &icalRRule{Interval: 1}
+	freq := ""
+	for _, part := range strings.Split(s, ";") {
+		k, v, _ := strings.Cut(part, "=")
+		switch strings.ToUpper(k) {
+		case "FREQ":
+			freq = strings.ToUpper(v)
+		case "INTERVAL":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("bad RRULE INTERVAL %q", v)
+			}
+			r.Interval = n
+		case "BYDAY":
+			for _, d := range strings.Split(v, ",") {
+				wd, ok := icalDays[strings.ToUpper(strings.TrimLeft(d, "+-0123456789"))]
+				if !ok {
+					return nil, fmt.Errorf("bad RRULE BYDAY %q", d)
+				}
+				r.Weekdays = append(r.Weekdays, wd)
+			}
+		case "UNTIL":
+			t, _, err := icalTime(icalProp{Value: v}, loc)
+			if err != nil {
+				return nil, fmt.Errorf("bad RRULE UNTIL %q", v)
+			}
+			r.Until = t
+		}
+	}
+	if freq != "WEEKLY" {
+		return nil, fmt.Errorf("unsupported RRULE FREQ=%s (only WEEKLY)", freq)
+	}
+	if r.Interval > 2 {
+		return nil, fmt.Errorf("unsupported RRULE INTERVAL=%d (1 or 2)", r.Interval)
+	}
+	return r, nil
+}
+
+// icalLabeled - значение строки «Метка: значение» из DESCRIPTION
+func icalLabeled(desc string, labels ...string) string {
+	for _, line := range strings.Split(desc, "\n") {
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		k = text.Fold(k)
+		for _, l := range labels {
+			if k == l {
+				return strings.TrimSpace(v)
+			}
+		}
+	}
+	return ""
+}
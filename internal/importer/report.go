@@ -0,0 +1,121 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Report - итог импорта; при DryRun изменения посчитаны, но не сохранены
+type Report struct {
+	DryRun    bool           `json:"dry_run"`
+	Rows      int            `json:"rows"`      // строк с данными
+	Unchanged int            `json:"unchanged"` // строк, которые ничего не поменяли
+	Created   map[string]int `json:"created"`   // сущность -> сколько создано
+	Updated   map[string]int `json:"updated"`
+	Deleted   map[string]int `json:"deleted,omitempty"`
+	Changes   []Change       `json:"changes"`
+	Errors    []RowError     `json:"errors"`
+	Warnings  []string       `json:"warnings,omitempty"`
+}
+
+// Change - одна созданная или изменённая запись
+type Change struct {
+	Line   int           `json:"line"`
+	Action string        `json:"action"` // ActionCreate | ActionUpdate | ActionDelete
+	Entity string        `json:"entity"`
+	Name   string        `json:"name"`
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+// FieldChange - поле до и после
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// RowError - строка пропущена целиком
+type RowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+func newReport(dryRun bool) *Report {
+	return &Report{
+		DryRun:  dryRun,
+		Created: map[string]int{}, Updated: map[string]int{}, Deleted: map[string]int{},
+		Changes: []Change{}, Errors: []RowError{},
+	}
+}
+
+func (r *Report) add(c Change) {
+	r.Changes = append(r.Changes, c)
+	switch c.Action {
+	case ActionCreate:
+		r.Created[c.Entity]++
+	case ActionUpdate:
+		r.Updated[c.Entity]++
+	case ActionDelete:
+		r.Deleted[c.Entity]++
+	}
+}
+
+func (r *Report) fail(line int, format string, args ...any) {
+	r.Errors = append(r.Errors, RowError{Line: line, Error: fmt.Sprintf(format, args...)})
+}
+
+// WriteText - отчёт для терминала: diff построчно, затем ошибки и итог
+func (r *Report) WriteText(w io.Writer) {
+	for _, c := range r.Changes {
+		mark := map[string]string{ActionCreate: "+", ActionUpdate: "~", ActionDelete: "-"}[c.Action]
+		if c.Line > 0 {
+			fmt.Fprintf(w, "%s line %d: %s %q\n", mark, c.Line, c.Entity, c.Name)
+		} else {
+			fmt.Fprintf(w, "%s %s %q\n", mark, c.Entity, c.Name)
+		}
+		for _, f := range c.Fields {
+			fmt.Fprintf(w, "    %s: %q -> %q\n", f.Field, f.Old, f.New)
+		}
+	}
+	for _, msg := range r.Warnings {
+		fmt.Fprintf(w, "warning: %s\n", msg)
+	}
+	for _, e := range r.Errors {
+		fmt.Fprintf(w, "! line %d: %s\n", e.Line, e.Error)
+	}
+
+	mode := "applied"
+	if r.DryRun {
+		mode = "dry run, nothing saved"
+	}
+	deleted := ""
+	if len(r.Deleted) > 0 {
+		deleted = "; deleted " + countsText(r.Deleted)
+	}
+	fmt.Fprintf(w, "\n%d rows (%s): created %s; updated %s%s; unchanged %d; errors %d\n",
+		r.Rows, mode, countsText(r.Created), countsText(r.Updated), deleted, r.Unchanged, len(r.Errors))
+}
+
+func countsText(m map[string]int) string {
+	if len(m) == 0 {
+		return "nothing"
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s %d", k, m[k])
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,400 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/Karielka/Hackaton_MAX/internal/text"
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// Импорт штатного расписания: институт → факультет → кафедра → преподаватель.
+//
+// Записи ищутся по естественным ключам (названия без учёта регистра и «ё»,
+// преподаватель — по почте, иначе по ФИО в пределах кафедры), поэтому
+// повторный запуск того же файла ничего не меняет. Пустая ячейка не стирает
+// существующее значение. Строка с ошибкой пропускается, остальные применяются.
+
+// Сущности в отчёте
+const (
+	EntityInstitute  = "institute"
+	EntityFaculty    = "faculty"
+	EntityDepartment = "department"
+	EntityTeacher    = "teacher"
+)
+
+// staffColumns - заголовок колонки (normKey) -> поле строки
+var staffColumns = map[string]string{
+	"institute": "institute", "институт": "institute",
+	"faculty": "faculty", "факультет": "faculty",
+	"department": "department", "кафедра": "department",
+	"department_office": "department_office", "кабинет кафедры": "department_office",
+	"full_name": "full_name", "фио": "full_name", "преподаватель": "full_name",
+	"email": "email", "e-mail": "email", "почта": "email",
+	"subject": "subject", "предмет": "subject", "дисциплина": "subject",
+	"office_room": "office_room", "кабинет": "office_room",
+	"phone": "phone", "телефон": "phone",
+	"office_hours": "office_hours", "консультации": "office_hours",
+}
+
+var staffRequired = []string{"institute", "faculty", "department", "full_name"}
+
+// StaffOptions - параметры импорта
+type StaffOptions struct {
+	DryRun bool // посчитать diff и откатить транзакцию
+}
+
+// errDryRun откатывает транзакцию пробного запуска
+var errDryRun = errors.New("dry run")
+
+// ImportStaff читает таблицу сотрудников и применяет её к базе одной транзакцией.
+// Ошибка возвращается, только если файл целиком непригоден или упала база;
+// проблемы отдельных строк — в Report.Errors.
+func ImportStaff(ctx context.Context, db *gorm.DB, data []byte, format string, opt StaffOptions) (*Report, error) {
+	table, err := readTable(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadFile, err)
+	}
+	rep := newReport(opt.DryRun)
+	cols, err := staffHeader(table[0], rep)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadFile, err)
+	}
+
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		idx, err := loadStaffIndex(tx)
+		if err != nil {
+			return err
+		}
+		for i, cells := range table[1:] {
+			line := i + 2 // строка 1 — заголовок
+			row := staffRow(cols, cells)
+			if row == nil {
+				continue // пустая строка
+			}
+			rep.Rows++
+			if msg := row.validate(); msg != "" {
+				rep.fail(line, "%s", msg)
+				continue
+			}
+
+			sp := fmt.Sprintf("staff_row_%d", line)
+			if err := tx.SavePoint(sp).Error; err != nil {
+				return err
+			}
+			changes, err := idx.apply(tx, line, row)
+			if err != nil {
+				if rbErr := tx.RollbackTo(sp).Error; rbErr != nil {
+					return rbErr
+				}
+				rep.fail(line, "%v", err)
+				// индекс мог запомнить откаченные записи — перечитываем
+				if idx, err = loadStaffIndex(tx); err != nil {
+					return err
+				}
+				continue
+			}
+			if len(changes) == 0 {
+				rep.Unchanged++
+			}
+			for _, c := range changes {
+				rep.add(c)
+			}
+		}
+		if opt.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, fmt.Errorf("import staff: %w", err)
+	}
+	return rep, nil
+}
+
+// staffHeader - номер колонки для каждого известного поля
+func staffHeader(header []string, rep *Report) (map[string]int, error) {
+	cols := map[string]int{}
+	for i, h := range header {
+		key := text.Fold(h)
+		if key == "" {
+			continue
+		}
+		field, ok := staffColumns[key]
+		if !ok {
+			rep.Warnings = append(rep.Warnings, fmt.Sprintf("column %q is ignored", h))
+			continue
+		}
+		if _, dup := cols[field]; dup {
+			return nil, fmt.Errorf("column %q is duplicated", h)
+		}
+		cols[field] = i
+	}
+	var missing []string
+	for _, f := range staffRequired {
+		if _, ok := cols[f]; !ok {
+			missing = append(missing, f)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required columns: %s", strings.Join(missing, ", "))
+	}
+	return cols, nil
+}
+
+// staffRecord - одна строка таблицы: поле -> значение
+type staffRecord map[string]string
+
+// staffRow - значения строки; nil, если строка пустая
+func staffRow(cols map[string]int, cells []string) staffRecord {
+	row := staffRecord{}
+	empty := true
+	for field, i := range cols {
+		if i < len(cells) {
+			row[field] = clean(cells[i])
+		}
+		if row[field] != "" {
+			empty = false
+		}
+	}
+	if empty {
+		return nil
+	}
+	return row
+}
+
+func (r staffRecord) validate() string {
+	var missing []string
+	for _, f := range staffRequired {
+		if r[f] == "" {
+			missing = append(missing, f)
+		}
+	}
+	if len(missing) > 0 {
+		return "empty " + strings.Join(missing, ", ")
+	}
+	if e := r["email"]; e != "" {
+		if a, err := mail.ParseAddress(e); err != nil || a.Address != e {
+			return fmt.Sprintf("bad email %q", e)
+		}
+	}
+	return ""
+}
+
+// staffIndex - существующие записи по естественным ключам
+type staffIndex struct {
+	institutes  map[string]*models.Institute
+	faculties   map[string]*models.Faculty
+	departments map[string]*models.Department
+	byEmail     map[string]*models.Teacher // lower(email)
+	byName      map[string]*models.Teacher // text.Fold(ФИО) + "|" + department_id
+}
+
+func loadStaffIndex(tx *gorm.DB) (*staffIndex, error) {
+	idx := &staffIndex{
+		institutes:  map[string]*models.Institute{},
+		faculties:   map[string]*models.Faculty{},
+		departments: map[string]*models.Department{},
+		byEmail:     map[string]*models.Teacher{},
+		byName:      map[string]*models.Teacher{},
+	}
+	var insts []models.Institute
+	var facs []models.Faculty
+	var deps []models.Department
+	var teachers []models.Teacher
+	for _, q := range []struct {
+		dst  any
+		what string
+	}{{&insts, "institutes"}, {&facs, "faculties"}, {&deps, "departments"}, {&teachers, "teachers"}} {
+		if err := tx.Order("id").Find(q.dst).Error; err != nil {
+			return nil, fmt.Errorf("load %s: %w", q.what, err)
+		}
+	}
+	for i := range insts {
+		idx.institutes[text.Fold(insts[i].Name)] = &insts[i]
+	}
+	for i := range facs {
+		idx.faculties[text.Fold(facs[i].Name)] = &facs[i]
+	}
+	for i := range deps {
+		idx.departments[text.Fold(deps[i].Name)] = &deps[i]
+	}
+	for i := range teachers {
+		idx.addTeacher(&teachers[i])
+	}
+	return idx, nil
+}
+
+func (idx *staffIndex) addTeacher(t *models.Teacher) {
+	if t.Email != "" {
+		if _, ok := idx.byEmail[strings.ToLower(t.Email)]; !ok {
+			idx.byEmail[strings.ToLower(t.Email)] = t
+		}
+	}
+	key := teacherKey(t.FullName, t.DepartmentID)
+	if _, ok := idx.byName[key]; !ok {
+		idx.byName[key] = t
+	}
+}
+
+func teacherKey(name string, depID uint) string {
+	return fmt.Sprintf("%s|%d", text.Fold(name), depID)
+}
+
+// apply создаёт недостающие звенья цепочки и обновляет изменившиеся поля
+func (idx *staffIndex) apply(tx *gorm.DB, line int, r staffRecord) ([]Change, error) {
+	var changes []Change
+	track := func(c *Change) {
+		if c != nil {
+			c.Line = line
+			changes = append(changes, *c)
+		}
+	}
+
+	inst, c, err := idx.institute(tx, r["institute"])
+	if err != nil {
+		return nil, err
+	}
+	track(c)
+	fac, c, err := idx.faculty(tx, r["faculty"], inst.ID)
+	if err != nil {
+		return nil, err
+	}
+	track(c)
+	dep, c, err := idx.department(tx, r["department"], fac.ID, r["department_office"])
+	if err != nil {
+		return nil, err
+	}
+	track(c)
+	c, err = idx.teacher(tx, r, dep.ID)
+	if err != nil {
+		return nil, err
+	}
+	track(c)
+	return changes, nil
+}
+
+func (idx *staffIndex) institute(tx *gorm.DB, name string) (*models.Institute, *Change, error) {
+	if inst, ok := idx.institutes[text.Fold(name)]; ok {
+		return inst, nil, nil
+	}
+	inst := &models.Institute{Name: name}
+	if err := tx.Create(inst).Error; err != nil {
+		return nil, nil, fmt.Errorf("create institute %q: %w", name, err)
+	}
+	idx.institutes[text.Fold(name)] = inst
+	return inst, &Change{Action: ActionCreate, Entity: EntityInstitute, Name: name}, nil
+}
+
+func (idx *staffIndex) faculty(tx *gorm.DB, name string, instID uint) (*models.Faculty, *Change, error) {
+	fac, ok := idx.faculties[text.Fold(name)]
+	if !ok {
+		fac = &models.Faculty{Name: name, InstituteID: instID}
+		if err := tx.Omit("Institute").Create(fac).Error; err != nil {
+			return nil, nil, fmt.Errorf("create faculty %q: %w", name, err)
+		}
+		idx.faculties[text.Fold(name)] = fac
+		return fac, &Change{Action: ActionCreate, Entity: EntityFaculty, Name: name}, nil
+	}
+	d := newDiff()
+	d.ref("institute_id", &fac.InstituteID, instID)
+	c, err := d.save(tx, &models.Faculty{ID: fac.ID}, EntityFaculty, fac.Name)
+	return fac, c, err
+}
+
+func (idx *staffIndex) department(tx *gorm.DB, name string, facID uint, office string) (*models.Department, *Change, error) {
+	dep, ok := idx.departments[text.Fold(name)]
+	if !ok {
+		dep = &models.Department{Name: name, FacultyID: facID, Office: office}
+		if err := tx.Omit("Faculty").Create(dep).Error; err != nil {
+			return nil, nil, fmt.Errorf("create department %q: %w", name, err)
+		}
+		idx.departments[text.Fold(name)] = dep
+		return dep, &Change{Action: ActionCreate, Entity: EntityDepartment, Name: name}, nil
+	}
+	d := newDiff()
+	d.ref("faculty_id", &dep.FacultyID, facID)
+	d.str("office", &dep.Office, office)
+	c, err := d.save(tx, &models.Department{ID: dep.ID}, EntityDepartment, dep.Name)
+	return dep, c, err
+}
+
+// teacher - поиск по почте, затем по ФИО на кафедре
+func (idx *staffIndex) teacher(tx *gorm.DB, r staffRecord, depID uint) (*Change, error) {
+	var t *models.Teacher
+	if e := strings.ToLower(r["email"]); e != "" {
+		t = idx.byEmail[e]
+	}
+	if t == nil {
+		t = idx.byName[teacherKey(r["full_name"], depID)]
+	}
+
+	if t == nil {
+		t = &models.Teacher{
+			FullName: r["full_name"], DepartmentID: depID, Email: r["email"], Subject: r["subject"],
+			OfficeRoom: r["office_room"], Phone: r["phone"], OfficeHours: r["office_hours"],
+		}
+		if err := tx.Omit("Department", "Lessons").Create(t).Error; err != nil {
+			return nil, fmt.Errorf("create teacher %q: %w", t.FullName, err)
+		}
+		idx.addTeacher(t)
+		return &Change{Action: ActionCreate, Entity: EntityTeacher, Name: t.FullName}, nil
+	}
+
+	d := newDiff()
+	d.str("full_name", &t.FullName, r["full_name"])
+	d.ref("department_id", &t.DepartmentID, depID)
+	d.str("email", &t.Email, r["email"])
+	d.str("subject", &t.Subject, r["subject"])
+	d.str("office_room", &t.OfficeRoom, r["office_room"])
+	d.str("phone", &t.Phone, r["phone"])
+	d.str("office_hours", &t.OfficeHours, r["office_hours"])
+	c, err := d.save(tx, &models.Teacher{ID: t.ID}, EntityTeacher, t.FullName)
+	if err == nil && c != nil {
+		idx.addTeacher(t) // новые почта/кафедра тоже должны находиться
+	}
+	return c, err
+}
+
+// diff копит изменения полей записи и сразу применяет их к кэшу
+type diff struct {
+	fields []FieldChange
+	cols   map[string]any
+}
+
+func newDiff() *diff { return &diff{cols: map[string]any{}} }
+
+// str - пустое значение из файла не стирает текущее
+func (d *diff) str(col string, cur *string, val string) {
+	if val == "" || val == *cur {
+		return
+	}
+	d.fields = append(d.fields, FieldChange{Field: col, Old: *cur, New: val})
+	d.cols[col] = val
+	*cur = val
+}
+
+func (d *diff) ref(col string, cur *uint, val uint) {
+	if val == *cur {
+		return
+	}
+	d.fields = append(d.fields, FieldChange{Field: col, Old: fmt.Sprint(*cur), New: fmt.Sprint(val)})
+	d.cols[col] = val
+	*cur = val
+}
+
+// save - UPDATE изменившихся колонок; nil, если менять нечего
+func (d *diff) save(tx *gorm.DB, model any, entity, name string) (*Change, error) {
+	if len(d.cols) == 0 {
+		return nil, nil
+	}
+	if err := tx.Model(model).Updates(d.cols).Error; err != nil {
+		return nil, fmt.Errorf("update %s %q: %w", entity, name, err)
+	}
+	return &Change{Action: ActionUpdate, Entity: entity, Name: name, Fields: d.fields}, nil
+}
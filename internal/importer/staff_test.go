@@ -0,0 +1,212 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// staffFile - таблица через «;», как её сохраняет русский Excel; dep — кафедра
+// преподавателя «Тестов Пётр Ильич» из openDB
+func staffFile(dep models.Department) string {
+	return strings.Join([]string{
+		"Институт;Факультет;Кафедра;Кабинет кафедры;ФИО;Почта;Дисциплина;Кабинет;Телефон;Комментарий",
+		"Институт тестирования;ТСТ;Кафедра тестов;Т-100;Новиков Семён Ильич;novikov@example.com;Тестирование;;+7 495 000-00-01;новый",
+		fmt.Sprintf("%s;%s;%s;;Тестов Пётр Ильич;;;А-1;;обновить кабинет", dep.Faculty.Institute.Name, dep.Faculty.Name, dep.Name),
+		"Институт тестирования;ТСТ;Кафедра тестов;;;;;;;нет ФИО",
+		"Институт тестирования;ТСТ;Кафедра тестов;;Сидоров С. С.;not an email;;;;плохая почта",
+		";;;;;;;;;",
+		"Институт сбоев;СБОЙ;Кафедра сбоев;;Сбойный Сбой Сбоевич;;;;;упадёт на вставке",
+		"ИНСТИТУТ ТЕСТИРОВАНИЯ;тст;кафедра тестов;;Петрова Анна Сергеевна;;;;;после отката",
+	}, "\n") + "\n"
+}
+
+// failTeacher - вставка преподавателя «Сбойный …» падает, когда институт, факультет
+// и кафедра этой строки уже созданы: их должен откатить savepoint строки
+func failTeacher(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	err := db.Exec(`CREATE TRIGGER fail_teacher BEFORE INSERT ON teachers
+		WHEN NEW.full_name LIKE 'Сбойный%'
+		BEGIN SELECT RAISE(ABORT, 'teacher rejected'); END`).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func loadTestov(t *testing.T, db *gorm.DB) models.Teacher {
+	t.Helper()
+	var tt models.Teacher
+	if err := db.Preload("Department.Faculty.Institute").Where("full_name = ?", "Тестов Пётр Ильич").First(&tt).Error; err != nil {
+		t.Fatal(err)
+	}
+	return tt
+}
+
+func count(t *testing.T, db *gorm.DB, model any, col, val string) int64 {
+	t.Helper()
+	var n int64
+	if err := db.Model(model).Where(col+" = ?", val).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestImportStaff(t *testing.T) {
+	db := openDB(t)
+	failTeacher(t, db)
+	ctx := context.Background()
+	testov := loadTestov(t, db)
+	data := []byte(staffFile(testov.Department))
+
+	wantErr := map[int]string{4: "empty full_name", 5: `bad email "not an email"`, 7: "teacher rejected"}
+	checkErrors := func(name string, rep *Report) {
+		t.Helper()
+		if len(rep.Errors) != len(wantErr) {
+			t.Errorf("%s: errors %+v", name, rep.Errors)
+		}
+		for _, e := range rep.Errors {
+			if !strings.Contains(e.Error, wantErr[e.Line]) {
+				t.Errorf("%s: line %d: %q, want %q", name, e.Line, e.Error, wantErr[e.Line])
+			}
+		}
+	}
+	wantCreated := map[string]int{EntityInstitute: 1, EntityFaculty: 1, EntityDepartment: 1, EntityTeacher: 2}
+	wantUpdated := map[string]int{EntityTeacher: 1}
+
+	// пробный запуск: полный diff, в базе ничего
+	rep, err := ImportStaff(ctx, db, data, FormatCSV, StaffOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rep.DryRun || rep.Rows != 6 || !reflect.DeepEqual(rep.Created, wantCreated) || !reflect.DeepEqual(rep.Updated, wantUpdated) {
+		t.Errorf("dry run: rows %d, created %v, updated %v", rep.Rows, rep.Created, rep.Updated)
+	}
+	checkErrors("dry run", rep)
+	if len(rep.Warnings) != 1 || !strings.Contains(rep.Warnings[0], "Комментарий") {
+		t.Errorf("warnings: %q", rep.Warnings)
+	}
+	var out strings.Builder
+	rep.WriteText(&out)
+	for _, want := range []string{
+		`+ line 2: institute "Институт тестирования"`,
+		`+ line 2: teacher "Новиков Семён Ильич"`,
+		`~ line 3: teacher "Тестов Пётр Ильич"`,
+		`    office_room: "" -> "А-1"`,
+		`+ line 8: teacher "Петрова Анна Сергеевна"`,
+		"! line 7: ",
+		"dry run, nothing saved",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("dry run text has no %q:\n%s", want, out.String())
+		}
+	}
+	if n := count(t, db, &models.Institute{}, "name", "Институт тестирования"); n != 0 {
+		t.Fatalf("dry run saved the institute")
+	}
+	if got := loadTestov(t, db); got.OfficeRoom != "" {
+		t.Fatalf("dry run updated the teacher: %q", got.OfficeRoom)
+	}
+
+	// настоящий запуск
+	rep, err = ImportStaff(ctx, db, data, FormatCSV, StaffOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.DryRun || !reflect.DeepEqual(rep.Created, wantCreated) || !reflect.DeepEqual(rep.Updated, wantUpdated) {
+		t.Errorf("import: created %v, updated %v", rep.Created, rep.Updated)
+	}
+	checkErrors("import", rep)
+
+	var dep models.Department
+	if err := db.Preload("Faculty.Institute").Where("name = ?", "Кафедра тестов").First(&dep).Error; err != nil {
+		t.Fatal(err)
+	}
+	if dep.Office != "Т-100" || dep.Faculty.Name != "ТСТ" || dep.Faculty.Institute.Name != "Институт тестирования" {
+		t.Errorf("department: %+v", dep)
+	}
+	var created []models.Teacher
+	if err := db.Where("department_id = ?", dep.ID).Order("full_name").Find(&created).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 2 || created[0].FullName != "Новиков Семён Ильич" || created[1].FullName != "Петрова Анна Сергеевна" {
+		t.Fatalf("teachers of the new department: %+v", created)
+	}
+	if n := created[0]; n.Email != "novikov@example.com" || n.Subject != "Тестирование" || n.Phone != "+7 495 000-00-01" || n.OfficeRoom != "" {
+		t.Errorf("created teacher: %+v", n)
+	}
+	if got := loadTestov(t, db); got.OfficeRoom != "А-1" || got.FullName != "Тестов Пётр Ильич" || got.DepartmentID != testov.DepartmentID {
+		t.Errorf("updated teacher: %+v", got)
+	}
+	// упавшая строка откатилась целиком, остальные на месте
+	for _, tc := range []struct {
+		model    any
+		col, val string
+	}{
+		{&models.Institute{}, "name", "Институт сбоев"},
+		{&models.Faculty{}, "name", "СБОЙ"},
+		{&models.Department{}, "name", "Кафедра сбоев"},
+		{&models.Teacher{}, "full_name", "Сидоров С. С."},
+	} {
+		if n := count(t, db, tc.model, tc.col, tc.val); n != 0 {
+			t.Errorf("%q saved by a failed row", tc.val)
+		}
+	}
+
+	// повторный запуск того же файла ничего не меняет
+	rep, err = ImportStaff(ctx, db, data, FormatCSV, StaffOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Changes) != 0 || rep.Unchanged != 3 {
+		t.Errorf("re-run: unchanged %d, changes %+v", rep.Unchanged, rep.Changes)
+	}
+	checkErrors("re-run", rep)
+}
+
+func TestImportStaffKeepsExisting(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	testov := loadTestov(t, db)
+	err := db.Model(&models.Teacher{ID: testov.ID}).
+		Updates(map[string]any{"email": "testov@example.com", "office_room": "А-1", "phone": "101"}).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&models.Department{ID: testov.DepartmentID}).Update("office", "К-5").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// пустые ячейки не стирают значения; почта находит преподавателя и под другим ФИО
+	dep := testov.Department
+	file := "institute,faculty,department,department_office,full_name,email,office_room,phone,office_hours\n" +
+		fmt.Sprintf("%s,%s,%s,,Тестов П. И.,testov@example.com,,,Чт 15:00\n", dep.Faculty.Institute.Name, dep.Faculty.Name, dep.Name)
+	rep, err := ImportStaff(ctx, db, []byte(file), FormatCSV, StaffOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Errors) != 0 || len(rep.Changes) != 1 {
+		t.Fatalf("report: %+v", rep)
+	}
+	fields := map[string]FieldChange{}
+	for _, f := range rep.Changes[0].Fields {
+		fields[f.Field] = f
+	}
+	if len(fields) != 2 || fields["full_name"].New != "Тестов П. И." || fields["office_hours"].New != "Чт 15:00" {
+		t.Errorf("changed fields: %+v", rep.Changes[0].Fields)
+	}
+
+	var tt models.Teacher
+	if err := db.Preload("Department").First(&tt, testov.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if tt.FullName != "Тестов П. И." || tt.OfficeRoom != "А-1" || tt.Phone != "101" || tt.OfficeHours != "Чт 15:00" || tt.Department.Office != "К-5" {
+		t.Errorf("teacher after import: room %q, phone %q, hours %q, department office %q",
+			tt.OfficeRoom, tt.Phone, tt.OfficeHours, tt.Department.Office)
+	}
+}
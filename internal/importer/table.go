@@ -0,0 +1,83 @@
+// Package importer загружает данные университета из таблиц (CSV, XLSX):
+// оргструктуру и преподавателей.
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Форматы таблиц
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+	FormatICS  = "ics"  // iCalendar (RFC 5545)
+	FormatJSON = "json" // JSON-выгрузка расписания, см. timetableJSON
+)
+
+// ErrBadFile - файл нельзя разобрать целиком (формат, заголовок); база не тронута
+var ErrBadFile = errors.New("bad file")
+
+// FormatByName - формат по расширению файла ("" — неизвестен)
+func FormatByName(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv", ".txt":
+		return FormatCSV
+	case ".xlsx":
+		return FormatXLSX
+	case ".ics", ".ical":
+		return FormatICS
+	case ".json":
+		return FormatJSON
+	}
+	return ""
+}
+
+// readTable - строки таблицы; первая строка — заголовок
+func readTable(data []byte, format string) ([][]string, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(data)
+	case FormatXLSX:
+		return readXLSX(data)
+	}
+	return nil, fmt.Errorf("unsupported format %q (want csv or xlsx)", format)
+}
+
+// readCSV понимает и «,», и «;» (русский Excel сохраняет CSV через точку с запятой)
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // BOM
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = sniffComma(data)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read csv: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("file is empty")
+	}
+	return rows, nil
+}
+
+// sniffComma - разделитель по первой строке
+func sniffComma(data []byte) rune {
+	line, _, _ := bytes.Cut(data, []byte("\n"))
+	best, bestN := ',', 0
+	for _, c := range []rune{',', ';', '\t'} {
+		if n := bytes.Count(line, []byte(string(c))); n > bestN {
+			best, bestN = c, n
+		}
+	}
+	return best
+}
+
+// clean - значение ячейки без лишних пробелов
+func clean(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,674 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Karielka/Hackaton_MAX/internal/text"
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// Импорт расписания за семестр из .ics или JSON (timetableJSON).
+//
+// Импорт заменяет занятия семестра целиком, но без лишних перезаписей:
+// совпадающие занятия остаются как есть, пропавшие из файла удаляются,
+// новые создаются. Повторный запуск того же файла ничего не меняет.
+// Преподаватели сопоставляются по ФИО («Иванов Иван Иванович» или «Иванов И.И.»)
+// и не создаются — их заводит импорт сотрудников. Группы создаются по имени.
+
+// Сущности в отчёте
+const (
+	EntitySemester = "semester"
+	EntityGroup    = "group"
+	EntityLesson   = "lesson"
+)
+
+// TimetableOptions - параметры импорта расписания
+type TimetableOptions struct {
+	DryRun   bool
+	Semester string         // имя семестра; для .ics обязательно, для JSON переопределяет semester.name
+	Location *time.Location // часовой пояс университета: к нему приводятся времена .ics
+}
+
+// timetableJSON - формат JSON-выгрузки:
+//
+//	{
+//	  "semester": {"name": "Осень 2025", "start": "2025-09-01", "end": "2025-12-28"},
+//	  "lessons": [{
+//	    "teacher": "Иванов Иван Иванович", "subject": "Базы данных", "type": "lecture",
+//	    "weekday": 1, "start": "10:15", "end": "11:50", "parity": "odd",
+//	    "room": "А-101", "campus": "ГУК", "groups": ["ИУ1-31Б"]
+//	  }]
+//	}
+//
+// weekday: 1 — Пн … 7 — Вс; parity: "" (каждую неделю), "odd", "even";
+// type: lecture | seminar | lab (или по-русски); campus — короткое или полное название.
+type timetableJSON struct {
+	Semester *struct {
+		Name  string `json:"name"`
+		Start string `json:"start"`
+		End   string `json:"end"`
+	} `json:"semester"`
+	Lessons []struct {
+		Teacher string   `json:"teacher"`
+		Subject string   `json:"subject"`
+		Type    string   `json:"type"`
+		Weekday int      `json:"weekday"`
+		Start   string   `json:"start"`
+		End     string   `json:"end"`
+		Parity  string   `json:"parity"`
+		Room    string   `json:"room"`
+		Campus  string   `json:"campus"`
+		Groups  []string `json:"groups"`
+	} `json:"lessons"`
+}
+
+// lessonEntry - занятие из файла до сопоставления с базой
+type lessonEntry struct {
+	Line       int // строка BEGIN:VEVENT или номер занятия в JSON
+	Teacher    string
+	Subject    string
+	Type       string
+	Weekday    int
+	Start, End int // минуты от полуночи
+	Parity     string
+	Room       string
+	Campus     string
+	Groups     []string
+	Err        string // ошибка разбора — строка попадёт в отчёт
+
+	// только .ics: дата занятия, по ней считается чётность
+	Date     time.Time
+	Biweekly bool // RRULE INTERVAL=2
+	Weekly   bool // RRULE INTERVAL=1 — каждую неделю
+}
+
+// timetableFile - разобранный файл
+type timetableFile struct {
+	SemName    string
+	Start, End time.Time // границы семестра из файла (JSON) или по датам событий и UNTIL (.ics)
+	Lessons    []lessonEntry
+	Warnings   []string
+}
+
+// ImportTimetable заменяет расписание семестра данными файла одной транзакцией
+func ImportTimetable(ctx context.Context, db *gorm.DB, data []byte, format string, opt TimetableOptions) (*Report, error) {
+	if opt.Location == nil {
+		opt.Location = time.Local
+	}
+	var (
+		f   *timetableFile
+		err error
+	)
+	switch format {
+	case FormatJSON:
+		f, err = parseTimetableJSON(data)
+	case FormatICS:
+		f, err = parseTimetableICS(data, opt.Location)
+	default:
+		err = fmt.Errorf("unsupported timetable format %q (want ics or json)", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadFile, err)
+	}
+	if opt.Semester != "" {
+		f.SemName = clean(opt.Semester)
+	}
+	if f.SemName == "" {
+		return nil, fmt.Errorf("%w: semester name is required", ErrBadFile)
+	}
+
+	rep := newReport(opt.DryRun)
+	rep.Warnings = f.Warnings
+	rep.Rows = len(f.Lessons)
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		sem, err := resolveSemester(tx, f, rep)
+		if err != nil {
+			return err
+		}
+		entries := resolveParity(f.Lessons, sem)
+
+		idx, err := loadTimetableIndex(tx)
+		if err != nil {
+			return err
+		}
+		want := map[string]models.Lesson{}
+		var order []string
+		for _, e := range entries {
+			l, err := idx.lesson(tx, e, sem.ID, rep)
+			if err != nil {
+				rep.fail(e.Line, "%v", err)
+				continue
+			}
+			k := lessonKey(l)
+			if _, dup := want[k]; !dup {
+				want[k] = l
+				order = append(order, k)
+			}
+		}
+		if err := syncLessons(tx, sem.ID, want, order, rep); err != nil {
+			return err
+		}
+		if opt.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if errors.Is(err, ErrBadFile) {
+		return nil, err
+	}
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, fmt.Errorf("import timetable: %w", err)
+	}
+	return rep, nil
+}
+
+// ---- разбор файлов ----
+
+func parseTimetableJSON(data []byte) (*timetableFile, error) {
+	var in timetableJSON
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		return nil, fmt.Errorf("read json: %w", err)
+	}
+	f := &timetableFile{}
+	if s := in.Semester; s != nil {
+		f.SemName = clean(s.Name)
+		var err error
+		if s.Start != "" {
+			if f.Start, err = time.Parse(time.DateOnly, s.Start); err != nil {
+				return nil, fmt.Errorf("semester.start: want YYYY-MM-DD")
+			}
+		}
+		if s.End != "" {
+			if f.End, err = time.Parse(time.DateOnly, s.End); err != nil {
+				return nil, fmt.Errorf("semester.end: want YYYY-MM-DD")
+			}
+		}
+	}
+	for i, l := range in.Lessons {
+		e := lessonEntry{
+			Line: i + 1, Teacher: clean(l.Teacher), Subject: clean(l.Subject),
+			Type: lessonType(l.Type), Weekday: l.Weekday, Parity: strings.ToLower(strings.TrimSpace(l.Parity)),
+			Room: clean(l.Room), Campus: clean(l.Campus),
+		}
+		for _, g := range l.Groups {
+			if g = clean(g); g != "" {
+				e.Groups = append(e.Groups, g)
+			}
+		}
+		var err1, err2 error
+		e.Start, err1 = parseClock(l.Start)
+		e.End, err2 = parseClock(l.End)
+		if err := errors.Join(err1, err2); err != nil {
+			e.Err = err.Error()
+		}
+		if e.Type == "?" {
+			e.Err = fmt.Sprintf("unknown lesson type %q", l.Type)
+		}
+		f.Lessons = append(f.Lessons, e)
+	}
+	return f, nil
+}
+
+func parseTimetableICS(data []byte, loc *time.Location) (*timetableFile, error) {
+	events, err := readICS(data)
+	if err != nil {
+		return nil, err
+	}
+	f := &timetableFile{}
+	for _, ev := range events {
+		e := lessonEntry{Line: ev.Line}
+		summary := icalText(ev.get("SUMMARY"))
+		e.Subject, e.Type = splitSubjectType(summary)
+		if c := icalText(ev.get("CATEGORIES")); c != "" {
+			if t := lessonType(strings.Split(c, ",")[0]); t != "?" {
+				e.Type = t
+			}
+		}
+		if e.Type == "?" {
+			e.Type = ""
+		}
+
+		desc := icalText(ev.get("DESCRIPTION"))
+		e.Teacher = clean(ev.Props["ORGANIZER"].Params["CN"])
+		if e.Teacher == "" {
+			e.Teacher = clean(icalText(ev.get("X-TEACHER")))
+		}
+		if e.Teacher == "" {
+			e.Teacher = clean(icalLabeled(desc, "преподаватель", "teacher"))
+		}
+		groups := icalText(ev.get("X-GROUPS"))
+		if groups == "" {
+			groups = icalLabeled(desc, "группы", "группа", "groups", "group")
+		}
+		for _, g := range strings.Split(groups, ",") {
+			if g = clean(g); g != "" {
+				e.Groups = append(e.Groups, g)
+			}
+		}
+		e.Room, e.Campus = splitLocation(icalText(ev.get("LOCATION")))
+
+		start, allDay, err := icalTime(ev.Props["DTSTART"], loc)
+		if err != nil || ev.get("DTSTART") == "" {
+			e.Err = "bad or missing DTSTART"
+			f.Lessons = append(f.Lessons, e)
+			continue
+		}
+		if allDay {
+			f.Warnings = append(f.Warnings, fmt.Sprintf("line %d: all-day event %q skipped", ev.Line, summary))
+			continue
+		}
+		end, _, err := icalTime(ev.Props["DTEND"], loc)
+		if err != nil || ev.get("DTEND") == "" {
+			e.Err = "bad or missing DTEND"
+			f.Lessons = append(f.Lessons, e)
+			continue
+		}
+		e.Date = start
+		e.Weekday = models.IsoWeekday(start)
+		e.Start = start.Hour()*60 + start.Minute()
+		e.End = end.Hour()*60 + end.Minute()
+		f.extendDates(start)
+
+		days := []int{e.Weekday}
+		if raw := ev.get("RRULE"); raw != "" {
+			rule, err := parseRRule(raw, loc)
+			if err != nil {
+				e.Err = err.Error()
+				f.Lessons = append(f.Lessons, e)
+				continue
+			}
+			e.Weekly, e.Biweekly = rule.Interval == 1, rule.Interval == 2
+			if !rule.Until.IsZero() {
+				f.extendDates(rule.Until)
+			}
+			if len(rule.Weekdays) > 0 {
+				days = rule.Weekdays
+			}
+		}
+		for _, wd := range days {
+			d := e
+			d.Weekday = wd
+			d.Date = e.Date.AddDate(0, 0, wd-e.Weekday) // та же неделя — та же чётность
+			f.Lessons = append(f.Lessons, d)
+		}
+	}
+	return f, nil
+}
+
+func (f *timetableFile) extendDates(t time.Time) {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if f.Start.IsZero() || day.Before(f.Start) {
+		f.Start = day
+	}
+	if day.After(f.End) {
+		f.End = day
+	}
+}
+
+// parseClock - «9:05» или «09:05» в минуты от полуночи
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("bad time %q (want HH:MM)", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// lessonType - тип занятия по подписи; "?" — не распознан
+func lessonType(s string) string {
+	switch text.Fold(strings.Trim(s, "(). ")) {
+	case "":
+		return ""
+	case models.LessonLecture, "лекция", "лек":
+		return models.LessonLecture
+	case models.LessonSeminar, "семинар", "сем", "практика", "пр":
+		return models.LessonSeminar
+	case models.LessonLab, "лабораторная", "лабораторная работа", "лаб":
+		return models.LessonLab
+	}
+	return "?"
+}
+
+// splitSubjectType - «Базы данных (лекция)» -> «Базы данных», lecture
+func splitSubjectType(summary string) (string, string) {
+	summary = clean(summary)
+	if i := strings.LastIndex(summary, "("); i > 0 && strings.HasSuffix(summary, ")") {
+		if t := lessonType(summary[i:]); t != "" && t != "?" {
+			return strings.TrimSpace(summary[:i]), t
+		}
+	}
+	return summary, ""
+}
+
+// splitLocation - «А-101, ГУК» -> аудитория и корпус (корпус проверяется при сопоставлении)
+func splitLocation(loc string) (room, campus string) {
+	loc = clean(loc)
+	if i := strings.LastIndex(loc, ","); i >= 0 {
+		return strings.TrimSpace(loc[:i]), strings.TrimSpace(loc[i+1:])
+	}
+	return loc, ""
+}
+
+// ---- семестр и чётность ----
+
+// resolveSemester - семестр по имени; новый создаётся по границам из файла
+func resolveSemester(tx *gorm.DB, f *timetableFile, rep *Report) (*models.Semester, error) {
+	var sem models.Semester
+	err := tx.Where("name = ?", f.SemName).First(&sem).Error
+	if err == nil {
+		return &sem, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if f.Start.IsZero() || f.End.IsZero() {
+		return nil, fmt.Errorf("%w: semester %q does not exist and the file has no start/end dates", ErrBadFile, f.SemName)
+	}
+	// неделя 1 начинается с понедельника
+	start := f.Start.AddDate(0, 0, 1-models.IsoWeekday(f.Start))
+	sem = models.Semester{Name: f.SemName, StartDate: start, EndDate: f.End}
+	if err := tx.Create(&sem).Error; err != nil {
+		return nil, fmt.Errorf("create semester: %w", err)
+	}
+	rep.add(Change{Action: ActionCreate, Entity: EntitySemester,
+		Name: fmt.Sprintf("%s (%s – %s)", sem.Name, start.Format(time.DateOnly), f.End.Format(time.DateOnly))})
+	return &sem, nil
+}
+
+// resolveParity - чётность занятий .ics по датам. Отдельные события одного слота
+// сливаются: встретилось в обе чётности — каждую неделю, в одну — только в неё.
+// Если файл короче двух недель, судить о чётности нельзя — всё «каждую неделю».
+func resolveParity(entries []lessonEntry, sem *models.Semester) []lessonEntry {
+	var minD, maxD time.Time
+	for _, e := range entries {
+		if e.Date.IsZero() {
+			continue
+		}
+		if minD.IsZero() || e.Date.Before(minD) {
+			minD = e.Date
+		}
+		if e.Date.After(maxD) {
+			maxD = e.Date
+		}
+	}
+	shortFeed := maxD.Sub(minD) < 7*24*time.Hour
+
+	type slot struct {
+		idx      int
+		parities map[string]bool
+	}
+	var out []lessonEntry
+	slots := map[string]*slot{}
+	for _, e := range entries {
+		if e.Date.IsZero() || e.Err != "" {
+			out = append(out, e)
+			continue
+		}
+		switch {
+		case e.Weekly:
+			e.Parity = models.ParityAny
+		case e.Biweekly:
+			e.Parity = models.ParityAt(sem.StartDate, e.Date)
+		case shortFeed:
+			e.Parity = models.ParityAny
+		default:
+			// отдельное событие: чётность копим по слоту
+			k := fmt.Sprintf("%d|%d|%d|%s|%s|%s|%s|%s|%s", e.Weekday, e.Start, e.End,
+				text.Fold(e.Teacher), text.Fold(e.Subject), e.Type, text.Fold(e.Room), text.Fold(e.Campus), text.Fold(strings.Join(e.Groups, ",")))
+			s, ok := slots[k]
+			if !ok {
+				s = &slot{idx: len(out), parities: map[string]bool{}}
+				slots[k] = s
+				out = append(out, e)
+			}
+			s.parities[models.ParityAt(sem.StartDate, e.Date)] = true
+			continue
+		}
+		out = append(out, e)
+	}
+	for _, s := range slots {
+		if len(s.parities) == 1 {
+			for p := range s.parities {
+				out[s.idx].Parity = p
+			}
+		} else {
+			out[s.idx].Parity = models.ParityAny
+		}
+	}
+	return out
+}
+
+// ---- сопоставление с базой ----
+
+type timetableIndex struct {
+	teachersFull     map[string][]uint // text.Fold(ФИО)
+	teachersInitials map[string][]uint // «иванов ии»
+	campuses         map[string]uint   // короткое и полное название
+	groups           map[string]*models.Group
+}
+
+func loadTimetableIndex(tx *gorm.DB) (*timetableIndex, error) {
+	idx := &timetableIndex{
+		teachersFull:     map[string][]uint{},
+		teachersInitials: map[string][]uint{},
+		campuses:         map[string]uint{},
+		groups:           map[string]*models.Group{},
+	}
+	var teachers []models.Teacher
+	if err := tx.Select("id", "full_name").Find(&teachers).Error; err != nil {
+		return nil, fmt.Errorf("load teachers: %w", err)
+	}
+	for _, t := range teachers {
+		k := text.Fold(t.FullName)
+		idx.teachersFull[k] = append(idx.teachersFull[k], t.ID)
+		ik := initialsKey(t.FullName)
+		idx.teachersInitials[ik] = append(idx.teachersInitials[ik], t.ID)
+	}
+	var campuses []models.Campus
+	if err := tx.Find(&campuses).Error; err != nil {
+		return nil, fmt.Errorf("load campuses: %w", err)
+	}
+	for _, c := range campuses {
+		idx.campuses[text.Fold(c.ShortName)] = c.ID
+		idx.campuses[text.Fold(c.FullName)] = c.ID
+	}
+	var groups []models.Group
+	if err := tx.Find(&groups).Error; err != nil {
+		return nil, fmt.Errorf("load groups: %w", err)
+	}
+	for i := range groups {
+		idx.groups[text.Fold(groups[i].Name)] = &groups[i]
+	}
+	return idx, nil
+}
+
+// initialsKey - фамилия и первые буквы имени и отчества: «Иванов И. И.» -> «иванов ии»
+func initialsKey(name string) string {
+	words := strings.Fields(text.Fold(strings.ReplaceAll(name, ".", " ")))
+	if len(words) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(words[0])
+	b.WriteString(" ")
+	for _, w := range words[1:min(len(words), 3)] {
+		b.WriteRune([]rune(w)[0])
+	}
+	return b.String()
+}
+
+// teacher - id преподавателя по ФИО; ошибка, если не найден или неоднозначен
+func (idx *timetableIndex) teacher(name string) (uint, error) {
+	ids := idx.teachersFull[text.Fold(name)]
+	if len(ids) == 0 {
+		ids = idx.teachersInitials[initialsKey(name)]
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	switch len(ids) {
+	case 0:
+		return 0, fmt.Errorf("unknown teacher %q", name)
+	case 1:
+		return ids[0], nil
+	default:
+		return 0, fmt.Errorf("teacher %q is ambiguous (%d matches), use the full name", name, len(ids))
+	}
+}
+
+// lesson проверяет занятие и переводит его в модель; недостающие группы создаются
+func (idx *timetableIndex) lesson(tx *gorm.DB, e lessonEntry, semID uint, rep *Report) (models.Lesson, error) {
+	switch {
+	case e.Err != "":
+		return models.Lesson{}, errors.New(e.Err)
+	case e.Teacher == "":
+		return models.Lesson{}, errors.New("teacher is empty")
+	case e.Subject == "":
+		return models.Lesson{}, errors.New("subject is empty")
+	case e.Weekday < 1 || e.Weekday > 7:
+		return models.Lesson{}, fmt.Errorf("weekday must be 1..7, got %d", e.Weekday)
+	case e.Start >= e.End:
+		return models.Lesson{}, errors.New("lesson must end after it starts")
+	case e.Parity != models.ParityAny && e.Parity != models.ParityOdd && e.Parity != models.ParityEven:
+		return models.Lesson{}, fmt.Errorf("parity must be empty, odd or even, got %q", e.Parity)
+	}
+	teacherID, err := idx.teacher(e.Teacher)
+	if err != nil {
+		return models.Lesson{}, err
+	}
+
+	l := models.Lesson{
+		TeacherID: teacherID, SemesterID: &semID, Weekday: e.Weekday,
+		StartMinute: e.Start, EndMinute: e.End, WeekParity: e.Parity,
+		Room: e.Room, Subject: e.Subject, LessonType: e.Type,
+	}
+	if e.Campus != "" {
+		id, ok := idx.campuses[text.Fold(e.Campus)]
+		switch {
+		case ok:
+			l.CampusID = &id
+		case e.Date.IsZero():
+			return models.Lesson{}, fmt.Errorf("unknown campus %q", e.Campus)
+		default:
+			// в LOCATION .ics после запятой не обязательно корпус
+			l.Room = strings.TrimPrefix(l.Room+", "+e.Campus, ", ")
+		}
+	}
+	for _, name := range e.Groups {
+		g, ok := idx.groups[text.Fold(name)]
+		if !ok {
+			g = &models.Group{Name: name}
+			if err := tx.Create(g).Error; err != nil {
+				return models.Lesson{}, fmt.Errorf("create group %q: %w", name, err)
+			}
+			idx.groups[text.Fold(name)] = g
+			rep.add(Change{Line: e.Line, Action: ActionCreate, Entity: EntityGroup, Name: name})
+		}
+		l.Groups = append(l.Groups, *g)
+	}
+	return l, nil
+}
+
+// lessonKey - занятие без id: по нему сравниваются старое и новое расписание
+func lessonKey(l models.Lesson) string {
+	campus := uint(0)
+	if l.CampusID != nil {
+		campus = *l.CampusID
+	}
+	groups := make([]uint, len(l.Groups))
+	for i, g := range l.Groups {
+		groups[i] = g.ID
+	}
+	slices.Sort(groups)
+	groups = slices.Compact(groups)
+	return fmt.Sprintf("%d|%d|%d|%d|%s|%s|%s|%s|%d|%v",
+		l.TeacherID, l.Weekday, l.StartMinute, l.EndMinute, l.WeekParity,
+		text.Fold(l.Subject), l.LessonType, text.Fold(l.Room), campus, groups)
+}
+
+// syncLessons приводит занятия семестра к want: лишние удаляет, недостающие создаёт
+func syncLessons(tx *gorm.DB, semID uint, want map[string]models.Lesson, order []string, rep *Report) error {
+	var have []models.Lesson
+	if err := tx.Preload("Groups").Preload("Teacher").
+		Where("semester_id = ?", semID).Order("id").Find(&have).Error; err != nil {
+		return fmt.Errorf("load lessons: %w", err)
+	}
+	kept := map[string]bool{}
+	var stale []uint
+	for _, l := range have {
+		k := lessonKey(l)
+		if _, ok := want[k]; ok && !kept[k] {
+			kept[k] = true
+			continue
+		}
+		stale = append(stale, l.ID)
+		rep.add(Change{Action: ActionDelete, Entity: EntityLesson, Name: lessonName(l, l.Teacher.FullName)})
+	}
+	if len(stale) > 0 {
+		if err := tx.Exec("DELETE FROM lesson_groups WHERE lesson_id IN ?", stale).Error; err != nil {
+			return fmt.Errorf("delete lesson groups: %w", err)
+		}
+		if err := tx.Delete(&models.Lesson{}, stale).Error; err != nil {
+			return fmt.Errorf("delete lessons: %w", err)
+		}
+	}
+
+	var teacherNames map[uint]string
+	for _, k := range order {
+		if kept[k] {
+			rep.Unchanged++
+			continue
+		}
+		l := want[k]
+		if err := tx.Omit("Teacher", "Semester", "Campus", "Groups.*").Create(&l).Error; err != nil {
+			return fmt.Errorf("create lesson: %w", err)
+		}
+		if teacherNames == nil {
+			var err error
+			if teacherNames, err = loadTeacherNames(tx); err != nil {
+				return err
+			}
+		}
+		rep.add(Change{Action: ActionCreate, Entity: EntityLesson, Name: lessonName(l, teacherNames[l.TeacherID])})
+	}
+	return nil
+}
+
+func loadTeacherNames(tx *gorm.DB) (map[uint]string, error) {
+	var teachers []models.Teacher
+	if err := tx.Select("id", "full_name").Find(&teachers).Error; err != nil {
+		return nil, fmt.Errorf("load teachers: %w", err)
+	}
+	names := make(map[uint]string, len(teachers))
+	for _, t := range teachers {
+		names[t.ID] = t.FullName
+	}
+	return names, nil
+}
+
+// lessonName - «Пн 10:15–11:50 (нечёт.) Базы данных, Иванов И.И., ИУ1-31Б»
+func lessonName(l models.Lesson, teacher string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s–%s", models.WeekdayShort[l.Weekday], models.FormatMinute(l.StartMinute), models.FormatMinute(l.EndMinute))
+	switch l.WeekParity {
+	case models.ParityOdd:
+		b.WriteString(" (нечёт.)")
+	case models.ParityEven:
+		b.WriteString(" (чёт.)")
+	}
+	fmt.Fprintf(&b, " %s, %s", l.Subject, teacher)
+	for _, g := range l.Groups {
+		b.WriteString(", " + g.Name)
+	}
+	return b.String()
+}
@@ -0,0 +1,316 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/Karielka/Hackaton_MAX/internal/fixtures"
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// openDB - SQLite во временном каталоге со схемой, демо-данными и преподавателем,
+// чьё ФИО не повторяется (в демо-данных фамилии случайные и могут совпасть)
+func openDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(t.TempDir()+"/importer.db?_pragma=foreign_keys(1)"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := models.AutoMigrate(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := fixtures.LoadSample(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&models.Teacher{FullName: "Тестов Пётр Ильич", DepartmentID: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// semesterLessons - занятия семестра в порядке дня недели и начала
+func semesterLessons(t *testing.T, db *gorm.DB, name string) []models.Lesson {
+	t.Helper()
+	var sem models.Semester
+	if err := db.Where("name = ?", name).First(&sem).Error; err != nil {
+		t.Fatalf("semester %q: %v", name, err)
+	}
+	var lessons []models.Lesson
+	if err := db.Preload("Groups").Preload("Teacher").Where("semester_id = ?", sem.ID).
+		Order("weekday, start_minute").Find(&lessons).Error; err != nil {
+		t.Fatal(err)
+	}
+	return lessons
+}
+
+const timetableJSONFile = `{
+  "semester": {"name": "Осень 2030", "start": "2030-09-04", "end": "2030-12-28"},
+  "lessons": [
+    {"teacher": "Тестов Пётр Ильич", "subject": "Базы данных", "type": "лекция",
+     "weekday": 1, "start": "10:15", "end": "11:50", "parity": "odd",
+     "room": "А-101", "campus": "ГУК", "groups": ["ИУ5-31Б", "ТСТ-11"]},
+    {"teacher": "Тестов П. И.", "subject": "Базы данных", "type": "lab",
+     "weekday": 3, "start": "12:00", "end": "13:35", "groups": ["ТСТ-11"]},
+    {"teacher": "Тестов Пётр Ильич", "subject": "Сети", "weekday": 2, "start": "25:00", "end": "26:00"},
+    {"teacher": "Нетаков Н. Н.", "subject": "Сети", "weekday": 2, "start": "09:00", "end": "10:35"},
+    {"teacher": "Тестов Пётр Ильич", "subject": "Сети", "weekday": 2, "start": "09:00", "end": "10:35", "campus": "Луна"},
+    {"teacher": "Тестов Пётр Ильич", "subject": "Сети", "weekday": 8, "start": "09:00", "end": "10:35"}
+  ]
+}`
+
+func TestImportTimetableJSON(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	data := []byte(timetableJSONFile)
+
+	rep, err := ImportTimetable(ctx, db, data, FormatJSON, TimetableOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rep.DryRun || rep.Created[EntitySemester] != 1 || rep.Created[EntityLesson] != 2 {
+		t.Errorf("dry run report: %+v", rep)
+	}
+	if n := db.Where("name = ?", "Осень 2030").Find(&[]models.Semester{}).RowsAffected; n != 0 {
+		t.Fatalf("dry run saved the semester")
+	}
+	if n := db.Where("name = ?", "ТСТ-11").Find(&[]models.Group{}).RowsAffected; n != 0 {
+		t.Fatalf("dry run saved the group")
+	}
+
+	rep, err = ImportTimetable(ctx, db, data, FormatJSON, TimetableOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Rows != 6 || rep.Created[EntitySemester] != 1 || rep.Created[EntityGroup] != 1 || rep.Created[EntityLesson] != 2 {
+		t.Errorf("report: rows %d, created %v", rep.Rows, rep.Created)
+	}
+	wantErr := map[int]string{3: "bad time", 4: "unknown teacher", 5: "unknown campus", 6: "weekday must be 1..7"}
+	if len(rep.Errors) != len(wantErr) {
+		t.Errorf("errors: %+v", rep.Errors)
+	}
+	for _, e := range rep.Errors {
+		if !strings.Contains(e.Error, wantErr[e.Line]) {
+			t.Errorf("line %d: error %q, want %q", e.Line, e.Error, wantErr[e.Line])
+		}
+	}
+
+	var sem models.Semester
+	db.Where("name = ?", "Осень 2030").First(&sem)
+	if got := sem.StartDate.Format(time.DateOnly); got != "2030-09-02" {
+		t.Errorf("semester start %s, want Monday 2030-09-02", got)
+	}
+	lessons := semesterLessons(t, db, "Осень 2030")
+	if len(lessons) != 2 {
+		t.Fatalf("lessons: %d, want 2", len(lessons))
+	}
+	lec, lab := lessons[0], lessons[1]
+	if lec.Teacher.FullName != "Тестов Пётр Ильич" || lab.Teacher.FullName != "Тестов Пётр Ильич" {
+		t.Errorf("teachers: %q, %q", lec.Teacher.FullName, lab.Teacher.FullName)
+	}
+	if lec.LessonType != models.LessonLecture || lec.WeekParity != models.ParityOdd || lec.CampusID == nil || len(lec.Groups) != 2 {
+		t.Errorf("lecture: %+v", lec)
+	}
+	if lab.LessonType != models.LessonLab || lab.WeekParity != models.ParityAny || lab.StartMinute != 12*60 || len(lab.Groups) != 1 {
+		t.Errorf("lab: %+v", lab)
+	}
+
+	// повторный запуск ничего не меняет
+	rep, err = ImportTimetable(ctx, db, data, FormatJSON, TimetableOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Changes) != 0 || rep.Unchanged != 2 {
+		t.Errorf("re-run: unchanged %d, changes %+v", rep.Unchanged, rep.Changes)
+	}
+
+	// пропавшее из файла занятие удаляется, остальные остаются
+	data = []byte(strings.Replace(timetableJSONFile, `"type": "lab"`, `"type": "seminar"`, 1))
+	rep, err = ImportTimetable(ctx, db, data, FormatJSON, TimetableOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Unchanged != 1 || rep.Created[EntityLesson] != 1 || rep.Deleted[EntityLesson] != 1 {
+		t.Errorf("replace: unchanged %d, created %v, deleted %v", rep.Unchanged, rep.Created, rep.Deleted)
+	}
+	if lessons := semesterLessons(t, db, "Осень 2030"); len(lessons) != 2 || lessons[1].LessonType != models.LessonSeminar {
+		t.Errorf("after replace: %+v", lessons)
+	}
+}
+
+func TestImportTimetableBadFile(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	for name, tc := range map[string]struct {
+		data, format string
+		opt          TimetableOptions
+	}{
+		"broken json":        {`{"lessons": [`, FormatJSON, TimetableOptions{}},
+		"unknown field":      {`{"lesons": []}`, FormatJSON, TimetableOptions{}},
+		"no semester":        {`{"lessons": []}`, FormatJSON, TimetableOptions{}},
+		"new semester dates": {`{"semester": {"name": "Весна 2031"}, "lessons": []}`, FormatJSON, TimetableOptions{}},
+		"not a calendar":     {"BEGIN:VEVENT\nEND:VEVENT\n", FormatICS, TimetableOptions{Semester: "Осень 2030"}},
+		"unknown format":     {"{}", "xlsx", TimetableOptions{Semester: "Осень 2030"}},
+	} {
+		if _, err := ImportTimetable(ctx, db, []byte(tc.data), tc.format, tc.opt); !errors.Is(err, ErrBadFile) {
+			t.Errorf("%s: err %v, want ErrBadFile", name, err)
+		}
+	}
+}
+
+// Пара по нечётным неделям (RRULE INTERVAL=2 с BYDAY на два дня), пара по чётным
+// двумя отдельными событиями и событие на весь день, которое пропускается
+const timetableICSFile = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART:20300902T101500\r\n" +
+	"DTEND:20300902T115000\r\n" +
+	"RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;UNTIL=20301228T000000Z\r\n" +
+	"SUMMARY:Базы данных (лекция)\r\n" +
+	"ORGANIZER;CN=\"Тестов Пётр Ильич\":mailto:test@example.com\r\n" +
+	"LOCATION:А-101\\, ГУК\r\n" +
+	"X-GROUPS:ИУ5-31Б\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART:20300910T090000\r\n" +
+	"DTEND:20300910T103500\r\n" +
+	"SUMMARY:Сети\r\n" +
+	"CATEGORIES:семинар\r\n" +
+	"DESCRIPTION:Преподаватель: Тестов П.И.\\nГруппы: ИУ5-31Б\\, ТСТ-11\r\n" +
+	"LOCATION:Ауд. 5\\, окно\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART:20300924T090000\r\n" +
+	"DTEND:20300924T103500\r\n" +
+	"SUMMARY:Сети\r\n" +
+	"CATEGORIES:семинар\r\n" +
+	"DESCRIPTION:Преподаватель: Тестов П.И.\\nГруппы: ИУ5-31Б\\, ТСТ-11\r\n" +
+	"LOCATION:Ауд. 5\\, окно\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;VALUE=DATE:20300905\r\n" +
+	"SUMMARY:День открытых дверей\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestImportTimetableICS(t *testing.T) {
+	db := openDB(t)
+	opt := TimetableOptions{Semester: "Осень 2030", Location: time.UTC}
+	rep, err := ImportTimetable(context.Background(), db, []byte(timetableICSFile), FormatICS, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Errors) != 0 || len(rep.Warnings) != 1 || rep.Created[EntityLesson] != 3 {
+		t.Errorf("report: created %v, errors %+v, warnings %v", rep.Created, rep.Errors, rep.Warnings)
+	}
+
+	var sem models.Semester
+	db.Where("name = ?", "Осень 2030").First(&sem)
+	if got := sem.StartDate.Format(time.DateOnly) + " – " + sem.EndDate.Format(time.DateOnly); got != "2030-09-02 – 2030-12-28" {
+		t.Errorf("semester dates %s, want first event and UNTIL", got)
+	}
+
+	type row struct {
+		weekday, start int
+		parity, typ    string
+		room           string
+		campus         bool
+		groups         int
+	}
+	want := []row{
+		{1, 10*60 + 15, models.ParityOdd, models.LessonLecture, "А-101", true, 1},
+		{2, 9 * 60, models.ParityEven, models.LessonSeminar, "Ауд. 5, окно", false, 2},
+		{4, 10*60 + 15, models.ParityOdd, models.LessonLecture, "А-101", true, 1},
+	}
+	lessons := semesterLessons(t, db, "Осень 2030")
+	if len(lessons) != len(want) {
+		t.Fatalf("lessons: %d, want %d", len(lessons), len(want))
+	}
+	for i, l := range lessons {
+		got := row{l.Weekday, l.StartMinute, l.WeekParity, l.LessonType, l.Room, l.CampusID != nil, len(l.Groups)}
+		if got != want[i] {
+			t.Errorf("lesson %d: got %+v, want %+v", i, got, want[i])
+		}
+	}
+
+	rep, err = ImportTimetable(context.Background(), db, []byte(timetableICSFile), FormatICS, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Changes) != 0 || rep.Unchanged != 3 {
+		t.Errorf("re-run: unchanged %d, changes %+v", rep.Unchanged, rep.Changes)
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	for in, want := range map[string]int{"9:05": 545, "09:05": 545, " 23:59 ": 1439, "00:00": 0} {
+		if got, err := parseClock(in); err != nil || got != want {
+			t.Errorf("parseClock(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "24:00", "9.05", "9:5x"} {
+		if _, err := parseClock(in); err == nil {
+			t.Errorf("parseClock(%q): want error", in)
+		}
+	}
+}
+
+func TestLessonType(t *testing.T) {
+	for in, want := range map[string]string{
+		"":             "",
+		"lecture":      models.LessonLecture,
+		"(Лекция)":     models.LessonLecture,
+		"сем.":         models.LessonSeminar,
+		"практика":     models.LessonSeminar,
+		"Лабораторная": models.LessonLab,
+		"экзамен":      "?",
+	} {
+		if got := lessonType(in); got != want {
+			t.Errorf("lessonType(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if s, typ := splitSubjectType("Базы данных (лаб)"); s != "Базы данных" || typ != models.LessonLab {
+		t.Errorf("splitSubjectType: %q, %q", s, typ)
+	}
+	if s, typ := splitSubjectType("Физика (углублённый курс)"); s != "Физика (углублённый курс)" || typ != "" {
+		t.Errorf("splitSubjectType keeps unknown brackets: %q, %q", s, typ)
+	}
+}
+
+func TestParseRRule(t *testing.T) {
+	r, err := parseRRule("FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,1TH;UNTIL=20301228T000000Z", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Interval != 2 || len(r.Weekdays) != 2 || r.Weekdays[0] != 1 || r.Weekdays[1] != 4 ||
+		!r.Until.Equal(time.Date(2030, 12, 28, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("parseRRule: %+v", r)
+	}
+	if r, err := parseRRule("FREQ=WEEKLY", time.UTC); err != nil || r.Interval != 1 || r.Weekdays != nil || !r.Until.IsZero() {
+		t.Errorf("defaults: %+v, %v", r, err)
+	}
+	for _, in := range []string{"FREQ=DAILY", "FREQ=WEEKLY;INTERVAL=3", "FREQ=WEEKLY;INTERVAL=0", "FREQ=WEEKLY;BYDAY=XX", "FREQ=WEEKLY;UNTIL=soon"} {
+		if _, err := parseRRule(in, time.UTC); err == nil {
+			t.Errorf("parseRRule(%q): want error", in)
+		}
+	}
+}
+
+func TestInitialsKey(t *testing.T) {
+	for in, want := range map[string]string{
+		"Тестов Пётр Ильич": "тестов пи",
+		"Тестов П.И.":       "тестов пи",
+		"тестов  п. и.":     "тестов пи",
+		"Тестов":            "тестов ",
+	} {
+		if got := initialsKey(in); got != want {
+			t.Errorf("initialsKey(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
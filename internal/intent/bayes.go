@@ -0,0 +1,108 @@
+package intent
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/Karielka/Hackaton_MAX/internal/text"
+)
+
+// trainData - размеченные фразы для байесовской модели: «намерение | фраза»
+//
+//go:embed train.txt
+var trainData string
+
+// Bayes - наивный байесовский классификатор по основам слов (мультиномиальный, со сглаживанием Лапласа).
+// Априорные вероятности классов равные, чтобы число примеров в train.txt не сдвигало ответ.
+type Bayes struct {
+	counts map[Intent]map[string]int // сколько раз основа встретилась в примерах класса
+	totals map[Intent]int            // всего основ в примерах класса
+	vocab  map[string]bool
+}
+
+// TrainBayes обучает модель на строках «намерение | фраза»; пустые строки и «#» пропускаются
+func TrainBayes(r io.Reader) (*Bayes, error) {
+	b := &Bayes{counts: map[Intent]map[string]int{}, totals: map[Intent]int{}, vocab: map[string]bool{}}
+	known := map[Intent]bool{Other: true}
+	for _, in := range Intents {
+		known[in] = true
+	}
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		ln := strings.TrimSpace(sc.Text())
+		if ln == "" || strings.HasPrefix(ln, "#") {
+			continue
+		}
+		label, phrase, ok := strings.Cut(ln, "|")
+		in := Intent(strings.TrimSpace(label))
+		if !ok || !known[in] {
+			return nil, fmt.Errorf("train data line %d: want «intent | phrase», got %q", line, ln)
+		}
+		if b.counts[in] == nil {
+			b.counts[in] = map[string]int{}
+		}
+		for _, w := range features(text.Keywords(phrase)) {
+			b.counts[in][w]++
+			b.totals[in]++
+			b.vocab[w] = true
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read train data: %w", err)
+	}
+	return b, nil
+}
+
+// Score - апостериорные вероятности классов. Если ни одного знакомого модели слова нет,
+// оценок нет вовсе (а не равные), чтобы незнакомый текст не набирал баллы.
+func (b *Bayes) Score(q Query) map[Intent]float64 {
+	var words []string
+	for _, w := range features(q.Stems) {
+		if b.vocab[w] {
+			words = append(words, w)
+		}
+	}
+	if len(words) == 0 {
+		return nil
+	}
+
+	logp := make(map[Intent]float64, len(b.counts))
+	best := math.Inf(-1)
+	for in, counts := range b.counts {
+		lp := 0.0
+		denom := float64(b.totals[in] + len(b.vocab))
+		for _, w := range words {
+			lp += math.Log(float64(counts[w]+1) / denom)
+		}
+		logp[in] = lp
+		best = max(best, lp)
+	}
+	sum := 0.0
+	for in, lp := range logp {
+		logp[in] = math.Exp(lp - best)
+		sum += logp[in]
+	}
+	out := make(map[Intent]float64, len(logp))
+	for in, p := range logp {
+		out[in] = p / sum
+	}
+	return out
+}
+
+// features - основы, по которым учится модель: без чисел и однобуквенных слов
+func features(stems []string) []string {
+	out := stems[:0:0]
+	for _, s := range stems {
+		r := []rune(s)
+		if len(r) < 2 || unicode.IsDigit(r[0]) {
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
@@ -0,0 +1,235 @@
+// Package intent - офлайн-классификатор свободного текста: какой раздел бота нужен пользователю.
+//
+// Оценка каждого намерения — взвешенная сумма оценок нескольких Scorer: правил (ключевые слова
+// и найденные сущности) и наивного байесовского классификатора, обученного на фразах из train.txt.
+// Сущности (корпус, факультет, группа, фамилия преподавателя, тип места) ищутся по справочнику
+// Gazetteer, который заполняет вызывающий: целыми словами, поэтому «к» не находит «Корпус 2».
+package intent
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/Karielka/Hackaton_MAX/internal/text"
+)
+
+// Intent - раздел бота, в который ведёт текст
+type Intent string
+
+const (
+	Teacher   Intent = "teacher"   // поиск преподавателя
+	Dean      Intent = "dean"      // деканат
+	Campus    Intent = "campus"    // корпус: адрес, карта
+	Places    Intent = "places"    // столовые, буфеты, копирки
+	OpenNow   Intent = "open_now"  // что открыто прямо сейчас
+	Timetable Intent = "timetable" // расписание группы
+	Other     Intent = "other"     // ничего из перечисленного: болтовня, вопросы для FAQ
+)
+
+// Intents - намерения, между которыми выбирает классификатор (без Other)
+var Intents = []Intent{Teacher, Dean, Campus, Places, OpenNow, Timetable}
+
+// Пороги выбора
+const (
+	MinScore = 0.45 // ниже — текст не про разделы бота
+	Margin   = 0.15 // если второе намерение ближе — переспрашиваем
+)
+
+// Name - название из справочника
+type Name struct {
+	ID   uint
+	Text string
+}
+
+// Gazetteer - справочник сущностей. У корпуса может быть несколько записей с одним ID
+// (короткое и полное название). У преподавателей сравнивается фамилия — первое слово ФИО.
+type Gazetteer struct {
+	Campuses  []Name
+	Faculties []Name
+	Groups    []Name
+	Teachers  []Name
+}
+
+// Entities - сущности, найденные в тексте; нулевые значения — не найдено
+type Entities struct {
+	CampusID  uint
+	FacultyID uint
+	GroupID   uint
+	Teacher   string // фамилия из справочника («Иванов» для «у Иванова»)
+	PlaceType string // canteen | buffet | copy
+}
+
+// Query - разобранный текст
+type Query struct {
+	Text     string
+	Tokens   []string // значимые слова (text.Words)
+	Stems    []string // их основы
+	Entities Entities
+}
+
+// Scorer оценивает намерения текста от 0 до 1; отсутствующее в ответе намерение — 0
+type Scorer interface {
+	Score(q Query) map[Intent]float64
+}
+
+// Result - намерение и его итоговая оценка
+type Result struct {
+	Intent Intent
+	Score  float64
+}
+
+// Classifier складывает оценки подключённых Scorer с весами
+type Classifier struct {
+	scorers []weightedScorer
+}
+
+type weightedScorer struct {
+	s Scorer
+	w float64
+}
+
+// New - классификатор по умолчанию: правила (вес 0.6) и байесовская модель на train.txt (0.4)
+func New() (*Classifier, error) {
+	nb, err := TrainBayes(strings.NewReader(trainData))
+	if err != nil {
+		return nil, err
+	}
+	c := &Classifier{}
+	c.Add(Rules{}, 0.6)
+	c.Add(nb, 0.4)
+	return c, nil
+}
+
+// Add подключает ещё один источник оценок
+func (c *Classifier) Add(s Scorer, weight float64) {
+	c.scorers = append(c.scorers, weightedScorer{s, weight})
+}
+
+// Parse разбирает текст и ищет в нём сущности справочника
+func Parse(s string, g Gazetteer) Query {
+	q := Query{Text: s, Tokens: text.Words(s)}
+	q.Stems = make([]string, len(q.Tokens))
+	for i, t := range q.Tokens {
+		q.Stems[i] = text.Stem(t)
+	}
+	q.Entities = extract(q, g)
+	return q
+}
+
+// Classify - оценки всех намерений по убыванию
+func (c *Classifier) Classify(q Query) []Result {
+	// источник без оценок (модель не знает ни одного слова) в среднем не участвует
+	total := 0.0
+	sums := map[Intent]float64{}
+	for _, ws := range c.scorers {
+		scores := ws.s.Score(q)
+		if len(scores) == 0 {
+			continue
+		}
+		total += ws.w
+		for in, s := range scores {
+			sums[in] += ws.w * s
+		}
+	}
+	if total == 0 {
+		total = 1
+	}
+	out := make([]Result, len(Intents))
+	for i, in := range Intents {
+		out[i] = Result{Intent: in, Score: sums[in] / total}
+	}
+	slices.SortStableFunc(out, func(a, b Result) int { return cmp.Compare(b.Score, a.Score) })
+	return out
+}
+
+// Pick выбирает по отсортированным оценкам: пусто — уверенного намерения нет,
+// одно — ведём туда, несколько — оценки близки, нужно переспросить
+func Pick(results []Result) []Result {
+	if len(results) == 0 || results[0].Score < MinScore {
+		return nil
+	}
+	n := 1
+	for n < len(results) && results[n].Score >= MinScore && results[0].Score-results[n].Score < Margin {
+		n++
+	}
+	return results[:n]
+}
+
+// extract ищет сущности справочника целыми словами (по основам) и тип места по ключевым словам
+func extract(q Query, g Gazetteer) Entities {
+	var e Entities
+	e.CampusID = findName(q.Stems, g.Campuses, false)
+	e.FacultyID = findName(q.Stems, g.Faculties, false)
+	e.GroupID = findName(q.Stems, g.Groups, false)
+	if id := findName(q.Stems, g.Teachers, true); id != 0 {
+		for _, n := range g.Teachers {
+			if n.ID == id {
+				e.Teacher = strings.Fields(n.Text)[0]
+				break
+			}
+		}
+	}
+	e.PlaceType = placeType(q.Stems)
+	return e
+}
+
+// findName - ID названия с самым длинным совпадением; surname — сравнивать только первое слово
+func findName(textStems []string, names []Name, surname bool) uint {
+	var bestID uint
+	bestLen := 0
+	for _, n := range names {
+		ns := text.Keywords(n.Text)
+		if surname && len(ns) > 0 {
+			ns = ns[:1]
+		}
+		if len(ns) == 0 || len(ns) <= bestLen || !meaningful(ns) {
+			continue
+		}
+		if containsSeq(textStems, ns) {
+			bestID, bestLen = n.ID, len(ns)
+		}
+	}
+	return bestID
+}
+
+// meaningful - название из одного слова должно быть длиннее одной буквы (корпус «К» не ищем)
+func meaningful(ns []string) bool {
+	if len(ns) > 1 {
+		return true
+	}
+	r := []rune(ns[0])
+	return len(r) >= 2 || unicode.IsDigit(r[0])
+}
+
+func containsSeq(s, sub []string) bool {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		if slices.Equal(s[i:i+len(sub)], sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// placeType - тип места по словам текста
+func placeType(textStems []string) string {
+	kinds := []struct {
+		typ   string
+		words []string
+	}{
+		{"buffet", []string{"буфет"}},
+		{"copy", []string{"копир", "копиц", "ксерок", "печат", "распечат", "напечат", "принтер", "скан"}},
+		{"canteen", []string{"столов", "поес", "еда", "еды", "обед", "пообед", "перекус", "покуш"}},
+	}
+	for _, k := range kinds {
+		for _, s := range textStems {
+			for _, w := range k.words {
+				if strings.HasPrefix(s, w) {
+					return k.typ
+				}
+			}
+		}
+	}
+	return ""
+}
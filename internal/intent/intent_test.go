@@ -0,0 +1,97 @@
+package intent
+
+import (
+	"strings"
+	"testing"
+)
+
+// справочник как в демо-данных (internal/fixtures)
+var testGazetteer = Gazetteer{
+	Campuses:  []Name{{1, "ГУК"}, {1, "Главный учебный корпус"}, {2, "Корпус 2"}},
+	Faculties: []Name{{1, "ИУ"}, {2, "Э"}, {3, "РК"}},
+	Groups:    []Name{{10, "ИУ5-31Б"}, {11, "РК6-11Б"}},
+	Teachers: []Name{
+		{13, "Иванов Мария Владимировна"},
+		{15, "Сидорова Ольга Ивановна"},
+		{20, "Кузнецов Пётр Петрович"},
+	},
+}
+
+// Маршрутизация свободного текста: после переобучения на train.txt или правки правил
+// эти фразы должны вести туда же. Пустое намерение — уверенного раздела нет (FAQ или меню).
+func TestRouting(t *testing.T) {
+	c, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		text   string
+		intent Intent
+		want   Entities
+	}{
+		{"деканат ИУ", Dean, Entities{FacultyID: 1}},
+		{"когда работает деканат", Dean, Entities{}},
+		{"деканат рк", Dean, Entities{FacultyID: 3}},
+		{"у Иванова", Teacher, Entities{Teacher: "Иванов"}},
+		{"bdfyjd", Teacher, Entities{Teacher: "Иванов"}},
+		{"kuznetsov", Teacher, Entities{Teacher: "Кузнецов"}},
+		{"почта Сидоровой", Teacher, Entities{Teacher: "Сидорова"}},
+		{"где Кузнецов", Teacher, Entities{Teacher: "Кузнецов"}},
+		{"где сейчас поесть", OpenNow, Entities{PlaceType: "canteen"}},
+		{"где распечатать сейчас", OpenNow, Entities{PlaceType: "copy"}},
+		{"что открыто сейчас", OpenNow, Entities{}},
+		{"меню на завтра", Places, Entities{}},
+		{"какое меню завтра", Places, Entities{}},
+		{"меню на сегодня", Places, Entities{}},
+		{"что в столовой на завтра", Places, Entities{PlaceType: "canteen"}},
+		{"меню буфета", Places, Entities{PlaceType: "buffet"}},
+		{"столовка", Places, Entities{PlaceType: "canteen"}},
+		{"адрес корпуса 2", Campus, Entities{CampusID: 2}},
+		{"как пройти в ГУК", Campus, Entities{CampusID: 1}},
+		{"расписание ИУ5-31Б", Timetable, Entities{GroupID: 10, FacultyID: 1}},
+		{"пары на завтра", Timetable, Entities{}},
+		{"какие пары сегодня", Timetable, Entities{}},
+		{"привет", "", Entities{}},
+		{"спасибо", "", Entities{}},
+		{"к", "", Entities{}},
+		{"общежитие", "", Entities{}},
+		{"как получить справку", "", Entities{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			q := Parse(tt.text, testGazetteer)
+			results := c.Classify(q)
+			picked := Pick(results)
+			var got Intent
+			if len(picked) == 1 {
+				got = picked[0].Intent
+			}
+			if got != tt.intent {
+				t.Errorf("intent = %q, want %q (scores %v)", got, tt.intent, results[:2])
+			}
+			if q.Entities != tt.want {
+				t.Errorf("entities = %+v, want %+v", q.Entities, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrainData(t *testing.T) {
+	b, err := TrainBayes(strings.NewReader(trainData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, in := range append(Intents, Other) {
+		if b.totals[in] == 0 {
+			t.Errorf("no training phrases for %q", in)
+		}
+	}
+}
+
+func TestTrainBayesRejectsBadLines(t *testing.T) {
+	for _, data := range []string{"places столовая", "lunch | столовая"} {
+		if _, err := TrainBayes(strings.NewReader(data)); err == nil {
+			t.Errorf("TrainBayes(%q) accepted a bad line", data)
+		}
+	}
+}
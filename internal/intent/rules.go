@@ -0,0 +1,102 @@
+package intent
+
+import "strings"
+
+// Rules - оценка по ключевым словам и найденным сущностям. Каждое правило срабатывает
+// один раз; оценка намерения — сумма весов сработавших правил, не больше 1.
+type Rules struct{}
+
+// rule - слова и вес; слово с «*» на конце — префикс основы
+type rule struct {
+	words  []string
+	weight float64
+}
+
+var keywordRules = map[Intent][]rule{
+	Teacher: {
+		{[]string{"преподават*", "препод*", "преп"}, 0.6},
+		{[]string{"учител*", "лектор*", "профессор*", "доцент*", "семинарист*"}, 0.4},
+		{[]string{"кафедр*", "почт*", "емаил", "маил", "мейл*", "имейл*", "консультац*"}, 0.2},
+	},
+	Dean: {
+		{[]string{"деканат*"}, 0.8},
+		{[]string{"декан*", "замдекан*"}, 0.5},
+		{[]string{"факультет*", "справк*", "заявлен*"}, 0.2},
+	},
+	Campus: {
+		{[]string{"корпус*", "здани*"}, 0.5},
+		{[]string{"адрес*", "добрат*", "доехат*", "дойт*", "пройт*", "метро", "карт*", "находит*"}, 0.3},
+	},
+	Places: {
+		{[]string{"меню", "блюд*"}, 0.6},
+		{[]string{"цен", "стоит"}, 0.2},
+	},
+	OpenNow: {
+		{[]string{"сейчас", "открыт*", "работает", "ближайш*", "рядом"}, 0.4},
+	},
+	Timetable: {
+		{[]string{"расписан*"}, 0.5},
+		{[]string{"пар", "занят*", "урок*", "лекц*", "семинар"}, 0.4},
+		{[]string{"сегодн*", "завтр*", "недел*"}, 0.2},
+	},
+}
+
+func (Rules) Score(q Query) map[Intent]float64 {
+	out := map[Intent]float64{}
+	for in, rules := range keywordRules {
+		for _, r := range rules {
+			if anyWord(q, r.words) {
+				out[in] += r.weight
+			}
+		}
+	}
+
+	e := q.Entities
+	if e.Teacher != "" {
+		out[Teacher] += 0.8
+		// «расписание Иванова» — в карточке преподавателя, а не расписание группы
+		if e.GroupID == 0 {
+			out[Timetable] -= 0.3
+		}
+	}
+	if e.FacultyID != 0 {
+		out[Dean] += 0.4
+	}
+	if e.GroupID != 0 {
+		out[Timetable] += 0.6
+	}
+	if e.CampusID != 0 {
+		out[Campus] += 0.5
+	}
+	if e.PlaceType != "" {
+		out[Places] += 0.7
+		// «где сейчас поесть»: место + «сейчас/открыто»
+		if out[OpenNow] > 0 {
+			out[OpenNow] += 0.6
+			out[Places] -= 0.2
+		}
+		// «столовая в ГУК» — это про столовую, а не про корпус
+		out[Campus] -= 0.3
+	}
+
+	for in, s := range out {
+		out[in] = min(max(s, 0), 1)
+	}
+	return out
+}
+
+// anyWord - есть ли в тексте одно из слов: точное слово сравнивается и со словом текста, и с его основой
+func anyWord(q Query, words []string) bool {
+	for i, s := range q.Stems {
+		for _, w := range words {
+			if p, ok := strings.CutSuffix(w, "*"); ok {
+				if strings.HasPrefix(s, p) {
+					return true
+				}
+			} else if s == w || q.Tokens[i] == w {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,208 @@
+// Package media - картинки для сообщений бота: ссылка или локальный файл загружается в MAX
+// один раз, полученные токены хранятся в таблице media_uploads и дальше прикладываются сразу.
+//
+// Ключ кеша для ссылки — сама ссылка, для файла — хеш содержимого: заменили файл — загрузится заново.
+package media
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// MaxSize - самая большая картинка, которую скачиваем или читаем с диска
+const MaxSize = 10 << 20
+
+// Uploader загружает картинку в MAX (services.Messenger)
+type Uploader interface {
+	UploadPhoto(ctx context.Context, name string, data []byte) (*schemes.PhotoTokens, error)
+}
+
+// Library - источник картинок с кешем токенов
+type Library struct {
+	db   *gorm.DB
+	dir  string // откуда берутся локальные файлы
+	http *http.Client
+}
+
+// New - библиотека поверх db; относительные пути картинок ищутся в dir
+func New(db *gorm.DB, dir string) *Library {
+	return &Library{db: db, dir: dir, http: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Validate - src годится как источник картинки: ссылка http(s) или путь к файлу внутри
+// каталога картинок (без «..» и абсолютных путей)
+func Validate(src string) error {
+	if isURL(src) {
+		u, err := url.Parse(src)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("must be an http(s) URL or a file path")
+		}
+		return nil
+	}
+	if !filepath.IsLocal(src) {
+		return errors.New("file path must be relative and stay inside the media directory")
+	}
+	return nil
+}
+
+// Photo - вложение для src (ссылка или файл): токены из кеша, иначе загрузка через up.
+// Пустой src — нет картинки, (nil, nil).
+func (l *Library) Photo(ctx context.Context, up Uploader, src string) (*schemes.PhotoTokens, error) {
+	src = strings.TrimSpace(src)
+	if src == "" {
+		return nil, nil
+	}
+	if err := Validate(src); err != nil {
+		return nil, fmt.Errorf("bad image source %q: %w", src, err)
+	}
+
+	var key, name string
+	var data []byte
+	if isURL(src) {
+		key, name = "url:"+src, urlName(src)
+		if photo, err := l.cached(key); photo != nil || err != nil {
+			return photo, err
+		}
+		var err error
+		if data, err = l.fetch(ctx, src); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if data, err = l.read(src); err != nil {
+			return nil, err
+		}
+		key, name = fileKey(data), filepath.Base(src)
+		if photo, err := l.cached(key); photo != nil || err != nil {
+			return photo, err
+		}
+	}
+
+	photo, err := up.UploadPhoto(ctx, name, data)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(photo)
+	if err != nil {
+		return nil, err
+	}
+	row := models.MediaUpload{Source: key, Tokens: string(raw)}
+	err = l.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "source"}},
+		DoUpdates: clause.AssignmentColumns([]string{"tokens", "created_at"}),
+	}).Create(&row).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to save media tokens: %w", err)
+	}
+	return photo, nil
+}
+
+// Forget удаляет токены src из кеша — следующий Photo загрузит картинку заново
+// (например, MAX перестал принимать старые токены)
+func (l *Library) Forget(src string) error {
+	src = strings.TrimSpace(src)
+	key := "url:" + src
+	if !isURL(src) {
+		data, err := l.read(src)
+		if err != nil {
+			return err
+		}
+		key = fileKey(data)
+	}
+	if err := l.db.Where("source = ?", key).Delete(&models.MediaUpload{}).Error; err != nil {
+		return fmt.Errorf("failed to forget media tokens: %w", err)
+	}
+	return nil
+}
+
+func (l *Library) cached(key string) (*schemes.PhotoTokens, error) {
+	var row models.MediaUpload
+	err := l.db.Where("source = ?", key).Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch media tokens: %w", err)
+	}
+	var photo schemes.PhotoTokens
+	if err := json.Unmarshal([]byte(row.Tokens), &photo); err != nil || len(photo.Photos) == 0 {
+		return nil, nil // испорченная запись — загрузим заново, OnConflict её перезапишет
+	}
+	return &photo, nil
+}
+
+// fetch скачивает картинку по ссылке
+func (l *Library) fetch(ctx context.Context, src string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := l.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", src, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: http %d", src, resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "" && !strings.HasPrefix(ct, "image/") {
+		return nil, fmt.Errorf("failed to download %s: not an image (%s)", src, ct)
+	}
+	return readLimited(resp.Body, src)
+}
+
+// read читает локальный файл из каталога картинок
+func (l *Library) read(src string) ([]byte, error) {
+	f, err := os.Open(filepath.Join(l.dir, src))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image: %w", err)
+	}
+	defer f.Close()
+	return readLimited(f, src)
+}
+
+func readLimited(r io.Reader, src string) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", src, err)
+	}
+	if len(data) > MaxSize {
+		return nil, fmt.Errorf("image %s is larger than %d MB", src, MaxSize>>20)
+	}
+	return data, nil
+}
+
+// urlName - имя файла из ссылки, для загрузки
+func urlName(src string) string {
+	if u, err := url.Parse(src); err == nil {
+		if name := path.Base(u.Path); name != "." && name != "/" {
+			return name
+		}
+	}
+	return "image.jpg"
+}
+
+func isURL(src string) bool { return strings.Contains(src, "://") }
+
+func fileKey(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
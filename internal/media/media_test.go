@@ -0,0 +1,190 @@
+package media
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// fakeUploader выдаёт новый токен на каждую загрузку
+type fakeUploader struct {
+	names []string
+	err   error
+}
+
+func (u *fakeUploader) UploadPhoto(_ context.Context, name string, _ []byte) (*schemes.PhotoTokens, error) {
+	if u.err != nil {
+		return nil, u.err
+	}
+	u.names = append(u.names, name)
+	token := "photo." + strconv.Itoa(len(u.names))
+	return &schemes.PhotoTokens{Photos: map[string]schemes.PhotoToken{"1": {Token: token}}}, nil
+}
+
+func token(p *schemes.PhotoTokens) string {
+	if p == nil {
+		return ""
+	}
+	return p.Photos["1"].Token
+}
+
+func newLibrary(t *testing.T) (*Library, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(t.TempDir()+"/media.db"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.MediaUpload{}); err != nil {
+		t.Fatal(err)
+	}
+	return New(db, t.TempDir()), db
+}
+
+func sources(t *testing.T, db *gorm.DB) []string {
+	t.Helper()
+	var keys []string
+	if err := db.Model(&models.MediaUpload{}).Order("source").Pluck("source", &keys).Error; err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+func TestPhotoURL(t *testing.T) {
+	lib, db := newLibrary(t)
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		switch r.URL.Path {
+		case "/gz.png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte("png"))
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	up := &fakeUploader{}
+	src := srv.URL + "/gz.png"
+
+	for i := range 2 {
+		photo, err := lib.Photo(ctx, up, " "+src+" ")
+		if err != nil || token(photo) != "photo.1" {
+			t.Fatalf("call %d: %v, %v", i, token(photo), err)
+		}
+	}
+	if hits != 1 || len(up.names) != 1 || up.names[0] != "gz.png" {
+		t.Errorf("downloads %d, uploads %q; want one of gz.png", hits, up.names)
+	}
+	if got := sources(t, db); len(got) != 1 || got[0] != "url:"+src {
+		t.Errorf("cache keys %q, want url:%s", got, src)
+	}
+
+	// забытые токены — загрузка заново
+	if err := lib.Forget(src); err != nil {
+		t.Fatal(err)
+	}
+	if got := sources(t, db); len(got) != 0 {
+		t.Fatalf("after Forget: %q", got)
+	}
+	if photo, err := lib.Photo(ctx, up, src); err != nil || token(photo) != "photo.2" {
+		t.Errorf("after Forget: %v, %v", token(photo), err)
+	}
+
+	for _, bad := range []string{srv.URL + "/page.html", srv.URL + "/missing.png"} {
+		if _, err := lib.Photo(ctx, up, bad); err == nil {
+			t.Errorf("%s: want an error", bad)
+		}
+	}
+	// неудачная загрузка ничего не кеширует
+	failing := &fakeUploader{err: errors.New("upload failed")}
+	if _, err := lib.Photo(ctx, failing, srv.URL+"/gz.png?v=2"); err == nil {
+		t.Errorf("upload error is lost")
+	}
+	if got := sources(t, db); len(got) != 1 {
+		t.Errorf("cache after failed upload: %q", got)
+	}
+}
+
+func TestPhotoFile(t *testing.T) {
+	lib, db := newLibrary(t)
+	ctx := context.Background()
+	up := &fakeUploader{}
+	write := func(name, data string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(lib.dir, name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(lib.dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sum := func(data string) string {
+		h := sha256.Sum256([]byte(data))
+		return "sha256:" + hex.EncodeToString(h[:])
+	}
+
+	write("campus/gz.jpg", "v1")
+	write("copy.jpg", "v1")
+	// ключ — содержимое: тот же файл под другим именем из кеша
+	for _, src := range []string{"campus/gz.jpg", "copy.jpg"} {
+		if photo, err := lib.Photo(ctx, up, src); err != nil || token(photo) != "photo.1" {
+			t.Fatalf("%s: %v, %v", src, token(photo), err)
+		}
+	}
+	if got := sources(t, db); len(got) != 1 || got[0] != sum("v1") {
+		t.Errorf("cache keys %q, want %s", got, sum("v1"))
+	}
+	if len(up.names) != 1 || up.names[0] != "gz.jpg" {
+		t.Errorf("uploads %q", up.names)
+	}
+
+	// заменили файл — загрузка заново
+	write("campus/gz.jpg", "v2")
+	if photo, err := lib.Photo(ctx, up, "campus/gz.jpg"); err != nil || token(photo) != "photo.2" {
+		t.Fatalf("changed file: %v, %v", token(photo), err)
+	}
+	if err := lib.Forget("campus/gz.jpg"); err != nil {
+		t.Fatal(err)
+	}
+	if got := sources(t, db); len(got) != 1 || got[0] != sum("v1") {
+		t.Errorf("after Forget: %q, want only %s", got, sum("v1"))
+	}
+
+	// испорченная запись в кеше — загрузка заново поверх неё
+	if err := db.Model(&models.MediaUpload{}).Where("source = ?", sum("v1")).Update("tokens", "{").Error; err != nil {
+		t.Fatal(err)
+	}
+	if photo, err := lib.Photo(ctx, up, "copy.jpg"); err != nil || token(photo) != "photo.3" {
+		t.Errorf("broken cache row: %v, %v", token(photo), err)
+	}
+	if photo, err := lib.Photo(ctx, up, "copy.jpg"); err != nil || token(photo) != "photo.3" {
+		t.Errorf("rewritten cache row: %v, %v", token(photo), err)
+	}
+
+	if photo, err := lib.Photo(ctx, up, ""); photo != nil || err != nil {
+		t.Errorf("empty source: %v, %v", photo, err)
+	}
+	for _, bad := range []string{"../secret.jpg", "/etc/passwd", "missing.jpg", "ftp://host/a.jpg"} {
+		if _, err := lib.Photo(ctx, up, bad); err == nil {
+			t.Errorf("%s: want an error", bad)
+		}
+	}
+}
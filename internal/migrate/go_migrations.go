@@ -0,0 +1,75 @@
+package migrate
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// goMigrations - миграции, которые проще написать на Go, чем на SQL
+var goMigrations = []Migration{
+	{
+		// Старый текстовый teachers.schedule -> lessons, нераспознанное -> legacy_schedules. Откат ничего не делает:
+		// занятия остаются в lessons, а вернуть колонку в прежнем виде нельзя.
+		Version: 2,
+		Name:    "legacy_schedules",
+		Up:      models.MigrateLegacySchedules,
+		Down:    func(*gorm.DB) error { return nil },
+	},
+	{
+		// Текстовый режим работы мест и деканатов -> opening_hours. Текст остаётся подписью,
+		// поэтому откатывать нечего: таблицу удаляет откат 0004.
+		Version: 5,
+		Name:    "text_hours",
+		Up:      models.MigrateTextHours,
+		Down:    func(*gorm.DB) error { return nil },
+	},
+	{
+		// 0001 создаёт таблицы с IF NOT EXISTS, поэтому в базах от старого AutoMigrate
+		// у teachers и departments не появились поздние колонки. Добавляем недостающие;
+		// откат их не удаляет — в свежей базе они из 0001.
+		Version: 12,
+		Name:    "legacy_columns",
+		Up:      addLegacyColumns,
+		Down:    func(*gorm.DB) error { return nil },
+	},
+}
+
+// Колонки, которых нет в схеме старого AutoMigrate. Структуры — снимок на момент
+// миграции, а не текущие модели: поля моделей могут меняться дальше.
+type legacyTeacher struct {
+	OfficeRoom  string
+	Phone       string
+	OfficeHours string
+}
+
+func (legacyTeacher) TableName() string { return "teachers" }
+
+type legacyDepartment struct {
+	Office string
+}
+
+func (legacyDepartment) TableName() string { return "departments" }
+
+func addLegacyColumns(tx *gorm.DB) error {
+	for _, c := range []struct {
+		table  string
+		model  any
+		fields []string
+	}{
+		{"teachers", &legacyTeacher{}, []string{"OfficeRoom", "Phone", "OfficeHours"}},
+		{"departments", &legacyDepartment{}, []string{"Office"}},
+	} {
+		for _, f := range c.fields {
+			if tx.Migrator().HasColumn(c.model, f) {
+				continue
+			}
+			if err := tx.Migrator().AddColumn(c.model, f); err != nil {
+				return fmt.Errorf("failed to add %s column %s: %w", c.table, f, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+// Package migrate - версионные обратимые миграции схемы.
+//
+// Миграция — пара файлов sql/NNNN_name.up.sql и sql/NNNN_name.down.sql или Go-функции
+// из goMigrations (перенос данных, который не выразить одним SQL). Применённые версии
+// записываются в schema_migrations; каждая миграция идёт в своей транзакции.
+package migrate
+
+import (
+	"cmp"
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed sql/*.sql
+var sqlFiles embed.FS
+
+// Migration - одна версия схемы
+type Migration struct {
+	Version int64
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// Status - миграция и момент применения (nil — ещё не применена)
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// schemaMigration - строка schema_migrations
+type schemaMigration struct {
+	Version   int64 `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string { return "schema_migrations" }
+
+// ключ pg_advisory_xact_lock: два экземпляра бота не применяют миграции одновременно
+const lockKey = 7_305_021
+
+// Migrator применяет и откатывает миграции
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration // по возрастанию версии
+}
+
+// New - мигратор со всеми миграциями пакета
+func New(db *gorm.DB) (*Migrator, error) {
+	list, err := loadSQL(sqlFiles, "sql")
+	if err != nil {
+		return nil, err
+	}
+	return newMigrator(db, append(list, goMigrations...))
+}
+
+func newMigrator(db *gorm.DB, list []Migration) (*Migrator, error) {
+	slices.SortFunc(list, func(a, b Migration) int { return cmp.Compare(a.Version, b.Version) })
+	for i := 1; i < len(list); i++ {
+		if list[i].Version == list[i-1].Version {
+			return nil, fmt.Errorf("duplicate migration version %d (%s, %s)", list[i].Version, list[i-1].Name, list[i].Name)
+		}
+	}
+	return &Migrator{db: db, migrations: list}, nil
+}
+
+// Latest - последняя известная версия
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Status - все миграции и какие из них применены. Только читает: в базе без
+// schema_migrations все миграции считаются неприменёнными.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(m.db.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Status, len(m.migrations))
+	for i, mg := range m.migrations {
+		out[i] = Status{Migration: mg}
+		if row, ok := applied[mg.Version]; ok {
+			at := row.AppliedAt
+			out[i].AppliedAt = &at
+		}
+	}
+	return out, nil
+}
+
+// Up применяет все неприменённые миграции; возвращает применённые
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	return m.To(ctx, m.Latest())
+}
+
+// Down откатывает steps последних применённых миграций
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	applied, err := m.applied(m.db.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]int64, 0, len(applied))
+	for v := range applied {
+		versions = append(versions, v)
+	}
+	slices.Sort(versions)
+	if steps <= 0 || len(versions) == 0 {
+		return nil, nil
+	}
+	target := int64(0)
+	if steps < len(versions) {
+		target = versions[len(versions)-steps-1]
+	}
+	return m.To(ctx, target)
+}
+
+// To приводит схему к версии target: применяет недостающие миграции до неё
+// и откатывает применённые после неё (0 — откатить всё)
+func (m *Migrator) To(ctx context.Context, target int64) ([]Migration, error) {
+	if target != 0 && !slices.ContainsFunc(m.migrations, func(mg Migration) bool { return mg.Version == target }) {
+		return nil, fmt.Errorf("unknown migration version %d", target)
+	}
+	if err := m.db.WithContext(ctx).AutoMigrate(&schemaMigration{}); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	applied, err := m.applied(m.db.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	for v := range applied {
+		if !slices.ContainsFunc(m.migrations, func(mg Migration) bool { return mg.Version == v }) {
+			return nil, fmt.Errorf("database has migration %d that this build does not know; update the app first", v)
+		}
+	}
+
+	var done []Migration
+	for _, mg := range m.migrations {
+		if _, ok := applied[mg.Version]; ok || mg.Version > target {
+			continue
+		}
+		if err := m.run(ctx, mg, true); err != nil {
+			return done, err
+		}
+		done = append(done, mg)
+	}
+	for _, mg := range slices.Backward(m.migrations) {
+		if _, ok := applied[mg.Version]; !ok || mg.Version <= target {
+			continue
+		}
+		if err := m.run(ctx, mg, false); err != nil {
+			return done, err
+		}
+		done = append(done, mg)
+	}
+	return done, nil
+}
+
+// run применяет (up) или откатывает миграцию в одной транзакции вместе с записью в schema_migrations
+func (m *Migrator) run(ctx context.Context, mg Migration, up bool) error {
+	dir := "down"
+	if up {
+		dir = "up"
+	}
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if tx.Dialector.Name() == "postgres" {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", lockKey).Error; err != nil {
+				return err
+			}
+		}
+		// под блокировкой перечитываем: другой экземпляр мог успеть раньше
+		var n int64
+		if err := tx.Model(&schemaMigration{}).Where("version = ?", mg.Version).Count(&n).Error; err != nil {
+			return err
+		}
+		if (n > 0) == up {
+			return nil
+		}
+		if up {
+			if err := mg.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{Version: mg.Version, Name: mg.Name, AppliedAt: time.Now()}).Error
+		}
+		if err := mg.Down(tx); err != nil {
+			return err
+		}
+		return tx.Delete(&schemaMigration{}, mg.Version).Error
+	})
+	if err != nil {
+		return fmt.Errorf("migration %d_%s %s: %w", mg.Version, mg.Name, dir, err)
+	}
+	return nil
+}
+
+// applied - применённые версии; пусто, если schema_migrations ещё нет (её создаёт To)
+func (m *Migrator) applied(db *gorm.DB) (map[int64]schemaMigration, error) {
+	if !db.Migrator().HasTable(&schemaMigration{}) {
+		return map[int64]schemaMigration{}, nil
+	}
+	var rows []schemaMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	out := make(map[int64]schemaMigration, len(rows))
+	for _, r := range rows {
+		out[r.Version] = r
+	}
+	return out, nil
+}
+
+// loadSQL читает пары NNNN_name.up.sql / NNNN_name.down.sql из каталога dir
+func loadSQL(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+	type pair struct {
+		name     string
+		up, down string
+	}
+	pairs := map[int64]*pair{}
+	for _, e := range entries {
+		base, dirn, ok := strings.Cut(strings.TrimSuffix(e.Name(), ".sql"), ".")
+		if !ok || (dirn != "up" && dirn != "down") || !strings.HasSuffix(e.Name(), ".sql") {
+			return nil, fmt.Errorf("bad migration file name %q (want NNNN_name.up.sql or .down.sql)", e.Name())
+		}
+		num, name, _ := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(num, 10, 64)
+		if err != nil || version <= 0 || name == "" {
+			return nil, fmt.Errorf("bad migration file name %q (want NNNN_name.up.sql or .down.sql)", e.Name())
+		}
+		body, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		p := pairs[version]
+		if p == nil {
+			p = &pair{name: name}
+			pairs[version] = p
+		}
+		if p.name != name {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, p.name, name)
+		}
+		if dirn == "up" {
+			p.up = string(body)
+		} else {
+			p.down = string(body)
+		}
+	}
+
+	var out []Migration
+	for version, p := range pairs {
+		if strings.TrimSpace(p.up) == "" || strings.TrimSpace(p.down) == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both .up.sql and .down.sql", version, p.name)
+		}
+		out = append(out, Migration{Version: version, Name: p.name, Up: execSQL(p.up), Down: execSQL(p.down)})
+	}
+	return out, nil
+}
+
+func execSQL(query string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error { return tx.Exec(query).Error }
+}
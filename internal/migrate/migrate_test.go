@@ -0,0 +1,227 @@
+package migrate
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(t.TempDir()+"/migrate.db"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func testMigrations() []Migration {
+	return []Migration{
+		{Version: 2, Name: "b", Up: execSQL("CREATE TABLE b (id integer)"), Down: execSQL("DROP TABLE b")},
+		{Version: 1, Name: "a", Up: execSQL("CREATE TABLE a (id integer)"), Down: execSQL("DROP TABLE a")},
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	m, err := New(openDB(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, mg := range m.migrations {
+		if mg.Version != int64(i+1) {
+			t.Errorf("migration #%d has version %d, want %d (gap or duplicate)", i, mg.Version, i+1)
+		}
+	}
+}
+
+func TestStatusIsReadOnly(t *testing.T) {
+	db := openDB(t)
+	m, err := newMigrator(db, testMigrations())
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, err := m.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].AppliedAt != nil || list[1].AppliedAt != nil {
+		t.Errorf("status of an empty database = %+v", list)
+	}
+	if db.Migrator().HasTable(&schemaMigration{}) {
+		t.Error("Status created schema_migrations")
+	}
+	if done, err := m.Down(context.Background(), 1); err != nil || len(done) != 0 {
+		t.Errorf("Down on an empty database = %v, %v", done, err)
+	}
+}
+
+func TestUpDown(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	m, err := newMigrator(db, testMigrations())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done, err := m.Up(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 2 || done[0].Version != 1 || done[1].Version != 2 {
+		t.Fatalf("Up applied %+v, want 1 then 2", done)
+	}
+	if !db.Migrator().HasTable("a") || !db.Migrator().HasTable("b") {
+		t.Fatal("tables not created")
+	}
+	if done, err := m.Up(ctx); err != nil || len(done) != 0 {
+		t.Errorf("second Up = %v, %v; want nothing to do", done, err)
+	}
+
+	done, err = m.Down(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 1 || done[0].Version != 2 || db.Migrator().HasTable("b") || !db.Migrator().HasTable("a") {
+		t.Errorf("Down(1) = %+v", done)
+	}
+	list, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list[0].AppliedAt == nil || list[1].AppliedAt != nil {
+		t.Errorf("status after Down(1) = %+v", list)
+	}
+
+	// версия из будущей сборки
+	old, err := newMigrator(db, testMigrations()[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := old.Up(ctx); err == nil || !strings.Contains(err.Error(), "does not know") {
+		t.Errorf("older build Up error = %v", err)
+	}
+}
+
+// Схема старого AutoMigrate (до версионных миграций): те же таблицы, но без поздних колонок
+type baselineDepartment struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"uniqueIndex;not null"`
+	FacultyID uint
+}
+
+func (baselineDepartment) TableName() string { return "departments" }
+
+type baselineTeacher struct {
+	ID           uint   `gorm:"primaryKey"`
+	FullName     string `gorm:"index;not null"`
+	Email        string `gorm:"index"`
+	Subject      string `gorm:"index"`
+	DepartmentID uint
+	Schedule     string `gorm:"type:text"`
+}
+
+func (baselineTeacher) TableName() string { return "teachers" }
+
+func TestUpgradeFromAutoMigrate(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	if err := db.AutoMigrate(&baselineDepartment{}, &baselineTeacher{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&baselineTeacher{FullName: "Иванов Иван Иванович", Schedule: "Пн 10:15–11:50; Аудитория: А-101"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// SQL-миграции после 0001 рассчитаны на Postgres; здесь — базовая схема и Go-миграции
+	all, err := New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list []Migration
+	for _, mg := range all.migrations {
+		if mg.Version == 1 || mg.Name == "legacy_schedules" || mg.Name == "legacy_columns" {
+			list = append(list, mg)
+		}
+	}
+	m, err := newMigrator(db, list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	for table, cols := range map[string][]string{
+		"teachers":    {"office_room", "phone", "office_hours"},
+		"departments": {"office"},
+	} {
+		for _, col := range cols {
+			if !db.Migrator().HasColumn(table, col) {
+				t.Errorf("%s.%s is missing after upgrade", table, col)
+			}
+		}
+	}
+	var room string
+	if err := db.Table("teachers").Where("full_name = ?", "Иванов Иван Иванович").
+		Update("office_room", "А-310").Error; err != nil {
+		t.Fatal(err)
+	}
+	db.Table("teachers").Select("office_room").Row().Scan(&room)
+	if room != "А-310" {
+		t.Errorf("office_room = %q", room)
+	}
+
+	if db.Migrator().HasColumn("teachers", "schedule") {
+		t.Error("teachers.schedule not migrated")
+	}
+
+	// на свежей схеме колонки уже есть — миграция ничего не делает
+	if err := addLegacyColumns(db); err != nil {
+		t.Errorf("second run: %v", err)
+	}
+}
+
+func TestLoadSQL(t *testing.T) {
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+		want  string // подстрока ошибки; пусто — без ошибки
+	}{
+		{"ok", fstest.MapFS{
+			"sql/0001_init.up.sql":   {Data: []byte("CREATE TABLE x (id int)")},
+			"sql/0001_init.down.sql": {Data: []byte("DROP TABLE x")},
+		}, ""},
+		{"no down", fstest.MapFS{
+			"sql/0001_init.up.sql": {Data: []byte("CREATE TABLE x (id int)")},
+		}, "needs both"},
+		{"bad name", fstest.MapFS{
+			"sql/init.up.sql": {Data: []byte("SELECT 1")},
+		}, "bad migration file name"},
+		{"two names", fstest.MapFS{
+			"sql/0001_init.up.sql":  {Data: []byte("SELECT 1")},
+			"sql/0001_other.up.sql": {Data: []byte("SELECT 1")},
+		}, "two names"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadSQL(tt.files, "sql")
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+	if _, err := newMigrator(nil, append(testMigrations(), Migration{Version: 1, Name: "dup"})); err == nil {
+		t.Error("duplicate version accepted")
+	}
+}
@@ -0,0 +1,16 @@
+DROP TABLE IF EXISTS admins;
+DROP TABLE IF EXISTS user_favorites;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS lesson_groups;
+DROP TABLE IF EXISTS lessons;
+DROP TABLE IF EXISTS "groups";
+DROP TABLE IF EXISTS semesters;
+DROP TABLE IF EXISTS dialog_states;
+DROP TABLE IF EXISTS faqs;
+DROP TABLE IF EXISTS places;
+DROP TABLE IF EXISTS campus;
+DROP TABLE IF EXISTS dean_offices;
+DROP TABLE IF EXISTS teachers;
+DROP TABLE IF EXISTS departments;
+DROP TABLE IF EXISTS faculties;
+DROP TABLE IF EXISTS institutes;
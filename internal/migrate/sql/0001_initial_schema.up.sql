@@ -0,0 +1,193 @@
+-- Схема на момент перехода с AutoMigrate на версионные миграции.
+-- IF NOT EXISTS: базы, созданные AutoMigrate, принимают эту версию без изменений.
+
+CREATE TABLE IF NOT EXISTS institutes (
+    id   bigserial PRIMARY KEY,
+    name text NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_institutes_name ON institutes (name);
+
+CREATE TABLE IF NOT EXISTS faculties (
+    id           bigserial PRIMARY KEY,
+    name         text NOT NULL,
+    institute_id bigint,
+    CONSTRAINT fk_faculties_institute FOREIGN KEY (institute_id)
+        REFERENCES institutes (id) ON UPDATE CASCADE ON DELETE SET NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_faculties_name ON faculties (name);
+
+CREATE TABLE IF NOT EXISTS departments (
+    id         bigserial PRIMARY KEY,
+    name       text NOT NULL,
+    faculty_id bigint,
+    office     text,
+    CONSTRAINT fk_departments_faculty FOREIGN KEY (faculty_id)
+        REFERENCES faculties (id) ON UPDATE CASCADE ON DELETE SET NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_departments_name ON departments (name);
+
+CREATE TABLE IF NOT EXISTS teachers (
+    id            bigserial PRIMARY KEY,
+    full_name     text NOT NULL,
+    email         text,
+    subject       text,
+    department_id bigint,
+    office_room   text,
+    phone         text,
+    office_hours  text,
+    CONSTRAINT fk_teachers_department FOREIGN KEY (department_id)
+        REFERENCES departments (id) ON UPDATE CASCADE ON DELETE SET NULL
+);
+CREATE INDEX IF NOT EXISTS idx_teachers_full_name ON teachers (full_name);
+CREATE INDEX IF NOT EXISTS idx_teachers_email ON teachers (email);
+CREATE INDEX IF NOT EXISTS idx_teachers_subject ON teachers (subject);
+
+CREATE TABLE IF NOT EXISTS dean_offices (
+    id         bigserial PRIMARY KEY,
+    faculty_id bigint,
+    schedule   text,
+    docs_link  text,
+    contacts   text
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_dean_offices_faculty_id ON dean_offices (faculty_id);
+
+CREATE TABLE IF NOT EXISTS campus (
+    id            bigserial PRIMARY KEY,
+    short_name    text NOT NULL,
+    full_name     text NOT NULL,
+    address       text,
+    metro         text,
+    image_url     text,
+    map_image_url text,
+    description   text,
+    institute_id  bigint
+);
+CREATE INDEX IF NOT EXISTS idx_campus_short_name ON campus (short_name);
+CREATE INDEX IF NOT EXISTS idx_campus_full_name ON campus (full_name);
+
+CREATE TABLE IF NOT EXISTS places (
+    id         bigserial PRIMARY KEY,
+    campus_id  bigint,
+    type       text,
+    name       text,
+    location   text,
+    schedule   text,
+    menu_url   text,
+    menu_today text
+);
+CREATE INDEX IF NOT EXISTS idx_places_campus_id ON places (campus_id);
+CREATE INDEX IF NOT EXISTS idx_places_type ON places (type);
+
+CREATE TABLE IF NOT EXISTS faqs (
+    id       bigserial PRIMARY KEY,
+    question text,
+    answer   text
+);
+CREATE INDEX IF NOT EXISTS idx_faqs_question ON faqs (question);
+
+CREATE TABLE IF NOT EXISTS dialog_states (
+    peer       bigint PRIMARY KEY,
+    state      text NOT NULL,
+    data       text,
+    expires_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_dialog_states_expires_at ON dialog_states (expires_at);
+
+CREATE TABLE IF NOT EXISTS semesters (
+    id         bigserial PRIMARY KEY,
+    name       text NOT NULL,
+    start_date date NOT NULL,
+    end_date   date NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_semesters_name ON semesters (name);
+
+CREATE TABLE IF NOT EXISTS "groups" (
+    id   bigserial PRIMARY KEY,
+    name text NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_groups_name ON "groups" (name);
+
+CREATE TABLE IF NOT EXISTS lessons (
+    id           bigserial PRIMARY KEY,
+    teacher_id   bigint NOT NULL,
+    semester_id  bigint,
+    weekday      bigint NOT NULL,
+    start_minute bigint NOT NULL,
+    end_minute   bigint NOT NULL,
+    week_parity  text,
+    room         text,
+    campus_id    bigint,
+    subject      text NOT NULL,
+    lesson_type  text,
+    CONSTRAINT fk_lessons_teacher FOREIGN KEY (teacher_id)
+        REFERENCES teachers (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_lessons_semester FOREIGN KEY (semester_id)
+        REFERENCES semesters (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    CONSTRAINT fk_lessons_campus FOREIGN KEY (campus_id)
+        REFERENCES campus (id) ON UPDATE CASCADE ON DELETE SET NULL
+);
+CREATE INDEX IF NOT EXISTS idx_lessons_teacher_id ON lessons (teacher_id);
+CREATE INDEX IF NOT EXISTS idx_lessons_semester_id ON lessons (semester_id);
+CREATE INDEX IF NOT EXISTS idx_lessons_campus_id ON lessons (campus_id);
+
+CREATE TABLE IF NOT EXISTS lesson_groups (
+    lesson_id bigint NOT NULL,
+    group_id  bigint NOT NULL,
+    PRIMARY KEY (lesson_id, group_id),
+    CONSTRAINT fk_lesson_groups_lesson FOREIGN KEY (lesson_id)
+        REFERENCES lessons (id) ON DELETE CASCADE,
+    CONSTRAINT fk_lesson_groups_group FOREIGN KEY (group_id)
+        REFERENCES "groups" (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    id            bigint PRIMARY KEY,
+    display_name  text,
+    faculty_id    bigint,
+    department_id bigint,
+    group_id      bigint,
+    campus_id     bigint,
+    study_form    text,
+    course        bigint,
+    language      text DEFAULT 'ru',
+    created_at    timestamptz,
+    CONSTRAINT fk_users_faculty FOREIGN KEY (faculty_id)
+        REFERENCES faculties (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    CONSTRAINT fk_users_department FOREIGN KEY (department_id)
+        REFERENCES departments (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    CONSTRAINT fk_users_group FOREIGN KEY (group_id)
+        REFERENCES "groups" (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    CONSTRAINT fk_users_campus FOREIGN KEY (campus_id)
+        REFERENCES campus (id) ON UPDATE CASCADE ON DELETE SET NULL
+);
+CREATE INDEX IF NOT EXISTS idx_users_faculty_id ON users (faculty_id);
+CREATE INDEX IF NOT EXISTS idx_users_department_id ON users (department_id);
+CREATE INDEX IF NOT EXISTS idx_users_group_id ON users (group_id);
+
+CREATE TABLE IF NOT EXISTS user_favorites (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint NOT NULL,
+    teacher_id bigint,
+    campus_id  bigint,
+    place_id   bigint,
+    faculty_id bigint,
+    created_at timestamptz,
+    CONSTRAINT fk_user_favorites_teacher FOREIGN KEY (teacher_id)
+        REFERENCES teachers (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_user_favorites_campus FOREIGN KEY (campus_id)
+        REFERENCES campus (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_user_favorites_place FOREIGN KEY (place_id)
+        REFERENCES places (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_user_favorites_faculty FOREIGN KEY (faculty_id)
+        REFERENCES faculties (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_fav_teacher ON user_favorites (user_id, teacher_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_fav_campus ON user_favorites (user_id, campus_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_fav_place ON user_favorites (user_id, place_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_fav_faculty ON user_favorites (user_id, faculty_id);
+
+CREATE TABLE IF NOT EXISTS admins (
+    user_id    bigint PRIMARY KEY,
+    note       text,
+    created_at timestamptz
+);
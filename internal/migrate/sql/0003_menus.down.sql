@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS dishes;
+DROP TABLE IF EXISTS menus;
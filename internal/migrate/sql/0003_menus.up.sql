@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS menus (
+    id       bigserial PRIMARY KEY,
+    place_id bigint NOT NULL,
+    date     date NOT NULL,
+    CONSTRAINT fk_menus_place FOREIGN KEY (place_id)
+        REFERENCES places (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_menus_place_date ON menus (place_id, date);
+
+CREATE TABLE IF NOT EXISTS dishes (
+    id        bigserial PRIMARY KEY,
+    menu_id   bigint NOT NULL,
+    position  bigint NOT NULL,
+    category  text,
+    name      text NOT NULL,
+    price_kop bigint NOT NULL,
+    weight    text,
+    allergens text,
+    CONSTRAINT fk_menus_dishes FOREIGN KEY (menu_id)
+        REFERENCES menus (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_dishes_menu_id ON dishes (menu_id);
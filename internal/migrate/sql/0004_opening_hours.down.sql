@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS hours_exceptions;
+DROP TABLE IF EXISTS opening_hours;
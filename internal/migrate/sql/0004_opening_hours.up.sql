@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS opening_hours (
+    id             bigserial PRIMARY KEY,
+    place_id       bigint,
+    dean_office_id bigint,
+    weekday        bigint NOT NULL,
+    open_minute    bigint NOT NULL,
+    close_minute   bigint NOT NULL,
+    CONSTRAINT fk_opening_hours_place FOREIGN KEY (place_id)
+        REFERENCES places (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_opening_hours_dean_office FOREIGN KEY (dean_office_id)
+        REFERENCES dean_offices (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_opening_hours_place_id ON opening_hours (place_id);
+CREATE INDEX IF NOT EXISTS idx_opening_hours_dean_office_id ON opening_hours (dean_office_id);
+
+CREATE TABLE IF NOT EXISTS hours_exceptions (
+    id             bigserial PRIMARY KEY,
+    place_id       bigint,
+    dean_office_id bigint,
+    date           date NOT NULL,
+    closed         boolean NOT NULL,
+    open_minute    bigint,
+    close_minute   bigint,
+    note           text,
+    CONSTRAINT fk_hours_exceptions_place FOREIGN KEY (place_id)
+        REFERENCES places (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_hours_exceptions_dean_office FOREIGN KEY (dean_office_id)
+        REFERENCES dean_offices (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_hours_exceptions_place_id ON hours_exceptions (place_id);
+CREATE INDEX IF NOT EXISTS idx_hours_exceptions_dean_office_id ON hours_exceptions (dean_office_id);
+CREATE INDEX IF NOT EXISTS idx_hours_exceptions_date ON hours_exceptions (date);
@@ -0,0 +1,2 @@
+ALTER TABLE campus DROP COLUMN longitude;
+ALTER TABLE campus DROP COLUMN latitude;
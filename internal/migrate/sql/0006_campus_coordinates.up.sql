@@ -0,0 +1,2 @@
+ALTER TABLE campus ADD COLUMN latitude double precision;
+ALTER TABLE campus ADD COLUMN longitude double precision;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_faqs_category_id;
+ALTER TABLE faqs DROP COLUMN category_id;
+DROP TABLE IF EXISTS faq_categories;
@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS faq_categories (
+    id       bigserial PRIMARY KEY,
+    name     text NOT NULL,
+    position bigint NOT NULL DEFAULT 0
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_faq_categories_name ON faq_categories (name);
+
+ALTER TABLE faqs ADD COLUMN category_id bigint
+    CONSTRAINT fk_faqs_category REFERENCES faq_categories (id) ON UPDATE CASCADE ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_faqs_category_id ON faqs (category_id);
+
+INSERT INTO faq_categories (name, position)
+SELECT v.name, v.position
+FROM (SELECT 'Академические вопросы' AS name, 1 AS position
+      UNION ALL SELECT 'Документы и справки', 2
+      UNION ALL SELECT 'Общежитие', 3
+      UNION ALL SELECT 'Стипендия', 4
+      UNION ALL SELECT 'Другое', 5) AS v
+WHERE NOT EXISTS (SELECT 1 FROM faq_categories c WHERE c.name = v.name);
+
+-- вопросы, которые раньше были зашиты в код бота
+INSERT INTO faqs (question, answer, category_id)
+SELECT v.question, v.answer, (SELECT id FROM faq_categories WHERE name = v.category)
+FROM (SELECT 'Как найти преподавателя?' AS question,
+             'Нажмите «Поиск препода» в главном меню и выберите способ поиска: по кафедре, факультету или ФИО. Бот покажет контакты и расписание преподавателя.' AS answer,
+             'Академические вопросы' AS category
+      UNION ALL SELECT 'Как узнать расписание занятий?',
+             'Расписание преподавателя выводится в его карточке. Студентам — кнопка «Моё расписание» в главном меню: выберите группу один раз, дальше бот покажет занятия на сегодня, завтра и неделю.',
+             'Академические вопросы'
+      UNION ALL SELECT 'Как написать преподавателю?',
+             'В карточке преподавателя есть его e-mail — нажмите на него или скопируйте адрес и отправьте письмо.',
+             'Академические вопросы'
+      UNION ALL SELECT 'Что делать, если не нашёл нужного преподавателя?',
+             'Введите фамилию без сокращений и уточните кафедру. Если результата нет — возможно, данные ещё не внесены в базу.',
+             'Другое'
+      UNION ALL SELECT 'К кому обратиться при технических проблемах с ботом?',
+             'Если бот не отвечает или выдаёт ошибку — напишите администратору: karielka@yandex.ru',
+             'Другое'
+) AS v
+WHERE NOT EXISTS (SELECT 1 FROM faqs f WHERE f.question = v.question);
@@ -0,0 +1,5 @@
+-- полнотекстовый поиск по FAQ; выражение совпадает с models.faqSearchVector
+CREATE INDEX IF NOT EXISTS idx_faqs_search ON faqs USING gin (
+    (setweight(to_tsvector('russian', coalesce(question, '')), 'A') ||
+     setweight(to_tsvector('russian', coalesce(answer, '')), 'B'))
+);
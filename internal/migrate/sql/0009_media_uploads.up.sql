@@ -0,0 +1,8 @@
+-- токены картинок, загруженных в MAX (internal/media): ключ — ссылка или хеш локального файла
+CREATE TABLE IF NOT EXISTS media_uploads (
+    id         bigserial PRIMARY KEY,
+    source     text NOT NULL,
+    tokens     text NOT NULL,
+    created_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_media_uploads_source ON media_uploads (source);
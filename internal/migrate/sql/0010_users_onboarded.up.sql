@@ -0,0 +1,5 @@
+-- знакомство после /start показывается по флагу, а не по факту создания профиля:
+-- профиль может появиться раньше, например при выборе группы в «Моём расписании»
+ALTER TABLE users ADD COLUMN IF NOT EXISTS onboarded boolean NOT NULL DEFAULT false;
+-- /start создавал профиль с именем из MAX, значит знакомство эти пользователи уже видели
+UPDATE users SET onboarded = true WHERE COALESCE(display_name, '') <> '';
@@ -0,0 +1,2 @@
+-- pg_trgm не удаляем: его могли включить до этой миграции, и им может пользоваться что-то ещё
+DROP INDEX IF EXISTS idx_teachers_full_name_trgm;
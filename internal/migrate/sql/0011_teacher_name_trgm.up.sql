@@ -0,0 +1,6 @@
+-- нечёткий поиск ФИО (services/ft_search.go): pg_trgm и индекс по нормализованному ФИО.
+-- Выражение совпадает с ftNormNameSQL. С Postgres 13 pg_trgm — доверенное расширение,
+-- его может включить владелец базы.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_teachers_full_name_trgm ON teachers
+    USING gin (lower(replace(replace(full_name, 'ё', 'е'), 'Ё', 'Е')) gin_trgm_ops);
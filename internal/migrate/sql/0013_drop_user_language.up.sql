@@ -0,0 +1,2 @@
+-- бот отвечает только по-русски: выбранный язык нигде не читался
+ALTER TABLE users DROP COLUMN IF EXISTS language;
@@ -0,0 +1,107 @@
+package text
+
+import (
+	"strings"
+	"unicode"
+)
+
+// клавиши раскладки QWERTY и ЙЦУКЕН в одном порядке
+const (
+	latinKeys    = "qwertyuiop[]asdfghjkl;'zxcvbnm,.`"
+	cyrillicKeys = "йцукенгшщзхъфывапролджэячсмитьбюё"
+)
+
+var layoutSwap = func() map[rune]rune {
+	lat, cyr := []rune(latinKeys), []rune(cyrillicKeys)
+	m := make(map[rune]rune, 2*len(lat))
+	for i := range lat {
+		m[lat[i]], m[cyr[i]] = cyr[i], lat[i]
+	}
+	return m
+}()
+
+// SwapLayout - текст, набранный не в той раскладке: латинские клавиши -> русские и наоборот.
+// Работает с нижним регистром («ghbdtn» -> «привет», «руддщ» -> «hello»).
+func SwapLayout(s string) string {
+	return strings.Map(func(r rune) rune {
+		if c, ok := layoutSwap[r]; ok {
+			return c
+		}
+		return r
+	}, s)
+}
+
+// сначала многобуквенные сочетания, затем одиночные буквы
+var translitPairs = []string{
+	"shch", "щ", "sch", "щ", "yo", "е", "zh", "ж", "kh", "х", "ts", "ц", "ch", "ч", "sh", "ш",
+	"yu", "ю", "ya", "я", "ye", "е", "iy", "ий", "yi", "ый",
+	"a", "а", "b", "б", "v", "в", "g", "г", "d", "д", "e", "е", "z", "з", "i", "и", "j", "й",
+	"k", "к", "l", "л", "m", "м", "n", "н", "o", "о", "p", "п", "r", "р", "s", "с", "t", "т",
+	"u", "у", "f", "ф", "h", "х", "c", "к", "y", "ы", "w", "в", "x", "кс", "q", "к",
+}
+
+var translitReplacer = strings.NewReplacer(translitPairs...)
+
+// Translit - транслитерация латиницы в кириллицу (ivanov -> иванов), нижний регистр
+func Translit(s string) string { return translitReplacer.Replace(s) }
+
+// FixLayout - слова из латиницы переводятся в кириллицу: транслитом («ivanov») или сменой
+// раскладки («bdfyjd»). В транслите гласных много, а в русском слове, набранном в английской
+// раскладке, латинских гласных почти нет (а, е, и, о стоят на f, t, b, j) — по их доле и выбираем.
+// Слова короче трёх букв (обычно инициалы) только транслитерируются. Ожидает Fold.
+func FixLayout(s string) string {
+	fields := strings.Fields(s)
+	changed := false
+	for i, f := range fields {
+		if !hasLatin(f) || hasCyrillic(f) {
+			continue
+		}
+		tr := Translit(f)
+		if len([]rune(f)) >= 3 && share(f, "aeiouy") < 0.2 {
+			if sw := SwapLayout(f); share(sw, "аеиоуыэюя") >= 0.25 {
+				tr = sw
+			}
+		}
+		fields[i], changed = tr, true
+	}
+	if !changed {
+		return s
+	}
+	return strings.Join(fields, " ")
+}
+
+func hasLatin(s string) bool {
+	for _, r := range s {
+		if r >= 'a' && r <= 'z' {
+			return true
+		}
+	}
+	return false
+}
+
+func hasCyrillic(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// share - доля букв из vowels среди всех букв s
+func share(s, vowels string) float64 {
+	letters, n := 0, 0
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		if strings.ContainsRune(vowels, r) {
+			n++
+		}
+	}
+	if letters == 0 {
+		return 0
+	}
+	return float64(n) / float64(letters)
+}
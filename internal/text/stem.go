@@ -0,0 +1,28 @@
+package text
+
+import "strings"
+
+// окончания, которые отрезает Stem, — сначала длинные. «-ов/-ев/-ин» не режем: это чаще фамилии,
+// чем падежи, а «Иванов» и «Иванова» должны давать одну основу.
+var endings = []string{
+	"ться", "иями", "ями", "ами", "ией", "иях", "иям", "ием",
+	"ого", "его", "ому", "ему", "ыми", "ими", "ать", "ять", "ить", "еть",
+	"ах", "ях", "ой", "ей", "ий", "ый", "ых", "их", "ая", "яя", "ое", "ее", "ую", "юю",
+	"ом", "ем", "ам", "ям", "ые", "ие", "ью", "ья", "ье",
+	"ть", "а", "я", "о", "е", "у", "ю", "ы", "и", "ь", "й",
+}
+
+// Stem - грубая основа слова: отрезаем одно типичное окончание, оставляя не меньше трёх букв.
+// «столовой», «столовая» -> «столов»; «деканате» -> «деканат»; «Иванова», «Иванову» -> «иванов».
+func Stem(w string) string {
+	r := []rune(w)
+	if len(r) <= 3 {
+		return w
+	}
+	for _, e := range endings {
+		if n := len([]rune(e)); strings.HasSuffix(w, e) && len(r)-n >= 3 {
+			return string(r[:len(r)-n])
+		}
+	}
+	return w
+}
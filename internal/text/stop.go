@@ -0,0 +1,19 @@
+package text
+
+// stopWords - предлоги, союзы, частицы и местоимения: в поиске они только мешают.
+// Вопросительные «где», «когда», «как» и «сейчас» сюда не входят — по ним intent узнаёт раздел.
+var stopWords = map[string]bool{}
+
+func init() {
+	for _, w := range []string{
+		"а", "без", "бы", "в", "во", "вот", "вы", "да", "для", "до", "же", "за", "и", "из", "или",
+		"к", "ко", "ли", "мне", "меня", "мой", "моя", "мое", "мои", "мы", "на", "над", "нам", "нас",
+		"не", "ни", "но", "о", "об", "обо", "он", "она", "они", "от", "по", "под", "пожалуйста",
+		"при", "про", "с", "со", "то", "ты", "у", "уже", "это", "этот", "эта", "я",
+	} {
+		stopWords[w] = true
+	}
+}
+
+// IsStop - служебное ли слово (после Fold)
+func IsStop(w string) bool { return stopWords[w] }
@@ -0,0 +1,167 @@
+// Package text - нормализация русского текста для поиска: токенизация, ё -> е, стоп-слова,
+// грубый стеммер и исправление раскладки («bdfyjd» -> «иванов»).
+//
+// Всё работает офлайн и без словарей: основа слова получается отрезанием типичного окончания,
+// поэтому «столовой», «столовая» и «в столовке» сводятся к сравнимым основам, а названия
+// сравниваются по префиксам основ (Filter).
+package text
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Fold - ключ сравнения строк: нижний регистр, ё -> е, пробелы схлопнуты
+func Fold(s string) string {
+	s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+	return strings.ReplaceAll(s, "ё", "е")
+}
+
+// Tokens - слова текста после Fold. Буквы и цифры разделяются: «ИУ7» -> «иу», «7».
+func Tokens(s string) []string {
+	var out []string
+	var cur []rune
+	flush := func() {
+		if len(cur) > 0 {
+			out = append(out, string(cur))
+			cur = cur[:0]
+		}
+	}
+	for _, r := range Fold(s) {
+		switch {
+		case unicode.IsLetter(r):
+			if len(cur) > 0 && unicode.IsDigit(cur[len(cur)-1]) {
+				flush()
+			}
+			cur = append(cur, r)
+		case unicode.IsDigit(r):
+			if len(cur) > 0 && unicode.IsLetter(cur[len(cur)-1]) {
+				flush()
+			}
+			cur = append(cur, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return out
+}
+
+// Words - значимые слова: раскладка исправлена, стоп-слова выброшены
+func Words(s string) []string {
+	toks := Tokens(FixLayout(Fold(s)))
+	out := toks[:0]
+	for _, t := range toks {
+		if !IsStop(t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// Keywords - основы значимых слов (Words + Stem)
+func Keywords(s string) []string {
+	words := Words(s)
+	for i, w := range words {
+		words[i] = Stem(w)
+	}
+	return words
+}
+
+// Filter - индексы названий справочника, подходящих под запрос: каждое значимое слово запроса
+// совпадает с началом какого-нибудь слова названия (по основам, в любом падеже). Слова запроса,
+// которых нет ни в одном названии («деканате» в «деканате ИУ» среди факультетов), не учитываются.
+// Подстрока от трёх букв тоже подходит — как прежний поиск ILIKE; запрос короче («к», «иу»)
+// сравнивается только по словам, иначе он нашёлся бы почти в каждом названии.
+// Если ничего не нашлось, латиница пробуется ещё и в русской раскладке: «hr» -> «рк».
+func Filter(query string, names []string) []int {
+	out := filter(query, names)
+	if fq := Fold(query); len(out) == 0 && hasLatin(fq) {
+		out = filter(SwapLayout(fq), names)
+	}
+	return out
+}
+
+// minSubstring - с какой длины запрос ищется и как подстрока
+const minSubstring = 3
+
+func filter(query string, names []string) []int {
+	fq := Fold(query)
+	if fq == "" {
+		return nil
+	}
+	qs := Keywords(query)
+	keys := make([][]string, len(names))
+	known := make([]bool, len(qs))
+	for i, n := range names {
+		keys[i] = Keywords(n)
+		for j, q := range qs {
+			known[j] = known[j] || hasWord(keys[i], q)
+		}
+	}
+
+	var out []int
+	for i, n := range names {
+		if len([]rune(fq)) >= minSubstring && strings.Contains(Fold(n), fq) {
+			out = append(out, i)
+			continue
+		}
+		ok, used := true, 0
+		for j, q := range qs {
+			if !known[j] {
+				continue
+			}
+			used++
+			if !hasWord(keys[i], q) {
+				ok = false
+				break
+			}
+		}
+		if ok && used > 0 {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// hasWord - есть ли среди основ ns слово, совпадающее с основой q
+func hasWord(ns []string, q string) bool {
+	for _, n := range ns {
+		if wordMatch(q, n) {
+			return true
+		}
+	}
+	return false
+}
+
+// wordMatch - основа запроса q — начало основы названия n, или наоборот, если n не короче трёх букв
+// («столовк» и «столов»). Числа сравниваются целиком: «5» не находит «51».
+func wordMatch(q, n string) bool {
+	if q == n {
+		return true
+	}
+	if isNumber(q) || isNumber(n) {
+		return false
+	}
+	return strings.HasPrefix(n, q) || (len([]rune(n)) >= 3 && strings.HasPrefix(q, n))
+}
+
+func isNumber(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// NormalizeName - часть ФИО для сравнения: Fold, раскладка или транслит для латиницы,
+// остаются буквы и дефис. Прочие символы разделяют слова: «у Иванова» -> «у иванова».
+func NormalizeName(s string) string {
+	return strings.Join(strings.Fields(strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || r == '-' {
+			return r
+		}
+		return ' '
+	}, FixLayout(Fold(s)))), " ")
+}
@@ -0,0 +1,84 @@
+package text
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	names := []string{
+		"ИУ", // 0
+		"РК", // 1
+		"Кафедра информационных систем", // 2
+		"Информатика",                   // 3
+		"ИУ5",                           // 4
+		"ИУ5-31Б",                       // 5
+		"Столовая № 1",                  // 6
+		"Копировальный центр",           // 7
+	}
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{"к", nil},
+		{"у", nil},
+		{"иу", []int{0, 4, 5}},
+		{"ИУ", []int{0, 4, 5}},
+		{"деканат иу", []int{0, 4, 5}},
+		{"рк", []int{1}},
+		{"hr", []int{1}},
+		{"инф", []int{2, 3}},
+		{"информационные системы", []int{2}},
+		{"byajhvfnbrf", []int{3}},
+		{"ИУ5-31", []int{5}},
+		{"иу5 31б", []int{5}},
+		{"в столовке", []int{6}},
+		{"столовой", []int{6}},
+		{"копир", []int{7}},
+		{"", nil},
+		{"химия", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := Filter(tt.query, names); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Filter(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"у Иванова", []string{"иванова"}},
+		{"Где  СЕЙЧАС поесть?", []string{"где", "сейчас", "поесть"}},
+		{"bdfyjd", []string{"иванов"}},
+		{"ivanov", []string{"иванов"}},
+		{"ИУ7", []string{"иу", "7"}},
+		{"ёлка", []string{"елка"}},
+	}
+	for _, tt := range tests {
+		if got := Words(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Words(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	tests := map[string]string{
+		"Иванова":          "иванова",
+		"у Иванова":        "у иванова",
+		"Петров-Водкин,":   "петров-водкин",
+		"  Сидорова  О.И.": "сидорова о и",
+		"Ёжиков":           "ежиков",
+		"kuznetsov":        "кузнецов",
+		"":                 "",
+	}
+	for in, want := range tests {
+		if got := NormalizeName(in); got != want {
+			t.Errorf("NormalizeName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -5,7 +5,11 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
+	_ "time/tzdata" // в distroless-образе нет системной базы часовых поясов
 
 	maxbot "github.com/max-messenger/max-bot-api-client-go"
 	"github.com/max-messenger/max-bot-api-client-go/configservice"
@@ -17,8 +21,9 @@ import (
 
 	"gorm.io/gorm"
 
+	httpapi "github.com/Karielka/Hackaton_MAX/internal/api"
 	intdb "github.com/Karielka/Hackaton_MAX/internal/db"
-	"github.com/Karielka/Hackaton_MAX/models"
+	"github.com/Karielka/Hackaton_MAX/internal/media"
 	"github.com/Karielka/Hackaton_MAX/services"
 )
 
@@ -31,6 +36,16 @@ func main() {
 	if err := godotenv.Load(".env"); err != nil {
 		log.Warn().Err(err).Msg("godotenv: skip loading .env")
 	}
+	if tz := os.Getenv("BOT_TIMEZONE"); tz != "" {
+		if err := services.SetTimezone(tz); err != nil {
+			log.Warn().Err(err).Str("BOT_TIMEZONE", tz).Msg("bad timezone, using MSK")
+		}
+	}
+	// Подкоманды (import-staff и др.) работают без токена бота
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1], os.Args[2:]))
+	}
+
 	token := os.Getenv("TOKEN_MAX")
 	if token == "" {
 		log.Fatal().Msg("env TOKEN_MAX is empty")
@@ -53,9 +68,15 @@ func main() {
 		log.Fatal().Err(err).Msg("NewWithConfig failed. Stop.")
 	}
 
+	// Роутер кнопок: конфликт шаблонов payload — ошибка конфигурации, не стартуем
+	router, err := services.NewRouter()
+	if err != nil {
+		log.Fatal().Err(err).Msg("services.NewRouter failed. Stop.")
+	}
+
 	// 2) БД (GORM + Postgres)
 	db := intdb.Connect()
-	runMigrations(db)
+	checkMigrations(db)
 
 	// 3) Контекст с graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -66,6 +87,34 @@ func main() {
 		cancel()
 	}()
 
+	// Состояния диалогов: по умолчанию в Postgres, чтобы переживать рестарт
+	dialogs := services.NewDialogs(newDialogStore(db), dialogTTL())
+	go func() {
+		if err := dialogs.RunJanitor(ctx, 10*time.Minute); err != nil {
+			log.Err(err).Msg("dialogs janitor stopped")
+		}
+	}()
+	sc := services.Ctx{
+		API:       services.NewMaxMessenger(api, token, cfg.GetHttpBotAPIUrl()),
+		DB:        db,
+		Dialogs:   dialogs,
+		Admins:    adminIDs(),
+		PublicURL: os.Getenv("PUBLIC_URL"),
+		Media:     media.New(db, mediaDir()),
+	}
+
+	// HTTP: /healthz, REST API справочников для сотрудников (токен ADMIN_API_TOKEN) и подписка на расписание
+	go func() {
+		addr := httpAddr()
+		if os.Getenv("ADMIN_API_TOKEN") == "" {
+			log.Warn().Msg("ADMIN_API_TOKEN is empty, admin REST API is disabled")
+		}
+		log.Info().Str("addr", addr).Msg("HTTP server is up")
+		if err := httpapi.New(db, os.Getenv("ADMIN_API_TOKEN"), services.Location).Run(ctx, addr); err != nil {
+			log.Err(err).Str("addr", addr).Msg("HTTP server stopped")
+		}
+	}()
+
 	// 4) Команды бота (опционально)
 	_, _ = api.Bots.PatchBot(ctx, &schemes.BotPatch{
 		Commands: []schemes.BotCommand{
@@ -83,13 +132,14 @@ func main() {
 
 		switch upd := upd.(type) {
 		case *schemes.MessageCreatedUpdate:
-			handleMessage(ctx, api, db, upd)
+			if err := services.HandleMessage(ctx, sc, upd); err != nil {
+				log.Err(err).Msg("services.HandleMessage")
+			}
 
 		case *schemes.MessageCallbackUpdate:
 			// маршрутизация в сервисы
-			sc := services.Ctx{API: api, DB: db}
-			if err := services.Route(ctx, sc, upd); err != nil {
-				log.Err(err).Msg("services.Route")
+			if err := router.Route(ctx, sc, upd); err != nil {
+				log.Err(err).Msg("router.Route")
 			}
 
 		default:
@@ -98,53 +148,72 @@ func main() {
 	}
 }
 
-// /start, /menu — показываем меню, ИНАЧЕ — пробуем обработать текст сценарием поиска
-func handleMessage(ctx context.Context, api *maxbot.Api, db *gorm.DB, upd *schemes.MessageCreatedUpdate) {
-	// команды
-	switch upd.GetCommand() {
-	case "/start", "/menu":
-		msg := maxbot.NewMessage()
-		if upd.Message.Recipient.ChatId != 0 {
-			msg.SetChat(upd.Message.Recipient.ChatId)
-		} else {
-			msg.SetUser(upd.Message.Sender.UserId)
-		}
-		msg.SetText(services.WelcomeText()).AddKeyboard(services.MenuKeyboard(api))
-		if _, err := api.Messages.Send(ctx, msg); err != nil {
-			log.Err(err).Msg("send menu")
-		}
-		return
+// newDialogStore выбирает хранилище состояний по DIALOG_STORE (postgres | memory)
+func newDialogStore(db *gorm.DB) services.DialogStore {
+	switch os.Getenv("DIALOG_STORE") {
+	case "memory":
+		return services.NewMemoryDialogStore()
+	case "", "postgres":
+		return services.NewGormDialogStore(db)
+	default:
+		log.Warn().Str("DIALOG_STORE", os.Getenv("DIALOG_STORE")).Msg("unknown dialog store, using postgres")
+		return services.NewGormDialogStore(db)
 	}
+}
 
-	// делегируем в сервис поиска
-	sc := services.Ctx{API: api, DB: db}
-	if handled, err := services.OnMessage(ctx, sc, upd); err != nil {
-		log.Err(err).Msg("services.OnMessage")
-		return
-	} else if handled {
-		return
+// dialogTTL - время жизни незавершённого диалога (DIALOG_TTL, например "30m")
+func dialogTTL() time.Duration {
+	raw := os.Getenv("DIALOG_TTL")
+	if raw == "" {
+		return services.DefaultDialogTTL
+	}
+	ttl, err := time.ParseDuration(raw)
+	if err != nil {
+		log.Warn().Err(err).Str("DIALOG_TTL", raw).Msg("bad DIALOG_TTL, using default")
+		return services.DefaultDialogTTL
 	}
+	return ttl
+}
 
-	// по умолчанию — меню
-	msg := maxbot.NewMessage()
-	if upd.Message.Recipient.ChatId != 0 {
-		msg.SetChat(upd.Message.Recipient.ChatId)
-	} else {
-		msg.SetUser(upd.Message.Sender.UserId)
+// httpAddr - адрес HTTP-сервера (HTTP_ADDR). В контейнере всегда :8080,
+// наружу его публикует docker-compose на APP_PORT.
+func httpAddr() string {
+	if addr := os.Getenv("HTTP_ADDR"); addr != "" {
+		return addr
 	}
-	msg.SetText(services.WelcomeText()).AddKeyboard(services.MenuKeyboard(api))
-	if _, err := api.Messages.Send(ctx, msg); err != nil {
-		log.Err(err).Msg("send menu")
+	return ":8080"
+}
+
+// mediaDir - каталог локальных картинок (MEDIA_DIR): в image_url корпуса можно указать путь относительно него
+func mediaDir() string {
+	if dir := os.Getenv("MEDIA_DIR"); dir != "" {
+		return dir
 	}
+	return "media"
 }
 
-func runMigrations(db *gorm.DB) {
-	if err := models.AutoMigrate(db); err != nil {
-		log.Fatal().Err(err).Msg("AutoMigrate failed")
+// adminIDs - user_id администраторов из ADMIN_IDS ("123,456")
+func adminIDs() map[int64]bool {
+	ids := map[int64]bool{}
+	for _, raw := range strings.Split(os.Getenv("ADMIN_IDS"), ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			log.Warn().Str("ADMIN_IDS", raw).Msg("bad admin id, skipped")
+			continue
+		}
+		ids[id] = true
 	}
+	return ids
+}
 
-	// Сидим после миграций. Можно синхронно — это быстро и удобно для dev.
-	if err := models.SeedSampleData(db); err != nil {
-		log.Err(err).Msg("seed sample data")
+// checkMigrations - бот не стартует на устаревшей схеме: миграции применяются
+// отдельной командой «app migrate up» (в docker-compose — сервис migrate)
+func checkMigrations(db *gorm.DB) {
+	if err := checkSchema(context.Background(), db); err != nil {
+		log.Fatal().Err(err).Msg("schema check failed")
 	}
 }
@@ -1,73 +1,242 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Institute struct {
-	ID   uint   `gorm:"primaryKey"`
-	Name string `gorm:"uniqueIndex;not null"`
+	ID   uint   `gorm:"primaryKey" json:"id"`
+	Name string `gorm:"uniqueIndex;not null" json:"name"`
 }
 
 type Faculty struct {
-	ID          uint   `gorm:"primaryKey"`
-	Name        string `gorm:"uniqueIndex;not null"`
-	InstituteID uint
-	Institute   Institute `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"uniqueIndex;not null" json:"name"`
+	InstituteID uint      `json:"institute_id"`
+	Institute   Institute `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
 }
 
 type Department struct {
-	ID        uint   `gorm:"primaryKey"`
-	Name      string `gorm:"uniqueIndex;not null"`
-	FacultyID uint
-	Faculty   Faculty `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	ID        uint    `gorm:"primaryKey" json:"id"`
+	Name      string  `gorm:"uniqueIndex;not null" json:"name"`
+	FacultyID uint    `json:"faculty_id"`
+	Faculty   Faculty `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
+	Office    string  `json:"office"` // кабинет кафедры
 }
 
 type Teacher struct {
-	ID           uint   `gorm:"primaryKey"`
-	FullName     string `gorm:"index;not null"`
-	Email        string `gorm:"index"`
-	Subject      string `gorm:"index"` // предмет (опционально)
-	DepartmentID uint
-	Department   Department `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	Schedule     string     `gorm:"type:text"` // заглушка; позже вынесем в отдельную сущность
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	FullName     string     `gorm:"index;not null" json:"full_name"`
+	Email        string     `gorm:"index" json:"email"`
+	Subject      string     `gorm:"index" json:"subject"` // предмет (опционально)
+	DepartmentID uint       `json:"department_id"`
+	Department   Department `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
+	OfficeRoom   string     `json:"office_room"`  // кабинет (опционально)
+	Phone        string     `json:"phone"`        // телефон (опционально)
+	OfficeHours  string     `json:"office_hours"` // часы консультаций, например «Ср 15:30–16:30» (опционально)
+	Lessons      []Lesson   `json:"-"`            // расписание преподавателя
+}
+
+// Semester - учебный семестр; от StartDate считается чётность недель
+type Semester struct {
+	ID        uint      `gorm:"primaryKey"`
+	Name      string    `gorm:"uniqueIndex;not null"` // например, «Осень 2025»
+	StartDate time.Time `gorm:"type:date;not null"`   // понедельник первой (нечётной) недели
+	EndDate   time.Time `gorm:"type:date;not null"`
+}
+
+// Group - учебная группа
+type Group struct {
+	ID   uint   `gorm:"primaryKey"`
+	Name string `gorm:"uniqueIndex;not null"` // например, «ИУ1-31Б»
+}
+
+// Формы обучения
+const (
+	StudyFullTime   = "full_time"  // очная
+	StudyPartTime   = "part_time"  // очно-заочная
+	StudyExtramural = "extramural" // заочная
+)
+
+// StudyFormName - подпись формы обучения
+func StudyFormName(f string) string {
+	switch f {
+	case StudyFullTime:
+		return "очная"
+	case StudyPartTime:
+		return "очно-заочная"
+	case StudyExtramural:
+		return "заочная"
+	default:
+		return f
+	}
+}
+
+// User - профиль пользователя бота (ID — user_id в MAX); создаётся при первом /start
+type User struct {
+	ID           int64       `gorm:"primaryKey;autoIncrement:false"`
+	DisplayName  string      // имя из MAX на момент регистрации
+	FacultyID    *uint       `gorm:"index"`
+	Faculty      *Faculty    `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	DepartmentID *uint       `gorm:"index"`
+	Department   *Department `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	GroupID      *uint       `gorm:"index"` // группа для «Моё расписание»
+	Group        *Group      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	CampusID     *uint       // основной корпус: по умолчанию в «Столовых/копирках»
+	Campus       *Campus     `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	StudyForm    string      // StudyFullTime | StudyPartTime | StudyExtramural
+	Course       int         // 0 — не указан
+	Onboarded    bool        `gorm:"not null;default:false"` // знакомство уже предлагалось (/start)
+	CreatedAt    time.Time
+}
+
+// Виды избранного
+const (
+	FavTeacher = "teacher"
+	FavCampus  = "campus"
+	FavPlace   = "place"
+	FavFaculty = "faculty"
+)
+
+// UserFavorite - объект в избранном пользователя; заполнена ровно одна из ссылок
+type UserFavorite struct {
+	ID        uint     `gorm:"primaryKey"`
+	UserID    int64    `gorm:"not null;uniqueIndex:idx_fav_teacher;uniqueIndex:idx_fav_campus;uniqueIndex:idx_fav_place;uniqueIndex:idx_fav_faculty"` // user_id в MAX
+	TeacherID *uint    `gorm:"uniqueIndex:idx_fav_teacher"`
+	Teacher   *Teacher `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CampusID  *uint    `gorm:"uniqueIndex:idx_fav_campus"`
+	Campus    *Campus  `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	PlaceID   *uint    `gorm:"uniqueIndex:idx_fav_place"`
+	Place     *Place   `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	FacultyID *uint    `gorm:"uniqueIndex:idx_fav_faculty"`
+	Faculty   *Faculty `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CreatedAt time.Time
+}
+
+// Kind - вид избранного по заполненной ссылке
+func (f UserFavorite) Kind() string {
+	switch {
+	case f.TeacherID != nil:
+		return FavTeacher
+	case f.CampusID != nil:
+		return FavCampus
+	case f.PlaceID != nil:
+		return FavPlace
+	case f.FacultyID != nil:
+		return FavFaculty
+	}
+	return ""
+}
+
+// Lesson - слот расписания: одно занятие, повторяющееся по неделям
+type Lesson struct {
+	ID          uint      `gorm:"primaryKey"`
+	TeacherID   uint      `gorm:"index;not null"`
+	Teacher     Teacher   `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	SemesterID  *uint     `gorm:"index"`
+	Semester    *Semester `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Weekday     int       `gorm:"not null"` // 1 — Пн … 7 — Вс
+	StartMinute int       `gorm:"not null"` // минуты от полуночи
+	EndMinute   int       `gorm:"not null"`
+	WeekParity  string    // ParityAny | ParityOdd | ParityEven
+	Room        string
+	CampusID    *uint   `gorm:"index"`
+	Campus      *Campus `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Subject     string  `gorm:"not null"`
+	LessonType  string  // LessonLecture | LessonSeminar | LessonLab
+	Groups      []Group `gorm:"many2many:lesson_groups;"`
 }
 
 type DeanOffice struct {
-	ID        uint `gorm:"primaryKey"`
-	FacultyID uint `gorm:"uniqueIndex"`
-	Schedule  string
-	DocsLink  string
-	Contacts  string
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	FacultyID uint   `gorm:"uniqueIndex" json:"faculty_id"`
+	Schedule  string `json:"schedule"` // подпись для людей; часы для «открыто сейчас» — OpeningHours
+	DocsLink  string `json:"docs_link"`
+	Contacts  string `json:"contacts"`
 }
 
 type Campus struct {
-	ID          uint   `gorm:"primaryKey"`
-	ShortName   string `gorm:"index;not null"`
-	FullName    string `gorm:"index;not null"`
-	Address     string
-	Metro       string // Ближайшее метро
-	ImageURL    string // Фото корпуса
-	MapImageURL string // Фото с картой
-	Description string // Что находится внутри
-	InstituteID uint   // если нужно привязать корпуса к институту
+	ID          uint     `gorm:"primaryKey" json:"id"`
+	ShortName   string   `gorm:"index;not null" json:"short_name"`
+	FullName    string   `gorm:"index;not null" json:"full_name"`
+	Address     string   `json:"address"`
+	Metro       string   `json:"metro"`         // Ближайшее метро
+	ImageURL    string   `json:"image_url"`     // Фото корпуса
+	MapImageURL string   `json:"map_image_url"` // Фото с картой
+	Description string   `json:"description"`   // Что находится внутри
+	InstituteID uint     `json:"institute_id"`  // если нужно привязать корпуса к институту
+	Latitude    *float64 `json:"latitude"`      // координаты входа — для «ближайшего открытого»
+	Longitude   *float64 `json:"longitude"`     // nil — не указаны
+}
+
+// DistanceKm - расстояние по прямой между корпусами; ok == false, если у одного из них нет координат
+func (c Campus) DistanceKm(other Campus) (km float64, ok bool) {
+	if c.Latitude == nil || c.Longitude == nil || other.Latitude == nil || other.Longitude == nil {
+		return 0, false
+	}
+	const earthRadiusKm = 6371.0
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	lat1, lat2 := rad(*c.Latitude), rad(*other.Latitude)
+	dLat, dLon := lat2-lat1, rad(*other.Longitude-*c.Longitude)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a)), true
 }
 
 type Place struct {
-	ID        uint   `gorm:"primaryKey"`
-	CampusID  uint   `gorm:"index"`
-	Type      string `gorm:"index"` // "canteen" | "buffet" | "copy"...
-	Name      string
-	Location  string
-	Schedule  string
-	MenuURL   string
-	MenuToday string `gorm:"type:text"` // Меню на сегодня
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	CampusID  uint   `gorm:"index" json:"campus_id"`
+	Type      string `gorm:"index" json:"type"` // "canteen" | "buffet" | "copy"...
+	Name      string `json:"name"`
+	Location  string `json:"location"`
+	Schedule  string `json:"schedule"` // подпись для людей; часы для «открыто сейчас» — OpeningHours
+	MenuURL   string `json:"menu_url"`
+	MenuToday string `gorm:"type:text" json:"menu_today"` // Услуги и цены копировального центра; меню еды — в Menu
+}
+
+// FAQCategory - тема частых вопросов («Общежитие», «Стипендия»…)
+type FAQCategory struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	Name     string `gorm:"uniqueIndex;not null" json:"name"`
+	Position int    `gorm:"not null;default:0" json:"position"` // порядок кнопок, затем по названию
 }
 
 type FAQ struct {
-	ID       uint   `gorm:"primaryKey"`
-	Question string `gorm:"index"`
-	Answer   string
+	ID         uint         `gorm:"primaryKey" json:"id"`
+	CategoryID *uint        `gorm:"index" json:"category_id"` // nil — «Без темы»
+	Category   *FAQCategory `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
+	Question   string       `gorm:"index" json:"question"`
+	Answer     string       `json:"answer"`
+}
+
+// Admin - администратор контента (в дополнение к ADMIN_IDS из окружения)
+type Admin struct {
+	UserID    int64  `gorm:"primaryKey;autoIncrement:false"` // user_id в MAX
+	Note      string // кто это, для людей
+	CreatedAt time.Time
+}
+
+// DialogState - сохранённое состояние диалога с чатом/пользователем
+type DialogState struct {
+	Peer      int64     `gorm:"primaryKey;autoIncrement:false"`
+	State     string    `gorm:"not null"`
+	Data      string    `gorm:"type:text"` // JSON с параметрами шага
+	ExpiresAt time.Time `gorm:"index"`
+}
+
+// MediaUpload - картинка, уже загруженная в MAX: повторно не загружаем, а прикладываем по токенам
+type MediaUpload struct {
+	ID        uint   `gorm:"primaryKey"`
+	Source    string `gorm:"uniqueIndex;not null"` // «url:<ссылка>» или «sha256:<хеш содержимого файла>»
+	Tokens    string `gorm:"type:text;not null"`   // ответ MAX на загрузку (JSON schemes.PhotoTokens)
+	CreatedAt time.Time
 }
 
+// AutoMigrate - схема по моделям для SQLite в симуляторе (internal/botsim).
+// В Postgres схему ведут версионные миграции internal/migrate — при изменении моделей
+// нужна новая миграция, AutoMigrate её не заменяет.
 func AutoMigrate(db *gorm.DB) error {
 	return db.AutoMigrate(
 		&Institute{},
@@ -77,6 +246,19 @@ func AutoMigrate(db *gorm.DB) error {
 		&DeanOffice{},
 		&Campus{},
 		&Place{},
+		&Menu{},
+		&Dish{},
+		&OpeningHours{},
+		&HoursException{},
+		&FAQCategory{},
 		&FAQ{},
+		&DialogState{},
+		&Semester{},
+		&Group{},
+		&Lesson{},
+		&User{},
+		&UserFavorite{},
+		&Admin{},
+		&MediaUpload{},
 	)
 }
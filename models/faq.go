@@ -0,0 +1,78 @@
+package models
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// faqSearchVector - документ для полнотекстового поиска по FAQ: вопрос весит больше ответа.
+// Выражение должно совпадать с индексом idx_faqs_search (миграция 0008), иначе индекс не используется.
+const faqSearchVector = `setweight(to_tsvector('russian', coalesce(faqs.question, '')), 'A') || ` +
+	`setweight(to_tsvector('russian', coalesce(faqs.answer, '')), 'B')`
+
+// сколько кандидатов берём из базы для пересчёта уверенности
+const faqSearchCandidates = 10
+
+// FAQMatch - найденный вопрос и уверенность совпадения
+type FAQMatch struct {
+	FAQ
+	// Score - доля слов запроса, найденных в вопросе (1) или только в ответе (½), от 0 до 1
+	Score float64
+}
+
+// SearchFAQ ищет вопросы по свободному тексту полнотекстовым поиском Postgres (словарь russian):
+// кандидаты — записи с хотя бы одним словом запроса, по убыванию Score. Стоп-слова («как», «об»)
+// не учитываются; если в запросе других слов нет, результат пустой.
+func SearchFAQ(db *gorm.DB, text string) ([]FAQMatch, error) {
+	var queryLex string
+	if err := db.Raw("SELECT array_to_string(tsvector_to_array(to_tsvector('russian', ?::text)), ' ')", text).
+		Scan(&queryLex).Error; err != nil {
+		return nil, fmt.Errorf("failed to parse faq query: %w", err)
+	}
+	words := strings.Fields(queryLex)
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	type row struct {
+		FAQ
+		Rank        float64
+		QuestionLex string
+		AnswerLex   string
+	}
+	var rows []row
+	// «справк & обучен» -> «справк | обучен»: подходят записи хотя бы с одним словом
+	err := db.Raw(`WITH q AS (SELECT replace(plainto_tsquery('russian', ?::text)::text, '&', '|')::tsquery AS query)
+		SELECT faqs.*, ts_rank(`+faqSearchVector+`, q.query) AS rank,
+			array_to_string(tsvector_to_array(to_tsvector('russian', coalesce(faqs.question, ''))), ' ') AS question_lex,
+			array_to_string(tsvector_to_array(to_tsvector('russian', coalesce(faqs.answer, ''))), ' ') AS answer_lex
+		FROM faqs, q
+		WHERE (`+faqSearchVector+`) @@ q.query
+		ORDER BY rank DESC, faqs.id
+		LIMIT ?`, text, faqSearchCandidates).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to search faqs: %w", err)
+	}
+
+	out := make([]FAQMatch, len(rows))
+	for i, r := range rows {
+		inQuestion, inAnswer := strings.Fields(r.QuestionLex), strings.Fields(r.AnswerLex)
+		var hits float64
+		for _, w := range words {
+			switch {
+			case slices.Contains(inQuestion, w):
+				hits++
+			case slices.Contains(inAnswer, w):
+				hits += 0.5
+			}
+		}
+		out[i] = FAQMatch{FAQ: r.FAQ, Score: hits / float64(len(words))}
+	}
+	// порядок ts_rank сохраняется при равной уверенности
+	slices.SortStableFunc(out, func(a, b FAQMatch) int { return cmp.Compare(b.Score, a.Score) })
+	return out, nil
+}
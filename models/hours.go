@@ -0,0 +1,319 @@
+package models
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// OpeningHours - интервал работы места или деканата в один день недели.
+// Перерыв (обед 13:00–14:00) — это два интервала в один день.
+type OpeningHours struct {
+	ID           uint        `gorm:"primaryKey"`
+	PlaceID      *uint       `gorm:"index"`
+	Place        *Place      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	DeanOfficeID *uint       `gorm:"index"`
+	DeanOffice   *DeanOffice `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Weekday      int         `gorm:"not null"` // 1 — Пн … 7 — Вс
+	OpenMinute   int         `gorm:"not null"` // минуты от полуночи
+	CloseMinute  int         `gorm:"not null"` // не больше 24:00
+}
+
+// HoursException - особый день: праздник (закрыто) или сокращённый день.
+// Без PlaceID и DeanOfficeID — общий для всего университета.
+type HoursException struct {
+	ID           uint        `gorm:"primaryKey"`
+	PlaceID      *uint       `gorm:"index"`
+	Place        *Place      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	DeanOfficeID *uint       `gorm:"index"`
+	DeanOffice   *DeanOffice `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Date         time.Time   `gorm:"type:date;index;not null"`
+	Closed       bool        `gorm:"not null"`
+	OpenMinute   int         // если не Closed — часы работы в этот день
+	CloseMinute  int
+	Note         string // «День народного единства»
+}
+
+// Hours - режим работы одного места или деканата
+type Hours struct {
+	Weekly     []OpeningHours
+	Exceptions []HoursException // свои и общие
+}
+
+// OpenStatus - открыто ли сейчас и когда это изменится
+type OpenStatus struct {
+	Known bool      // режим работы заполнен
+	Open  bool      // открыто в момент проверки
+	Until time.Time // когда закроется (Open); у круглосуточных — конец просмотренных дней
+	Next  time.Time // когда откроется в следующий раз; нулевое — не в ближайшие дни
+}
+
+// на сколько дней вперёд ищем следующее открытие
+const hoursHorizon = 14
+
+// StatusAt - статус в момент now. Часы считаются в часовом поясе now.
+func (h Hours) StatusAt(now time.Time) OpenStatus {
+	if len(h.Weekly) == 0 {
+		return OpenStatus{}
+	}
+	st := OpenStatus{Known: true}
+
+	// интервалы с сегодняшнего дня на hoursHorizon вперёд; смежные склеиваем (Пн 0:00–24:00, Вт 0:00–…)
+	type span struct{ from, to time.Time }
+	var spans []span
+	y, m, d := now.Date()
+	for off := 0; off <= hoursHorizon; off++ {
+		day := time.Date(y, m, d+off, 0, 0, 0, 0, now.Location())
+		for _, iv := range h.intervals(day) {
+			from := time.Date(y, m, d+off, iv[0]/60, iv[0]%60, 0, 0, now.Location())
+			to := time.Date(y, m, d+off, iv[1]/60, iv[1]%60, 0, 0, now.Location())
+			if n := len(spans); n > 0 && !from.After(spans[n-1].to) {
+				spans[n-1].to = to
+				continue
+			}
+			spans = append(spans, span{from, to})
+		}
+	}
+
+	for i, s := range spans {
+		if now.Before(s.from) {
+			st.Next = s.from
+			return st
+		}
+		if now.Before(s.to) {
+			st.Open, st.Until = true, s.to
+			if i+1 < len(spans) {
+				st.Next = spans[i+1].from
+			}
+			return st
+		}
+	}
+	return st
+}
+
+// intervals - часы работы в день day по возрастанию: исключение на этот день важнее недельного режима,
+// своё исключение — важнее общего
+func (h Hours) intervals(day time.Time) [][2]int {
+	var exc *HoursException
+	for i, e := range h.Exceptions {
+		if e.Date.Format(time.DateOnly) != day.Format(time.DateOnly) {
+			continue
+		}
+		if exc == nil || (exc.PlaceID == nil && exc.DeanOfficeID == nil) {
+			exc = &h.Exceptions[i]
+		}
+	}
+	if exc != nil {
+		if exc.Closed || exc.CloseMinute <= exc.OpenMinute {
+			return nil
+		}
+		return [][2]int{{exc.OpenMinute, exc.CloseMinute}}
+	}
+	var out [][2]int
+	for _, w := range h.Weekly {
+		if w.Weekday == IsoWeekday(day) && w.CloseMinute > w.OpenMinute {
+			out = append(out, [2]int{w.OpenMinute, w.CloseMinute})
+		}
+	}
+	slices.SortFunc(out, func(a, b [2]int) int { return cmp.Compare(a[0], b[0]) })
+	return out
+}
+
+// PlaceHours - режим работы мест с данными id
+func PlaceHours(db *gorm.DB, now time.Time, ids ...uint) (map[uint]Hours, error) {
+	return loadHours(db, "place_id", now, ids)
+}
+
+// DeanOfficeHours - режим работы деканатов с данными id
+func DeanOfficeHours(db *gorm.DB, now time.Time, ids ...uint) (map[uint]Hours, error) {
+	return loadHours(db, "dean_office_id", now, ids)
+}
+
+// loadHours читает недельный режим и исключения на ближайшие hoursHorizon дней
+func loadHours(db *gorm.DB, col string, now time.Time, ids []uint) (map[uint]Hours, error) {
+	out := make(map[uint]Hours, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	var weekly []OpeningHours
+	if err := db.Where(col+" IN ?", ids).Order("weekday, open_minute").Find(&weekly).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch opening hours: %w", err)
+	}
+	var exceptions []HoursException
+	if err := db.Where("("+col+" IN ? OR (place_id IS NULL AND dean_office_id IS NULL)) AND date >= ? AND date <= ?",
+		ids, now.AddDate(0, 0, -1).Format(time.DateOnly), now.AddDate(0, 0, hoursHorizon+1).Format(time.DateOnly)).
+		Find(&exceptions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch hours exceptions: %w", err)
+	}
+
+	owner := func(placeID, deanOfficeID *uint) *uint {
+		if col == "place_id" {
+			return placeID
+		}
+		return deanOfficeID
+	}
+	for _, w := range weekly {
+		id := *owner(w.PlaceID, w.DeanOfficeID)
+		h := out[id]
+		h.Weekly = append(h.Weekly, w)
+		out[id] = h
+	}
+	for _, e := range exceptions {
+		if id := owner(e.PlaceID, e.DeanOfficeID); id != nil {
+			h := out[*id]
+			h.Exceptions = append(h.Exceptions, e)
+			out[*id] = h
+			continue
+		}
+		for _, id := range ids { // общий праздник
+			h := out[id]
+			h.Exceptions = append(h.Exceptions, e)
+			out[id] = h
+		}
+	}
+	return out, nil
+}
+
+// ---- разбор текстового режима работы ----
+
+const (
+	hoursDays  = `(Пн|Вт|Ср|Чт|Пт|Сб|Вс)`
+	hoursTime  = `(\d{1,2})[:.](\d{2})`
+	hoursRange = hoursTime + `\s*[–—-]\s*` + hoursTime
+)
+
+var hoursLineRe = regexp.MustCompile(`(?i)^` + hoursDays + `(?:\s*[–—-]\s*` + hoursDays + `)?\s*:?\s*` +
+	`(?:(выходной)|` + hoursRange + `(?:\s*\((?:обед|перерыв)\s*` + hoursRange + `\))?)$`)
+
+// ParseOpeningHours разбирает текстовый режим работы: строки вида «Пн-Пт 9:00 - 17:00»,
+// «Пн–Чт: 10:00–17:00 (обед 13:00–14:00)», «Сб–Вс: выходной», разделённые переводом строки или «;».
+// Строки, которые не начинаются с дня недели (примечания), пропускаются.
+func ParseOpeningHours(raw string) ([]OpeningHours, error) {
+	var out []OpeningHours
+	parsed := false
+	for _, line := range strings.FieldsFunc(raw, func(r rune) bool { return r == '\n' || r == ';' }) {
+		line = strings.TrimSpace(line)
+		m := hoursLineRe.FindStringSubmatch(line)
+		if m == nil {
+			if startsWithWeekday(line) {
+				return nil, fmt.Errorf("unrecognized hours line %q", line)
+			}
+			continue
+		}
+		parsed = true
+		first := weekdayIndex(m[1])
+		last := first
+		if m[2] != "" {
+			last = weekdayIndex(m[2])
+		}
+		if last < first {
+			return nil, fmt.Errorf("bad weekday range in %q", line)
+		}
+		if m[3] != "" { // выходной
+			continue
+		}
+		opens, closes := clock(m[4], m[5]), clock(m[6], m[7])
+		if opens < 0 || closes < 0 || closes <= opens {
+			return nil, fmt.Errorf("bad time range in %q", line)
+		}
+		spans := [][2]int{{opens, closes}}
+		if m[8] != "" {
+			bFrom, bTo := clock(m[8], m[9]), clock(m[10], m[11])
+			if bFrom <= opens || bTo >= closes || bTo <= bFrom {
+				return nil, fmt.Errorf("break outside working hours in %q", line)
+			}
+			spans = [][2]int{{opens, bFrom}, {bTo, closes}}
+		}
+		for wd := first; wd <= last; wd++ {
+			for _, s := range spans {
+				out = append(out, OpeningHours{Weekday: wd, OpenMinute: s[0], CloseMinute: s[1]})
+			}
+		}
+	}
+	if !parsed {
+		return nil, errors.New("no opening hours found")
+	}
+	return out, nil
+}
+
+func startsWithWeekday(s string) bool {
+	for _, name := range WeekdayShort[1:] {
+		if len(s) >= len(name) && strings.EqualFold(s[:len(name)], name) {
+			return true
+		}
+	}
+	return false
+}
+
+func weekdayIndex(name string) int {
+	for i, n := range WeekdayShort {
+		if i > 0 && strings.EqualFold(n, name) {
+			return i
+		}
+	}
+	return 0
+}
+
+// clock - «9», «05» -> минуты от полуночи; -1, если время вне 0:00–24:00
+func clock(h, m string) int {
+	hh, _ := strconv.Atoi(h)
+	mm, _ := strconv.Atoi(m)
+	if mm > 59 || hh*60+mm > 24*60 {
+		return -1
+	}
+	return hh*60 + mm
+}
+
+// MigrateTextHours заполняет opening_hours из текстовых places.schedule и dean_offices.schedule.
+// Текст остаётся как подпись для людей; строки, которые не разобрались, пишутся в лог и
+// пропускаются — такие часы заводятся через API.
+func MigrateTextHours(db *gorm.DB) error {
+	type row struct {
+		ID       uint
+		Schedule string
+	}
+	for _, src := range []struct {
+		table string
+		col   string // колонка opening_hours со ссылкой на запись
+		set   func(h *OpeningHours, id uint)
+	}{
+		{"places", "place_id", func(h *OpeningHours, id uint) { h.PlaceID = &id }},
+		{"dean_offices", "dean_office_id", func(h *OpeningHours, id uint) { h.DeanOfficeID = &id }},
+	} {
+		var rows []row
+		if err := db.Table(src.table).Select("id, schedule").
+			Where("COALESCE(schedule, '') <> ''").Scan(&rows).Error; err != nil {
+			return fmt.Errorf("read %s.schedule: %w", src.table, err)
+		}
+		for _, r := range rows {
+			var n int64
+			if err := db.Model(&OpeningHours{}).Where(src.col+" = ?", r.ID).Count(&n).Error; err != nil {
+				return err
+			}
+			if n > 0 {
+				continue // уже заведено
+			}
+			hours, err := ParseOpeningHours(r.Schedule)
+			if err != nil {
+				log.Warn().Err(err).Str("table", src.table).Uint("id", r.ID).Str("schedule", r.Schedule).
+					Msg("opening hours not parsed, set them via API")
+				continue
+			}
+			for i := range hours {
+				src.set(&hours[i], r.ID)
+			}
+			if err := db.Create(&hours).Error; err != nil {
+				return fmt.Errorf("create opening hours for %s %d: %w", src.table, r.ID, err)
+			}
+		}
+	}
+	return nil
+}
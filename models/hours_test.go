@@ -0,0 +1,170 @@
+package models_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+func TestParseOpeningHours(t *testing.T) {
+	type iv = [3]int // день, открытие, закрытие
+	tests := []struct {
+		raw     string
+		want    []iv
+		wantErr bool
+	}{
+		{raw: "Пн-Пт 9:00 - 17:00", want: []iv{{1, 540, 1020}, {2, 540, 1020}, {3, 540, 1020}, {4, 540, 1020}, {5, 540, 1020}}},
+		{raw: "Сб 10.00–14.30", want: []iv{{6, 600, 870}}},
+		{raw: "Пн–Вт: 10:00–17:00 (обед 13:00–14:00)", want: []iv{{1, 600, 780}, {1, 840, 1020}, {2, 600, 780}, {2, 840, 1020}}},
+		{raw: "Пн 8:00–12:00 (перерыв 10:00-10:15)", want: []iv{{1, 480, 600}, {1, 615, 720}}},
+		{raw: "Пн-Пт 9:00-18:00; Сб-Вс: выходной", want: []iv{{1, 540, 1080}, {2, 540, 1080}, {3, 540, 1080}, {4, 540, 1080}, {5, 540, 1080}}},
+		{raw: "Вс 0:00–24:00\nв праздники закрыто", want: []iv{{7, 0, 1440}}},
+		{raw: "пт 9:00-10:00", want: []iv{{5, 540, 600}}},
+		{raw: "Сб-Вс: выходной", want: []iv{}},
+		{raw: "по будням с утра", wantErr: true},
+		{raw: "", wantErr: true},
+		{raw: "Пт-Пн 9:00-17:00", wantErr: true},
+		{raw: "Пн 18:00-9:00", wantErr: true},
+		{raw: "Пн 9:00-25:00", wantErr: true},
+		{raw: "Пн 9:00-17:00 (обед 8:00-9:30)", wantErr: true},
+		{raw: "Пн 9:00-17:00; Вт круглосуточно", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			hours, err := models.ParseOpeningHours(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want error, got %+v", hours)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []iv{}
+			for _, h := range hours {
+				got = append(got, iv{h.Weekday, h.OpenMinute, h.CloseMinute})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHoursStatusAt(t *testing.T) {
+	loc := time.UTC
+	at := func(s string) time.Time { return date(s, loc) }
+	day := func(s string) time.Time { return at(s + " 00:00") }
+	place := uint(1)
+	weekly := func(spec ...[3]int) []models.OpeningHours {
+		var out []models.OpeningHours
+		for _, s := range spec {
+			out = append(out, models.OpeningHours{Weekday: s[0], OpenMinute: s[1], CloseMinute: s[2]})
+		}
+		return out
+	}
+	// Пн–Пт 9:00–18:00 с обедом 13:00–14:00
+	office := weekly(
+		[3]int{1, 540, 780}, [3]int{1, 840, 1080}, [3]int{2, 540, 780}, [3]int{2, 840, 1080},
+		[3]int{3, 540, 780}, [3]int{3, 840, 1080}, [3]int{4, 540, 780}, [3]int{4, 840, 1080},
+		[3]int{5, 540, 780}, [3]int{5, 840, 1080},
+	)
+	// 2025-11-03 — понедельник
+	tests := []struct {
+		name  string
+		hours models.Hours
+		now   string
+		want  models.OpenStatus
+	}{
+		{"no hours", models.Hours{}, "2025-11-03 10:00", models.OpenStatus{}},
+		{"before opening", models.Hours{Weekly: office}, "2025-11-03 08:00",
+			models.OpenStatus{Known: true, Next: at("2025-11-03 09:00")}},
+		{"open until break", models.Hours{Weekly: office}, "2025-11-03 10:00",
+			models.OpenStatus{Known: true, Open: true, Until: at("2025-11-03 13:00"), Next: at("2025-11-03 14:00")}},
+		{"on break", models.Hours{Weekly: office}, "2025-11-03 13:30",
+			models.OpenStatus{Known: true, Next: at("2025-11-03 14:00")}},
+		{"closing time is closed", models.Hours{Weekly: office}, "2025-11-03 18:00",
+			models.OpenStatus{Known: true, Next: at("2025-11-04 09:00")}},
+		{"friday evening to monday", models.Hours{Weekly: office}, "2025-11-07 19:00",
+			models.OpenStatus{Known: true, Next: at("2025-11-10 09:00")}},
+		{"night shift joins across midnight",
+			models.Hours{Weekly: weekly([3]int{1, 1200, 1440}, [3]int{2, 0, 360}, [3]int{2, 1200, 1440})},
+			"2025-11-03 23:00",
+			models.OpenStatus{Known: true, Open: true, Until: at("2025-11-04 06:00"), Next: at("2025-11-04 20:00")}},
+		{"round the clock until the horizon",
+			models.Hours{Weekly: weekly([3]int{1, 0, 1440}, [3]int{2, 0, 1440}, [3]int{3, 0, 1440},
+				[3]int{4, 0, 1440}, [3]int{5, 0, 1440}, [3]int{6, 0, 1440}, [3]int{7, 0, 1440})},
+			"2025-11-03 12:00",
+			models.OpenStatus{Known: true, Open: true, Until: at("2025-11-18 00:00")}},
+		{"holiday closes the day",
+			models.Hours{Weekly: office, Exceptions: []models.HoursException{{Date: day("2025-11-04"), Closed: true}}},
+			"2025-11-04 10:00",
+			models.OpenStatus{Known: true, Next: at("2025-11-05 09:00")}},
+		{"short day replaces weekly hours and break",
+			models.Hours{Weekly: office, Exceptions: []models.HoursException{{Date: day("2025-11-03"), OpenMinute: 600, CloseMinute: 900}}},
+			"2025-11-03 13:30",
+			models.OpenStatus{Known: true, Open: true, Until: at("2025-11-03 15:00"), Next: at("2025-11-04 09:00")}},
+		{"own exception beats the common one",
+			models.Hours{Weekly: office, Exceptions: []models.HoursException{
+				{Date: day("2025-11-04"), Closed: true},
+				{Date: day("2025-11-04"), PlaceID: &place, OpenMinute: 660, CloseMinute: 720},
+			}},
+			"2025-11-04 08:00",
+			models.OpenStatus{Known: true, Next: at("2025-11-04 11:00")}},
+		{"own exception beats the common one in any order",
+			models.Hours{Weekly: office, Exceptions: []models.HoursException{
+				{Date: day("2025-11-04"), PlaceID: &place, OpenMinute: 660, CloseMinute: 720},
+				{Date: day("2025-11-04"), Closed: true},
+			}},
+			"2025-11-04 08:00",
+			models.OpenStatus{Known: true, Next: at("2025-11-04 11:00")}},
+		{"exception on a weekend opens it",
+			models.Hours{Weekly: office, Exceptions: []models.HoursException{{Date: day("2025-11-08"), OpenMinute: 600, CloseMinute: 840}}},
+			"2025-11-07 19:00",
+			models.OpenStatus{Known: true, Next: at("2025-11-08 10:00")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.hours.StatusAt(at(tt.now)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StatusAt(%s) = %+v, want %+v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrateTextHours(t *testing.T) {
+	db := openDB(t)
+	good := models.Place{CampusID: 1, Type: "canteen", Name: "Новая столовая", Schedule: "Пн-Пт 9:00-16:00"}
+	bad := models.Place{CampusID: 1, Type: "copy", Name: "Новая копирка", Schedule: "как получится"}
+	for _, p := range []*models.Place{&good, &bad} {
+		if err := db.Create(p).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := models.MigrateTextHours(db); err != nil {
+		t.Fatal(err)
+	}
+	count := func(id uint) int64 {
+		var n int64
+		if err := db.Model(&models.OpeningHours{}).Where("place_id = ?", id).Count(&n).Error; err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	if n := count(good.ID); n != 5 {
+		t.Errorf("parsed place has %d intervals, want 5", n)
+	}
+	if n := count(bad.ID); n != 0 {
+		t.Errorf("unparsed place has %d intervals, want 0", n)
+	}
+	// повторный запуск ничего не дублирует
+	if err := models.MigrateTextHours(db); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(good.ID); n != 5 {
+		t.Errorf("after second run parsed place has %d intervals, want 5", n)
+	}
+}
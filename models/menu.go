@@ -0,0 +1,94 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Menu - меню столовой или буфета на один день
+type Menu struct {
+	ID      uint      `gorm:"primaryKey" json:"id"`
+	PlaceID uint      `gorm:"uniqueIndex:idx_menus_place_date;not null" json:"place_id"`
+	Place   *Place    `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	Date    time.Time `gorm:"type:date;uniqueIndex:idx_menus_place_date;not null" json:"-"`
+	Dishes  []Dish    `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"dishes"`
+}
+
+// Dish - блюдо в меню
+type Dish struct {
+	ID        uint   `gorm:"primaryKey" json:"-"`
+	MenuID    uint   `gorm:"index;not null" json:"-"`
+	Position  int    `gorm:"not null" json:"-"` // порядок в меню
+	Category  string `json:"category"`          // «Супы», «Горячее», «Напитки»…
+	Name      string `gorm:"not null" json:"name"`
+	PriceKop  int    `gorm:"not null" json:"price_kop"` // цена в копейках
+	Weight    string `json:"weight,omitempty"`          // выход: «250 г», «150/50»
+	Allergens string `json:"allergens,omitempty"`       // через запятую: «глютен, молоко»
+}
+
+// HasMenu - публикуются ли у места ежедневные меню (столовые и буфеты)
+func (p Place) HasMenu() bool {
+	return p.Type == "canteen" || p.Type == "buffet"
+}
+
+// FindMenu - меню места на день day с блюдами по порядку; ok == false, если его не публиковали
+func FindMenu(db *gorm.DB, placeID uint, day time.Time) (menu Menu, ok bool, err error) {
+	// диапазон вместо равенства: SQLite хранит date как строку с временем
+	from := day.Format(time.DateOnly)
+	to := day.AddDate(0, 0, 1).Format(time.DateOnly)
+	err = db.Preload("Dishes", func(q *gorm.DB) *gorm.DB { return q.Order("position, id") }).
+		Where("place_id = ? AND date >= ? AND date < ?", placeID, from, to).
+		First(&menu).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Menu{}, false, nil
+	}
+	if err != nil {
+		return Menu{}, false, fmt.Errorf("failed to fetch menu: %w", err)
+	}
+	return menu, true, nil
+}
+
+// ReplaceMenu публикует меню места на день day: прежнее меню этого дня заменяется целиком
+func ReplaceMenu(db *gorm.DB, placeID uint, day time.Time, dishes []Dish) (Menu, error) {
+	menu := Menu{PlaceID: placeID, Date: time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		old, ok, err := FindMenu(tx, placeID, day)
+		if err != nil {
+			return err
+		}
+		if ok {
+			if err := tx.Where("menu_id = ?", old.ID).Delete(&Dish{}).Error; err != nil {
+				return err
+			}
+			menu.ID = old.ID
+		} else if err := tx.Omit("Dishes").Create(&menu).Error; err != nil {
+			return err
+		}
+		menu.Dishes = make([]Dish, len(dishes))
+		for i, d := range dishes {
+			d.ID = 0
+			d.MenuID = menu.ID
+			d.Position = i + 1
+			menu.Dishes[i] = d
+		}
+		if len(menu.Dishes) == 0 {
+			return nil
+		}
+		return tx.Create(&menu.Dishes).Error
+	})
+	if err != nil {
+		return Menu{}, fmt.Errorf("failed to save menu: %w", err)
+	}
+	return menu, nil
+}
+
+// FormatPrice печатает цену в копейках: «120 ₽», «89,50 ₽»
+func FormatPrice(kop int) string {
+	if kop%100 == 0 {
+		return fmt.Sprintf("%d ₽", kop/100)
+	}
+	return fmt.Sprintf("%d,%02d ₽", kop/100, kop%100)
+}
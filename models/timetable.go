@@ -0,0 +1,189 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Чётность недели занятия
+const (
+	ParityAny  = ""     // каждую неделю
+	ParityOdd  = "odd"  // нечётные недели (числитель)
+	ParityEven = "even" // чётные недели (знаменатель)
+)
+
+// Типы занятий
+const (
+	LessonLecture = "lecture"
+	LessonSeminar = "seminar"
+	LessonLab     = "lab"
+)
+
+// WeekdayShort - короткие названия дней недели, индекс совпадает с Lesson.Weekday
+var WeekdayShort = [...]string{"", "Пн", "Вт", "Ср", "Чт", "Пт", "Сб", "Вс"}
+
+// IsoWeekday - день недели в нумерации Lesson.Weekday (1 — Пн … 7 — Вс)
+func IsoWeekday(t time.Time) int {
+	if t.Weekday() == time.Sunday {
+		return 7
+	}
+	return int(t.Weekday())
+}
+
+// FormatMinute печатает минуты от полуночи как «9:05»
+func FormatMinute(m int) string {
+	return fmt.Sprintf("%d:%02d", m/60, m%60)
+}
+
+// LessonTypeName - подпись типа занятия
+func LessonTypeName(t string) string {
+	switch t {
+	case LessonLecture:
+		return "лекция"
+	case LessonSeminar:
+		return "семинар"
+	case LessonLab:
+		return "лабораторная"
+	default:
+		return t
+	}
+}
+
+// ParityAt - чётность недели, в которую попадает t, относительно начала семестра.
+// Первая неделя семестра — нечётная.
+func ParityAt(semesterStart, t time.Time) string {
+	start := time.Date(semesterStart.Year(), semesterStart.Month(), semesterStart.Day(), 0, 0, 0, 0, t.Location())
+	// выравниваем на понедельник, чтобы неделя менялась ровно в ночь на Пн
+	start = start.AddDate(0, 0, 1-IsoWeekday(start))
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	days := int(math.Round(day.Sub(start).Hours() / 24))
+	weeks := int(math.Floor(float64(days) / 7))
+	if weeks%2 == 0 {
+		return ParityOdd
+	}
+	return ParityEven
+}
+
+// OccursIn - проходит ли занятие на неделе с чётностью parity
+func (l Lesson) OccursIn(parity string) bool {
+	return l.WeekParity == ParityAny || parity == "" || l.WeekParity == parity
+}
+
+// ---- перенос старого текстового Teacher.Schedule в Lesson ----
+
+var (
+	legacySlotRe = regexp.MustCompile(`^(Пн|Вт|Ср|Чт|Пт|Сб|Вс)\s+(\d{1,2}):(\d{2})\s*[–-]\s*(\d{1,2}):(\d{2})$`)
+	legacyRoomRe = regexp.MustCompile(`^Аудитория:\s*(.+)$`)
+)
+
+// parseLegacySchedule разбирает строку вида «Пн 10:00–11:40; Ср 12:00–13:40; Аудитория: А-101».
+// Нераспознанные части не мешают остальным: они возвращаются в bad.
+func parseLegacySchedule(raw string) (lessons []Lesson, bad []string) {
+	var room string
+	for _, part := range strings.Split(raw, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if m := legacyRoomRe.FindStringSubmatch(part); m != nil {
+			room = strings.TrimSpace(m[1])
+			continue
+		}
+		m := legacySlotRe.FindStringSubmatch(part)
+		if m == nil {
+			bad = append(bad, part)
+			continue
+		}
+		wd := 0
+		for i, name := range WeekdayShort {
+			if name == m[1] {
+				wd = i
+			}
+		}
+		num := func(s string) int { n, _ := strconv.Atoi(s); return n }
+		lessons = append(lessons, Lesson{
+			Weekday:     wd,
+			StartMinute: num(m[2])*60 + num(m[3]),
+			EndMinute:   num(m[4])*60 + num(m[5]),
+		})
+	}
+	for i := range lessons {
+		lessons[i].Room = room
+	}
+	return lessons, bad
+}
+
+// LegacySchedule - старое расписание преподавателя, разобранное не целиком.
+// Разобранные части уже в lessons; таблица нужна для ручного переноса остального.
+type LegacySchedule struct {
+	TeacherID uint   `gorm:"primaryKey"`
+	Schedule  string `gorm:"type:text;not null"` // исходная строка teachers.schedule
+	Unparsed  string `gorm:"type:text;not null"` // нераспознанные части через «; »
+}
+
+// MigrateLegacySchedules переносит teachers.schedule в lessons и удаляет колонку.
+// Нераспознанные части строки пропускаются, остальные занятия преподавателя сохраняются;
+// исходная строка и пропущенные части записываются в legacy_schedules.
+func MigrateLegacySchedules(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&Teacher{}, "schedule") {
+		return nil
+	}
+
+	type legacyRow struct {
+		ID       uint
+		Subject  string
+		Schedule string
+	}
+	var rows []legacyRow
+	if err := db.Table("teachers").Select("id, subject, schedule").
+		Where("COALESCE(schedule, '') <> ''").Scan(&rows).Error; err != nil {
+		return fmt.Errorf("read legacy schedules: %w", err)
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var leftovers []LegacySchedule
+		for _, r := range rows {
+			var n int64
+			if err := tx.Model(&Lesson{}).Where("teacher_id = ?", r.ID).Count(&n).Error; err != nil {
+				return err
+			}
+			if n > 0 {
+				continue // уже перенесено
+			}
+			lessons, bad := parseLegacySchedule(r.Schedule)
+			if len(bad) > 0 {
+				log.Warn().Uint("teacher_id", r.ID).Strs("unparsed", bad).
+					Msg("legacy schedule partly parsed, the rest is kept in legacy_schedules")
+				leftovers = append(leftovers, LegacySchedule{TeacherID: r.ID, Schedule: r.Schedule, Unparsed: strings.Join(bad, "; ")})
+			}
+			for i := range lessons {
+				lessons[i].TeacherID = r.ID
+				lessons[i].Subject = r.Subject
+			}
+			if len(lessons) > 0 {
+				if err := tx.Create(&lessons).Error; err != nil {
+					return fmt.Errorf("create lessons for teacher %d: %w", r.ID, err)
+				}
+			}
+		}
+		if len(leftovers) == 0 {
+			return nil
+		}
+		if err := tx.Migrator().AutoMigrate(&LegacySchedule{}); err != nil {
+			return fmt.Errorf("create legacy_schedules: %w", err)
+		}
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&leftovers).Error
+	})
+	if err != nil {
+		return fmt.Errorf("migrate legacy schedules: %w", err)
+	}
+	return db.Migrator().DropColumn(&Teacher{}, "schedule")
+}
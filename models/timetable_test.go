@@ -0,0 +1,145 @@
+package models_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/Karielka/Hackaton_MAX/internal/fixtures"
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// openDB - SQLite во временном каталоге со схемой и демо-данными.
+// Внешние ключи выключены: DropColumn в SQLite пересоздаёт таблицу, и lessons мешали бы этому.
+func openDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(t.TempDir()+"/models.db"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := models.AutoMigrate(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := fixtures.LoadSample(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func date(s string, loc *time.Location) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", s, loc)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParityAt(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	tests := []struct {
+		name  string
+		start string
+		at    string
+		loc   *time.Location
+		want  string
+	}{
+		{"first day", "2025-09-01 00:00", "2025-09-01 10:00", time.UTC, models.ParityOdd},
+		{"first sunday", "2025-09-01 00:00", "2025-09-07 23:59", time.UTC, models.ParityOdd},
+		{"second monday", "2025-09-01 00:00", "2025-09-08 00:00", time.UTC, models.ParityEven},
+		{"third week", "2025-09-01 00:00", "2025-09-17 12:00", time.UTC, models.ParityOdd},
+		{"start midweek aligns to monday", "2025-09-03 00:00", "2025-09-01 09:00", time.UTC, models.ParityOdd},
+		{"start midweek, next monday", "2025-09-03 00:00", "2025-09-08 09:00", time.UTC, models.ParityEven},
+		{"week before start", "2025-09-01 00:00", "2025-08-31 12:00", time.UTC, models.ParityEven},
+		{"across DST change", "2025-10-27 00:00", "2025-11-03 00:30", ny, models.ParityEven},
+		{"after DST change", "2025-10-27 00:00", "2025-11-10 08:00", ny, models.ParityOdd},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := models.ParityAt(date(tt.start, time.UTC), date(tt.at, tt.loc))
+			if got != tt.want {
+				t.Errorf("ParityAt(%s, %s) = %q, want %q", tt.start, tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrateLegacySchedules(t *testing.T) {
+	db := openDB(t)
+	if err := db.Exec("ALTER TABLE `teachers` ADD COLUMN `schedule` text").Error; err != nil {
+		t.Fatal(err)
+	}
+	good := models.Teacher{FullName: "Старый Расписание Хороший", Subject: "Физика", DepartmentID: 1}
+	mixed := models.Teacher{FullName: "Старый Расписание Частичный", Subject: "Химия", DepartmentID: 1}
+	bad := models.Teacher{FullName: "Старый Расписание Плохой", Subject: "Биология", DepartmentID: 1}
+	for _, tc := range []*models.Teacher{&good, &mixed, &bad} {
+		if err := db.Create(tc).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	setSchedule := func(id uint, s string) {
+		t.Helper()
+		if err := db.Table("teachers").Where("id = ?", id).Update("schedule", s).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	lessons := func(id uint) []models.Lesson {
+		t.Helper()
+		var out []models.Lesson
+		if err := db.Where("teacher_id = ?", id).Order("weekday").Find(&out).Error; err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+	setSchedule(good.ID, "Пн 10:00–11:40; Ср 12:00-13:40; Аудитория: А-101")
+	setSchedule(mixed.ID, "Вт 9:00–10:35; по пятницам после обеда; Аудитория: Б-2")
+	setSchedule(bad.ID, "по договорённости")
+
+	if err := models.MigrateLegacySchedules(db); err != nil {
+		t.Fatalf("MigrateLegacySchedules: %v", err)
+	}
+	got := lessons(good.ID)
+	if len(got) != 2 {
+		t.Fatalf("good teacher has %d lessons, want 2", len(got))
+	}
+	if l := got[0]; l.Weekday != 1 || l.StartMinute != 600 || l.EndMinute != 700 || l.Room != "А-101" || l.Subject != "Физика" {
+		t.Errorf("first lesson = %+v", l)
+	}
+	if l := got[1]; l.Weekday != 3 || l.StartMinute != 720 || l.EndMinute != 820 {
+		t.Errorf("second lesson = %+v", l)
+	}
+	// нераспознанная часть не отменяет остальные занятия преподавателя
+	if got := lessons(mixed.ID); len(got) != 1 || got[0].Weekday != 2 || got[0].Room != "Б-2" {
+		t.Errorf("mixed teacher lessons = %+v", got)
+	}
+	if n := len(lessons(bad.ID)); n != 0 {
+		t.Errorf("bad teacher has %d lessons, want 0", n)
+	}
+	if db.Migrator().HasColumn(&models.Teacher{}, "schedule") {
+		t.Error("schedule column kept after migration")
+	}
+
+	// остатки записаны явно: исходная строка и что не разобрано
+	var left []models.LegacySchedule
+	if err := db.Order("teacher_id").Find(&left).Error; err != nil {
+		t.Fatal(err)
+	}
+	want := []models.LegacySchedule{
+		{TeacherID: mixed.ID, Schedule: "Вт 9:00–10:35; по пятницам после обеда; Аудитория: Б-2", Unparsed: "по пятницам после обеда"},
+		{TeacherID: bad.ID, Schedule: "по договорённости", Unparsed: "по договорённости"},
+	}
+	if !reflect.DeepEqual(left, want) {
+		t.Errorf("legacy_schedules = %+v, want %+v", left, want)
+	}
+
+	// повторный запуск без колонки ничего не делает
+	if err := models.MigrateLegacySchedules(db); err != nil {
+		t.Errorf("second run: %v", err)
+	}
+}
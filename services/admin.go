@@ -0,0 +1,435 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// Маршруты администрирования контента (/admin)
+const (
+	AdminMenu          = "admin_menu"
+	AdminList          = "admin/{entity:str}/list/{offset}"
+	AdminItem          = "admin/{entity:str}/{id}"
+	AdminCreate        = "admin/{entity:str}/new"
+	AdminEdit          = "admin/{entity:str}/{id}/edit"
+	AdminDelete        = "admin/{entity:str}/{id}/delete"
+	AdminDeleteConfirm = "admin/{entity:str}/{id}/delete/yes"
+	AdminKeep          = "admin_keep"          // оставить текущее значение поля
+	AdminValue         = "admin_value/{v:str}" // значение поля кнопкой (варианты, ссылки)
+	AdminSave          = "admin_save"
+	AdminCancel        = "admin_cancel"
+)
+
+// ключи Dialog.Data формы
+const (
+	admEntity = "entity"
+	admID     = "id"
+	admStep   = "step"
+	admValue  = "v." // префикс значений полей
+)
+
+// сколько вариантов ссылки показывать кнопками
+const adminRefButtons = 8
+
+func registerAdminRoutes(r *Router) {
+	r.Handle(AdminMenu, func(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, _ Params) error {
+		return adminShowMenu(ctx, sc, upd.Message.Recipient)
+	}, adminOnly, resetDialog)
+	r.Handle(AdminList, adminShowList, adminOnly, resetDialog)
+	r.Handle(AdminItem, adminShowItem, adminOnly, resetDialog)
+	r.Handle(AdminCreate, adminStartForm, adminOnly)
+	r.Handle(AdminEdit, adminStartForm, adminOnly)
+	r.Handle(AdminDelete, adminAskDelete, adminOnly, resetDialog)
+	r.Handle(AdminDeleteConfirm, adminDelete, adminOnly, resetDialog)
+	r.Handle(AdminKeep, adminKeep, adminOnly)
+	r.Handle(AdminValue, adminValue, adminOnly)
+	r.Handle(AdminSave, adminSave, adminOnly)
+	r.Handle(AdminCancel, func(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, _ Params) error {
+		return adminShowMenu(ctx, sc, upd.Message.Recipient)
+	}, adminOnly, resetDialog)
+}
+
+// isAdmin - администратор из ADMIN_IDS или таблицы admins
+func isAdmin(sc Ctx, userID int64) bool {
+	if userID == 0 {
+		return false
+	}
+	if sc.Admins[userID] {
+		return true
+	}
+	var n int64
+	if err := sc.DB.Model(&models.Admin{}).Where("user_id = ?", userID).Count(&n).Error; err != nil {
+		log.Error().Err(err).Int64("user_id", userID).Msg("failed to check admin rights")
+		return false
+	}
+	return n > 0
+}
+
+// adminOnly - middleware: кнопки админки работают только для администраторов
+func adminOnly(next CallbackHandler) CallbackHandler {
+	return func(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+		if !isAdmin(sc, upd.Callback.User.UserId) {
+			return adminReply(ctx, sc, upd.Message.Recipient, "⛔ Недостаточно прав.", nil)
+		}
+		return next(ctx, sc, upd, p)
+	}
+}
+
+// Admin_Handle - команда /admin
+func Admin_Handle(ctx context.Context, sc Ctx, upd *schemes.MessageCreatedUpdate) error {
+	recipient := recipientFromMessage(upd)
+	if !isAdmin(sc, upd.Message.Sender.UserId) {
+		return adminReply(ctx, sc, recipient, "⛔ Команда доступна только администраторам.", nil)
+	}
+	if err := sc.Dialogs.Reset(ctx, peerFromMessage(upd)); err != nil {
+		return err
+	}
+	return adminShowMenu(ctx, sc, recipient)
+}
+
+func adminShowMenu(ctx context.Context, sc Ctx, recipient schemes.Recipient) error {
+	kb := sc.API.NewKeyboardBuilder()
+	for _, e := range adminEntities {
+		kb.AddRow().AddCallback(e.Plural, schemes.POSITIVE, payload(AdminList, e.Key, 0))
+	}
+	kb.AddRow().AddCallback("🏠 Главное меню", schemes.NEGATIVE, BackToMenu)
+	return adminReply(ctx, sc, recipient, "🛠 Администрирование. Что редактируем?", kb)
+}
+
+func adminShowList(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	e := adminEntityByKey(p.Str("entity"))
+	if e == nil {
+		return fmt.Errorf("unknown admin entity: %s", p.Str("entity"))
+	}
+	rows, page, err := e.list(sc.DB, int(p.ID("offset")))
+	if err != nil {
+		return fmt.Errorf("failed to list %s: %w", e.Key, err)
+	}
+
+	kb := sc.API.NewKeyboardBuilder()
+	for _, r := range rows {
+		kb.AddRow().AddCallback(r.Label, schemes.DEFAULT, payload(AdminItem, e.Key, r.ID))
+	}
+	addPager(kb, page, func(offset int) string { return payload(AdminList, e.Key, offset) })
+	kb.AddRow().
+		AddCallback("➕ Добавить", schemes.POSITIVE, payload(AdminCreate, e.Key)).
+		AddCallback("◀️ Админка", schemes.NEGATIVE, AdminMenu)
+	return adminReply(ctx, sc, upd.Message.Recipient, page.Header(e.Plural), kb)
+}
+
+func adminShowItem(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	e := adminEntityByKey(p.Str("entity"))
+	if e == nil {
+		return fmt.Errorf("unknown admin entity: %s", p.Str("entity"))
+	}
+	id := p.ID("id")
+	vals, err := e.load(sc.DB, id)
+	if err != nil {
+		return adminReply(ctx, sc, upd.Message.Recipient, "Запись не найдена.", adminBackKB(sc, e))
+	}
+
+	kb := sc.API.NewKeyboardBuilder()
+	kb.AddRow().
+		AddCallback("✏️ Изменить", schemes.POSITIVE, payload(AdminEdit, e.Key, id)).
+		AddCallback("🗑 Удалить", schemes.NEGATIVE, payload(AdminDelete, e.Key, id))
+	kb.AddRow().AddCallback("◀️ К списку", schemes.DEFAULT, payload(AdminList, e.Key, 0))
+	text := fmt.Sprintf("%s #%d\n\n%s", capitalize(e.Title), id, adminFormat(sc, e, vals))
+	return adminReply(ctx, sc, upd.Message.Recipient, text, kb)
+}
+
+func adminAskDelete(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	e := adminEntityByKey(p.Str("entity"))
+	if e == nil {
+		return fmt.Errorf("unknown admin entity: %s", p.Str("entity"))
+	}
+	id := p.ID("id")
+	vals, err := e.load(sc.DB, id)
+	if err != nil {
+		return adminReply(ctx, sc, upd.Message.Recipient, "Запись не найдена.", adminBackKB(sc, e))
+	}
+	kb := sc.API.NewKeyboardBuilder()
+	kb.AddRow().
+		AddCallback("🗑 Да, удалить", schemes.NEGATIVE, payload(AdminDeleteConfirm, e.Key, id)).
+		AddCallback("Отмена", schemes.DEFAULT, payload(AdminItem, e.Key, id))
+	text := fmt.Sprintf("Удалить %s #%d?\n\n%s", e.Title, id, adminFormat(sc, e, vals))
+	return adminReply(ctx, sc, upd.Message.Recipient, text, kb)
+}
+
+func adminDelete(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	e := adminEntityByKey(p.Str("entity"))
+	if e == nil {
+		return fmt.Errorf("unknown admin entity: %s", p.Str("entity"))
+	}
+	if err := e.delete(sc.DB, p.ID("id")); err != nil {
+		return adminReply(ctx, sc, upd.Message.Recipient, "Не удалось удалить: "+adminErrorText(sc, e, "delete", err), adminBackKB(sc, e))
+	}
+	return adminReply(ctx, sc, upd.Message.Recipient, "🗑 Удалено.", adminBackKB(sc, e))
+}
+
+// ---- пошаговая форма (состояние admin.form) ----
+
+// adminStartForm - создание (без id) или правка записи
+func adminStartForm(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	e := adminEntityByKey(p.Str("entity"))
+	if e == nil {
+		return fmt.Errorf("unknown admin entity: %s", p.Str("entity"))
+	}
+	id := p.ID("id")
+	data := map[string]string{admEntity: e.Key, admID: strconv.FormatUint(uint64(id), 10), admStep: "0"}
+	if id != 0 {
+		vals, err := e.load(sc.DB, id)
+		if err != nil {
+			return adminReply(ctx, sc, upd.Message.Recipient, "Запись не найдена.", adminBackKB(sc, e))
+		}
+		for k, v := range vals {
+			data[admValue+k] = v
+		}
+	}
+	d := Dialog{State: StateAdminForm, Data: data}
+	if err := sc.Dialogs.Enter(ctx, peerFromCallback(upd), d.State, d.Data); err != nil {
+		return err
+	}
+	return adminAskStep(ctx, sc, upd.Message.Recipient, e, d, "")
+}
+
+// Admin_OnMessage - значение очередного поля текстом
+func Admin_OnMessage(ctx context.Context, sc Ctx, upd *schemes.MessageCreatedUpdate, d Dialog) (bool, error) {
+	recipient := recipientFromMessage(upd)
+	if !isAdmin(sc, upd.Message.Sender.UserId) {
+		return true, sc.Dialogs.Reset(ctx, peerFromMessage(upd))
+	}
+	return true, adminAccept(ctx, sc, peerFromMessage(upd), recipient, d, upd.GetText(), false)
+}
+
+func adminValue(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	d, ok := adminForm(ctx, sc, upd)
+	if !ok {
+		return nil
+	}
+	return adminAccept(ctx, sc, peerFromCallback(upd), upd.Message.Recipient, d, p.Str("v"), false)
+}
+
+func adminKeep(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, _ Params) error {
+	d, ok := adminForm(ctx, sc, upd)
+	if !ok {
+		return nil
+	}
+	return adminAccept(ctx, sc, peerFromCallback(upd), upd.Message.Recipient, d, "", true)
+}
+
+// adminForm - активная форма; иначе сообщаем, что она устарела
+func adminForm(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate) (Dialog, bool) {
+	d, ok, err := sc.Dialogs.Get(ctx, peerFromCallback(upd))
+	if err != nil || !ok || d.State != StateAdminForm || adminEntityByKey(d.Data[admEntity]) == nil {
+		_ = adminReply(ctx, sc, upd.Message.Recipient, "Форма устарела, начните заново.", nil)
+		return Dialog{}, false
+	}
+	return d, true
+}
+
+// adminAccept принимает значение текущего поля (keep — оставить как есть) и переходит к следующему
+func adminAccept(ctx context.Context, sc Ctx, peer int64, recipient schemes.Recipient, d Dialog, in string, keep bool) error {
+	e := adminEntityByKey(d.Data[admEntity])
+	if e == nil {
+		return sc.Dialogs.Reset(ctx, peer)
+	}
+	step, _ := strconv.Atoi(d.Data[admStep])
+	if step >= len(e.Fields) {
+		return adminPreview(ctx, sc, recipient, e, d)
+	}
+	f := e.Fields[step]
+
+	key := admValue + f.Key
+	switch {
+	case keep:
+	case strings.TrimSpace(in) == "-":
+		d.Data[key] = ""
+	default:
+		v, err := f.parse(sc.DB, in)
+		if err != nil {
+			return adminAskStep(ctx, sc, recipient, e, d, "⚠️ "+err.Error())
+		}
+		d.Data[key] = v
+	}
+	if f.Required && d.Data[key] == "" {
+		return adminAskStep(ctx, sc, recipient, e, d, "⚠️ Это поле обязательно.")
+	}
+
+	d.Data[admStep] = strconv.Itoa(step + 1)
+	if err := sc.Dialogs.Enter(ctx, peer, StateAdminForm, d.Data); err != nil {
+		return err
+	}
+	if step+1 >= len(e.Fields) {
+		return adminPreview(ctx, sc, recipient, e, d)
+	}
+	return adminAskStep(ctx, sc, recipient, e, d, "")
+}
+
+// adminAskStep - вопрос о текущем поле (warn — что не так с прошлым вводом)
+func adminAskStep(ctx context.Context, sc Ctx, recipient schemes.Recipient, e *adminEntity, d Dialog, warn string) error {
+	step, _ := strconv.Atoi(d.Data[admStep])
+	f := e.Fields[step]
+	cur := d.Data[admValue+f.Key]
+
+	var b strings.Builder
+	if warn != "" {
+		b.WriteString(warn + "\n\n")
+	}
+	action := "Новый объект"
+	if d.Data[admID] != "0" {
+		action = "Правка #" + d.Data[admID]
+	}
+	fmt.Fprintf(&b, "✏️ %s: %s · шаг %d/%d\n\n%s", action, e.Title, step+1, len(e.Fields), f.Label)
+	if f.Required {
+		b.WriteString(" (обязательно)")
+	}
+	if f.Hint != "" {
+		fmt.Fprintf(&b, "\nНапример: %s", f.Hint)
+	}
+	if cur != "" {
+		fmt.Fprintf(&b, "\nСейчас: %s", adminValueText(sc, f, cur))
+	}
+	b.WriteString("\n\nВведите значение")
+	if !f.Required {
+		b.WriteString(" или «-», чтобы очистить")
+	}
+	b.WriteString(".")
+
+	kb := sc.API.NewKeyboardBuilder()
+	switch f.Kind {
+	case fieldEnum:
+		for _, o := range f.Options {
+			kb.AddRow().AddCallback(o, schemes.DEFAULT, payload(AdminValue, o))
+		}
+	case fieldRef:
+		for _, o := range f.Ref.options(sc.DB, adminRefButtons) {
+			kb.AddRow().AddCallback(o.Label, schemes.DEFAULT, payload(AdminValue, o.ID))
+		}
+	}
+	switch {
+	case cur != "":
+		kb.AddRow().AddCallback("⏭ Оставить как есть", schemes.POSITIVE, AdminKeep)
+	case !f.Required:
+		kb.AddRow().AddCallback("⏭ Пропустить", schemes.POSITIVE, AdminKeep)
+	}
+	kb.AddRow().AddCallback("✖️ Отмена", schemes.NEGATIVE, AdminCancel)
+	return adminReply(ctx, sc, recipient, b.String(), kb)
+}
+
+// adminPreview - всё введённое и подтверждение перед сохранением
+func adminPreview(ctx context.Context, sc Ctx, recipient schemes.Recipient, e *adminEntity, d Dialog) error {
+	vals := adminValues(e, d)
+	kb := sc.API.NewKeyboardBuilder()
+	kb.AddRow().
+		AddCallback("✅ Сохранить", schemes.POSITIVE, AdminSave).
+		AddCallback("✖️ Отмена", schemes.NEGATIVE, AdminCancel)
+	text := fmt.Sprintf("Проверьте данные (%s) перед сохранением:\n\n%s", e.Title, adminFormat(sc, e, vals))
+	return adminReply(ctx, sc, recipient, text, kb)
+}
+
+func adminSave(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, _ Params) error {
+	d, ok := adminForm(ctx, sc, upd)
+	if !ok {
+		return nil
+	}
+	e := adminEntityByKey(d.Data[admEntity])
+	id, _ := strconv.ParseUint(d.Data[admID], 10, 64)
+	if err := e.save(sc.DB, uint(id), adminValues(e, d)); err != nil {
+		return adminReply(ctx, sc, upd.Message.Recipient,
+			"Не удалось сохранить: "+adminErrorText(sc, e, "save", err)+"\n\nИсправьте данные или отмените.", adminSaveKB(sc))
+	}
+	if err := sc.Dialogs.Reset(ctx, peerFromCallback(upd)); err != nil {
+		return err
+	}
+	return adminReply(ctx, sc, upd.Message.Recipient, "✅ Сохранено.", adminBackKB(sc, e))
+}
+
+// adminErrorText - причина отказа базы словами; исходная ошибка уходит в лог
+func adminErrorText(sc Ctx, e *adminEntity, op string, err error) string {
+	log.Error().Err(err).Str("entity", e.Key).Str("op", op).Msg("admin write failed")
+	switch err := translateDBError(sc.DB, err); {
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return "такая запись уже есть (совпадает название или другое уникальное поле)."
+	case errors.Is(err, gorm.ErrForeignKeyViolated) && op == "delete":
+		return "на запись ссылаются другие данные. Сначала удалите их или перенесите на другую запись."
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return "связанная запись не найдена — возможно, её уже удалили."
+	}
+	return "ошибка базы данных, подробности в журнале бота."
+}
+
+// ---- утилиты ----
+
+func capitalize(s string) string {
+	r := []rune(s)
+	if len(r) == 0 {
+		return s
+	}
+	return string(unicode.ToUpper(r[0])) + string(r[1:])
+}
+
+func adminValues(e *adminEntity, d Dialog) map[string]string {
+	vals := make(map[string]string, len(e.Fields))
+	for _, f := range e.Fields {
+		vals[f.Key] = d.Data[admValue+f.Key]
+	}
+	return vals
+}
+
+// adminFormat - «Поле: значение» по всем полям формы
+func adminFormat(sc Ctx, e *adminEntity, vals map[string]string) string {
+	var b strings.Builder
+	for _, f := range e.Fields {
+		v := "—"
+		if vals[f.Key] != "" {
+			v = adminValueText(sc, f, vals[f.Key])
+		}
+		fmt.Fprintf(&b, "• %s: %s\n", f.Label, v)
+	}
+	return b.String()
+}
+
+func adminValueText(sc Ctx, f adminField, v string) string {
+	if f.Kind == fieldRef {
+		return f.Ref.name(sc.DB, v)
+	}
+	return v
+}
+
+func adminBackKB(sc Ctx, e *adminEntity) *maxbot.Keyboard {
+	kb := sc.API.NewKeyboardBuilder()
+	kb.AddRow().
+		AddCallback("◀️ К списку", schemes.DEFAULT, payload(AdminList, e.Key, 0)).
+		AddCallback("🛠 Админка", schemes.NEGATIVE, AdminMenu)
+	return kb
+}
+
+func adminSaveKB(sc Ctx) *maxbot.Keyboard {
+	kb := sc.API.NewKeyboardBuilder()
+	kb.AddRow().
+		AddCallback("✅ Сохранить", schemes.POSITIVE, AdminSave).
+		AddCallback("✖️ Отмена", schemes.NEGATIVE, AdminCancel)
+	return kb
+}
+
+func adminReply(ctx context.Context, sc Ctx, recipient schemes.Recipient, text string, kb *maxbot.Keyboard) error {
+	msg := maxbot.NewMessage()
+	setRecipient(msg, recipient)
+	msg.SetText(text)
+	if kb != nil {
+		msg.AddKeyboard(kb)
+	}
+	_, err := sc.API.Send(ctx, msg)
+	return err
+}
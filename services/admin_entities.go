@@ -0,0 +1,369 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+
+	"github.com/Karielka/Hackaton_MAX/internal/media"
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// Описание редактируемых через /admin сущностей.
+//
+// Форма — список полей; Key совпадает с колонкой в БД. Значения в диалоге
+// хранятся строками, в типы колонок переводятся только при сохранении.
+
+type fieldKind int
+
+const (
+	fieldText  fieldKind = iota // произвольный текст
+	fieldURL                    // ссылка http(s)
+	fieldImage                  // картинка: ссылка http(s) или путь к файлу в MEDIA_DIR
+	fieldEnum                   // одно из Options
+	fieldRef                    // ссылка на запись другой таблицы (хранится ID)
+	fieldHours                  // режим работы текстом; при сохранении раскладывается в opening_hours
+)
+
+type adminField struct {
+	Key      string
+	Label    string
+	Kind     fieldKind
+	Required bool
+	Options  []string  // для fieldEnum
+	Ref      *adminRef // для fieldRef
+	Hint     string    // пример ввода
+}
+
+// adminRef - таблица, на которую ссылается поле; ввод — ID или точное название
+type adminRef struct {
+	Table   string
+	NameCol string
+}
+
+var (
+	refCampus     = &adminRef{Table: "campus", NameCol: "short_name"}
+	refFaculty    = &adminRef{Table: "faculties", NameCol: "name"}
+	refDepartment = &adminRef{Table: "departments", NameCol: "name"}
+	refFAQTopic   = &adminRef{Table: "faq_categories", NameCol: "name"}
+)
+
+type adminEntity struct {
+	Key    string // сегмент payload
+	Title  string // «корпус»
+	Plural string // «Корпуса» — кнопка меню
+	Model  func() any
+	Table  string
+	// LabelSQL и Joins — подпись записи в списке
+	LabelSQL string
+	Joins    string
+	// HoursCol - колонка opening_hours со ссылкой на запись (для поля fieldHours)
+	HoursCol string
+	Fields   []adminField
+}
+
+// hoursHint - пример режима работы в формате models.ParseOpeningHours
+const hoursHint = "Пн-Пт 9:00 - 17:00 (обед 13:00 - 14:00); Сб: выходной"
+
+var adminEntities = []*adminEntity{
+	{
+		Key: "campus", Title: "корпус", Plural: "🏫 Корпуса",
+		Model: func() any { return &models.Campus{} }, Table: "campus",
+		LabelSQL: "campus.short_name || ' — ' || campus.full_name",
+		Fields: []adminField{
+			{Key: "short_name", Label: "Короткое название", Required: true, Hint: "ГУК"},
+			{Key: "full_name", Label: "Полное название", Required: true, Hint: "Главный учебный корпус"},
+			{Key: "address", Label: "Адрес"},
+			{Key: "metro", Label: "Метро"},
+			{Key: "description", Label: "Что внутри"},
+			{Key: "image_url", Label: "Фото (ссылка или файл)", Kind: fieldImage, Hint: "https://example.edu/guk.jpg или guk.jpg"},
+			{Key: "map_image_url", Label: "Карта (ссылка или файл)", Kind: fieldImage, Hint: "maps/guk.png"},
+		},
+	},
+	{
+		Key: "place", Title: "место", Plural: "🍽️ Столовые и копирки",
+		Model: func() any { return &models.Place{} }, Table: "places",
+		LabelSQL: "places.name || ' (' || campus.short_name || ')'",
+		Joins:    "LEFT JOIN campus ON campus.id = places.campus_id",
+		HoursCol: "place_id",
+		Fields: []adminField{
+			{Key: "campus_id", Label: "Корпус", Kind: fieldRef, Ref: refCampus, Required: true},
+			{Key: "type", Label: "Тип", Kind: fieldEnum, Options: []string{"canteen", "buffet", "copy"}, Required: true},
+			{Key: "name", Label: "Название", Required: true, Hint: "Столовая №1"},
+			{Key: "location", Label: "Где находится", Hint: "1 этаж, левое крыло"},
+			{Key: "schedule", Label: "Режим работы", Kind: fieldHours, Hint: hoursHint},
+			{Key: "menu_today", Label: "Меню / услуги"},
+			{Key: "menu_url", Label: "Ссылка на меню", Kind: fieldURL},
+		},
+	},
+	{
+		Key: "dean", Title: "деканат", Plural: "📅 Деканаты",
+		Model: func() any { return &models.DeanOffice{} }, Table: "dean_offices",
+		LabelSQL: "'Деканат ' || faculties.name",
+		Joins:    "LEFT JOIN faculties ON faculties.id = dean_offices.faculty_id",
+		HoursCol: "dean_office_id",
+		Fields: []adminField{
+			{Key: "faculty_id", Label: "Факультет", Kind: fieldRef, Ref: refFaculty, Required: true},
+			{Key: "schedule", Label: "Расписание", Kind: fieldHours, Hint: hoursHint},
+			{Key: "contacts", Label: "Контакты"},
+			{Key: "docs_link", Label: "Документы (ссылка)", Kind: fieldURL},
+		},
+	},
+	{
+		Key: "faq", Title: "вопрос FAQ", Plural: "❓ FAQ",
+		Model: func() any { return &models.FAQ{} }, Table: "faqs",
+		LabelSQL: "faqs.question",
+		Fields: []adminField{
+			{Key: "category_id", Label: "Тема", Kind: fieldRef, Ref: refFAQTopic},
+			{Key: "question", Label: "Вопрос", Required: true},
+			{Key: "answer", Label: "Ответ", Required: true},
+		},
+	},
+	{
+		Key: "faq_category", Title: "тема FAQ", Plural: "🗂️ Темы FAQ",
+		Model: func() any { return &models.FAQCategory{} }, Table: "faq_categories",
+		LabelSQL: "faq_categories.name",
+		Fields: []adminField{
+			{Key: "name", Label: "Название", Required: true, Hint: "Общежитие"},
+		},
+	},
+	{
+		Key: "teacher", Title: "преподаватель", Plural: "👤 Преподаватели",
+		Model: func() any { return &models.Teacher{} }, Table: "teachers",
+		LabelSQL: "teachers.full_name",
+		Fields: []adminField{
+			{Key: "full_name", Label: "ФИО", Required: true, Hint: "Иванов Иван Иванович"},
+			{Key: "department_id", Label: "Кафедра", Kind: fieldRef, Ref: refDepartment},
+			{Key: "subject", Label: "Предмет"},
+			{Key: "email", Label: "Почта"},
+			{Key: "office_room", Label: "Кабинет"},
+			{Key: "phone", Label: "Телефон"},
+			{Key: "office_hours", Label: "Консультации", Hint: "Ср 15:30–16:30"},
+		},
+	},
+}
+
+func adminEntityByKey(key string) *adminEntity {
+	for _, e := range adminEntities {
+		if e.Key == key {
+			return e
+		}
+	}
+	return nil
+}
+
+// adminRow - запись списка
+type adminRow struct {
+	ID    uint
+	Label string
+}
+
+func (e *adminEntity) list(db *gorm.DB, offset int) ([]adminRow, Page, error) {
+	var total int64
+	if err := db.Table(e.Table).Count(&total).Error; err != nil {
+		return nil, Page{}, err
+	}
+	page := newPage(offset, int(total))
+	q := db.Table(e.Table).Select(e.Table + ".id AS id, COALESCE(" + e.LabelSQL + ", '#' || " + e.Table + ".id) AS label")
+	if e.Joins != "" {
+		q = q.Joins(e.Joins)
+	}
+	var rows []adminRow
+	err := q.Order(e.Table + ".id").Offset(page.Offset).Limit(PageSize).Scan(&rows).Error
+	return rows, page, err
+}
+
+// load читает запись в строковые значения полей формы
+func (e *adminEntity) load(db *gorm.DB, id uint) (map[string]string, error) {
+	row := map[string]any{}
+	if err := db.Table(e.Table).Where("id = ?", id).Take(&row).Error; err != nil {
+		return nil, err
+	}
+	vals := make(map[string]string, len(e.Fields))
+	for _, f := range e.Fields {
+		switch v := row[f.Key].(type) {
+		case nil:
+		case []byte:
+			vals[f.Key] = string(v)
+		default:
+			vals[f.Key] = fmt.Sprint(v)
+		}
+		if f.Kind == fieldRef && vals[f.Key] == "0" {
+			vals[f.Key] = ""
+		}
+	}
+	return vals, nil
+}
+
+// save создаёт (id == 0) или обновляет запись. Изменённый режим работы (fieldHours)
+// заменяет недельные часы записи, чтобы «Открыто сейчас» не расходилось с текстом;
+// пустой текст часы не трогает (их можно вести через REST API без подписи).
+func (e *adminEntity) save(db *gorm.DB, id uint, vals map[string]string) error {
+	cols := make(map[string]any, len(e.Fields))
+	var hours *string // новый текст режима работы
+	for _, f := range e.Fields {
+		if v := vals[f.Key]; f.Kind == fieldHours && v != "" {
+			hours = &v
+		}
+		v := vals[f.Key]
+		if f.Kind == fieldRef {
+			n, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				cols[f.Key] = nil // не указано
+				continue
+			}
+			cols[f.Key] = uint(n)
+			continue
+		}
+		cols[f.Key] = v
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if hours != nil && id != 0 {
+			old, err := e.load(tx, id)
+			if err != nil {
+				return err
+			}
+			for _, f := range e.Fields {
+				if f.Kind == fieldHours && old[f.Key] == *hours {
+					hours = nil // текст не менялся — часы могли уточнить через API
+				}
+			}
+		}
+		if id == 0 {
+			if err := tx.Model(e.Model()).Create(cols).Error; err != nil {
+				return err
+			}
+			n, err := strconv.ParseUint(fmt.Sprint(cols["id"]), 10, 64)
+			if err != nil {
+				return fmt.Errorf("failed to get id of new %s: %w", e.Key, err)
+			}
+			id = uint(n)
+		} else if err := tx.Model(e.Model()).Where("id = ?", id).Updates(cols).Error; err != nil {
+			return err
+		}
+		if hours == nil {
+			return nil
+		}
+		return e.replaceHours(tx, id, *hours)
+	})
+}
+
+// replaceHours - недельные часы записи из текста (формат проверен в parse)
+func (e *adminEntity) replaceHours(tx *gorm.DB, id uint, text string) error {
+	weekly, err := models.ParseOpeningHours(text)
+	if err != nil {
+		return fmt.Errorf("failed to parse hours of %s %d: %w", e.Key, id, err)
+	}
+	if err := tx.Where(e.HoursCol+" = ?", id).Delete(&models.OpeningHours{}).Error; err != nil {
+		return fmt.Errorf("failed to delete hours of %s %d: %w", e.Key, id, err)
+	}
+	if len(weekly) == 0 {
+		return nil // все дни выходные
+	}
+	for i := range weekly {
+		switch e.HoursCol {
+		case "place_id":
+			weekly[i].PlaceID = &id
+		case "dean_office_id":
+			weekly[i].DeanOfficeID = &id
+		}
+	}
+	if err := tx.Create(&weekly).Error; err != nil {
+		return fmt.Errorf("failed to save hours of %s %d: %w", e.Key, id, err)
+	}
+	return nil
+}
+
+func (e *adminEntity) delete(db *gorm.DB, id uint) error {
+	return db.Delete(e.Model(), id).Error
+}
+
+// parse проверяет ввод для поля и возвращает значение для хранения
+func (f adminField) parse(db *gorm.DB, in string) (string, error) {
+	in = strings.TrimSpace(in)
+	if in == "" {
+		return "", errors.New("пустое значение")
+	}
+	switch f.Kind {
+	case fieldURL:
+		if !strings.HasPrefix(in, "http://") && !strings.HasPrefix(in, "https://") {
+			return "", errors.New("нужна ссылка, начинающаяся с http:// или https://")
+		}
+	case fieldImage:
+		if err := media.Validate(in); err != nil {
+			return "", errors.New("нужна ссылка http(s) или путь к файлу внутри MEDIA_DIR (без «..»)")
+		}
+	case fieldEnum:
+		for _, o := range f.Options {
+			if strings.EqualFold(o, in) {
+				return o, nil
+			}
+		}
+		return "", fmt.Errorf("допустимые значения: %s", strings.Join(f.Options, ", "))
+	case fieldHours:
+		if _, err := models.ParseOpeningHours(in); err != nil {
+			return "", errors.New("не удалось разобрать режим работы: каждая строка — дни и часы, например «" +
+				hoursHint + "». Особые дни задаются через REST API")
+		}
+	case fieldRef:
+		id, err := f.Ref.resolve(db, in)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatUint(uint64(id), 10), nil
+	}
+	return in, nil
+}
+
+// resolve - ID по номеру или точному названию (регистр и ё/е не важны, как в sameName)
+func (r *adminRef) resolve(db *gorm.DB, in string) (uint, error) {
+	var rows []adminRow
+	q := db.Table(r.Table).Select("id, " + r.NameCol + " AS label")
+	n, numErr := strconv.ParseUint(in, 10, 64)
+	if numErr == nil {
+		q = q.Where("id = ?", n)
+	}
+	if err := q.Scan(&rows).Error; err != nil {
+		log.Error().Err(err).Str("table", r.Table).Msg("admin reference lookup failed")
+		return 0, errors.New("не удалось проверить значение, попробуйте ещё раз")
+	}
+	var ids []uint
+	for _, row := range rows {
+		if numErr == nil || sameName(row.Label, in) {
+			ids = append(ids, row.ID)
+		}
+	}
+	switch len(ids) {
+	case 0:
+		return 0, fmt.Errorf("запись «%s» не найдена", in)
+	case 1:
+		return ids[0], nil
+	default:
+		return 0, fmt.Errorf("«%s» неоднозначно, укажите номер", in)
+	}
+}
+
+// name - подпись записи по ID (для предпросмотра)
+func (r *adminRef) name(db *gorm.DB, id string) string {
+	var names []string
+	db.Table(r.Table).Where("id = ?", id).Limit(1).Pluck(r.NameCol, &names)
+	if len(names) == 0 {
+		return "#" + id
+	}
+	return fmt.Sprintf("%s (#%s)", names[0], id)
+}
+
+// options - варианты для кнопок, если их немного
+func (r *adminRef) options(db *gorm.DB, limit int) []adminRow {
+	var rows []adminRow
+	db.Table(r.Table).Select("id, " + r.NameCol + " AS label").
+		Order(r.NameCol).Limit(limit + 1).Scan(&rows)
+	if len(rows) > limit {
+		return nil
+	}
+	return rows
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+func TestAdminErrorText(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(t.TempDir()+"/admin.db?_pragma=foreign_keys(1)"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := models.AutoMigrate(db); err != nil {
+		t.Fatal(err)
+	}
+	sc := Ctx{DB: db}
+	faq := adminEntityByKey("faq_category")
+	teacher := adminEntityByKey("teacher")
+	if err := faq.save(db, 0, map[string]string{"name": "Общежитие"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		e    *adminEntity
+		op   string
+		err  error
+		want string
+	}{
+		{"unique", faq, "save", faq.save(db, 0, map[string]string{"name": "Общежитие"}), "такая запись уже есть"},
+		{"missing ref", teacher, "save", teacher.save(db, 0, map[string]string{"full_name": "Иванов И. И.", "department_id": "999"}), "связанная запись не найдена"},
+		{"in use", teacher, "delete", gorm.ErrForeignKeyViolated, "на запись ссылаются другие данные"},
+		{"other", teacher, "delete", gorm.ErrInvalidDB, "ошибка базы данных"},
+	} {
+		if tt.err == nil {
+			t.Fatalf("%s: want a database error", tt.name)
+		}
+		got := adminErrorText(sc, tt.e, tt.op, tt.err)
+		if !strings.HasPrefix(got, tt.want) {
+			t.Errorf("%s: %q, want %q…", tt.name, got, tt.want)
+		}
+		if strings.Contains(strings.ToLower(got), "constraint") {
+			t.Errorf("%s: driver text leaked: %q", tt.name, got)
+		}
+	}
+}
+
+func TestAdminRefResolve(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(t.TempDir()+"/ref.db"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := models.AutoMigrate(db); err != nil {
+		t.Fatal(err)
+	}
+	facs := []models.Faculty{{Name: "Лёгкая промышленность"}, {Name: "ИУ"}}
+	if err := db.Create(&facs).Error; err != nil {
+		t.Fatal(err)
+	}
+	for in, want := range map[string]uint{
+		"ЛЕГКАЯ ПРОМЫШЛЕННОСТЬ":   facs[0].ID,
+		" лёгкая  промышленность": facs[0].ID,
+		"иу": facs[1].ID,
+		"2":  facs[1].ID,
+	} {
+		if got, err := refFaculty.resolve(db, strings.TrimSpace(in)); err != nil || got != want {
+			t.Errorf("resolve(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"легкая", "99"} {
+		if _, err := refFaculty.resolve(db, in); err == nil {
+			t.Errorf("resolve(%q): want an error", in)
+		}
+	}
+}
+
+func TestAdminSaveHours(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(t.TempDir()+"/hours.db?_pragma=foreign_keys(1)"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := models.AutoMigrate(db); err != nil {
+		t.Fatal(err)
+	}
+	campus := models.Campus{ShortName: "ГУК", FullName: "Главный учебный корпус"}
+	if err := db.Create(&campus).Error; err != nil {
+		t.Fatal(err)
+	}
+	place := adminEntityByKey("place")
+	vals := map[string]string{"campus_id": fmt.Sprint(campus.ID), "type": "canteen", "name": "Столовая", "schedule": ""}
+	hours := func(id uint) []string {
+		t.Helper()
+		var rows []models.OpeningHours
+		if err := db.Where("place_id = ?", id).Order("weekday, open_minute").Find(&rows).Error; err != nil {
+			t.Fatal(err)
+		}
+		out := make([]string, len(rows))
+		for i, h := range rows {
+			out[i] = fmt.Sprintf("%d %d-%d", h.Weekday, h.OpenMinute, h.CloseMinute)
+		}
+		return out
+	}
+	save := func(id uint, schedule string) uint {
+		t.Helper()
+		vals["schedule"] = schedule
+		if err := place.save(db, id, vals); err != nil {
+			t.Fatal(err)
+		}
+		var p models.Place
+		if err := db.Where("name = ?", "Столовая").First(&p).Error; err != nil {
+			t.Fatal(err)
+		}
+		return p.ID
+	}
+
+	id := save(0, "Пн-Вт 9:00 - 17:00 (обед 13:00 - 14:00); Сб: выходной")
+	want := []string{"1 540-780", "1 840-1020", "2 540-780", "2 840-1020"}
+	if got := hours(id); !slices.Equal(got, want) {
+		t.Errorf("created: %q, want %q", got, want)
+	}
+	save(id, "Пт 10:00 - 12:00")
+	if got := hours(id); !slices.Equal(got, []string{"5 600-720"}) {
+		t.Errorf("changed text: %q", got)
+	}
+
+	// тот же текст и пустой текст не трогают часы, уточнённые через API
+	if err := db.Create(&models.OpeningHours{PlaceID: &id, Weekday: 6, OpenMinute: 600, CloseMinute: 660}).Error; err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"5 600-720", "6 600-660"}
+	save(id, "Пт 10:00 - 12:00")
+	if got := hours(id); !slices.Equal(got, want) {
+		t.Errorf("same text: %q, want %q", got, want)
+	}
+	save(id, "")
+	if got := hours(id); !slices.Equal(got, want) {
+		t.Errorf("empty text: %q, want %q", got, want)
+	}
+
+	f := place.Fields[slices.IndexFunc(place.Fields, func(f adminField) bool { return f.Key == "schedule" })]
+	if _, err := f.parse(db, "круглосуточно"); err == nil {
+		t.Errorf("free-text schedule accepted")
+	}
+	if v, err := f.parse(db, " Пн-Пт 9:00 - 17:00 "); err != nil || v != "Пн-Пт 9:00 - 17:00" {
+		t.Errorf("parse = %q, %v", v, err)
+	}
+}
+
+func TestIsAdminDBError(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(t.TempDir()+"/empty.db"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// таблицы admins нет: ошибка базы — не администратор (и запись в журнале)
+	if isAdmin(Ctx{DB: db}, 42) {
+		t.Errorf("isAdmin without admins table = true")
+	}
+	if !isAdmin(Ctx{DB: db, Admins: map[int64]bool{42: true}}, 42) {
+		t.Errorf("admin from config rejected")
+	}
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+
+	"github.com/Karielka/Hackaton_MAX/internal/calendar"
+)
+
+// «📅 Экспорт в календарь»: .ics файлом в чат и ссылка для подписки (если задан PUBLIC_URL)
+const (
+	ICS_Teacher = "ics/teacher/{id}" // расписание преподавателя
+	ICS_Group   = "ics/group/{id}"   // расписание группы
+)
+
+func registerCalendarRoutes(r *Router) {
+	r.Handle(ICS_Teacher, func(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+		return icsSend(ctx, sc, upd.Message.Recipient, calendar.ForTeacher, p.ID("id"),
+			calendar.TeacherPath, "👤 К карточке", payload(FT_Card, p.ID("id")))
+	})
+	r.Handle(ICS_Group, func(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+		return icsSend(ctx, sc, upd.Message.Recipient, calendar.ForGroup, p.ID("id"),
+			calendar.GroupPath, "📅 К расписанию", payload(TT_Show, p.ID("id"), ttWeek))
+	})
+}
+
+// icsSend собирает календарь, загружает его в MAX и отправляет файлом.
+// path — адрес подписки на HTTP-сервере, backText/backPayload — кнопка возврата.
+func icsSend(ctx context.Context, sc Ctx, recipient schemes.Recipient, load calendar.Loader, id uint,
+	path, backText, backPayload string) error {
+	kb := sc.API.NewKeyboardBuilder()
+	kb.AddRow().
+		AddCallback(backText, schemes.DEFAULT, backPayload).
+		AddCallback("🏠 Главное меню", schemes.NEGATIVE, BackToMenu)
+
+	cal, err := load(sc.DB, id, sc.now(), Location)
+	if errors.Is(err, calendar.ErrNotFound) {
+		return ftSend(ctx, sc, recipient, "Расписание не найдено.", kb)
+	}
+	if err != nil {
+		return err
+	}
+	if cal.Semester == nil || len(cal.Lessons) == 0 {
+		return ftSend(ctx, sc, recipient, "Расписание на этот семестр пока не загружено — выгружать нечего.", kb)
+	}
+
+	file, err := sc.API.UploadFile(ctx, icsFileName(cal.Name), cal.Encode())
+	if err != nil {
+		_ = ftSend(ctx, sc, recipient, "Не удалось отправить файл, попробуйте позже.", kb)
+		return fmt.Errorf("failed to upload calendar: %w", err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📅 %s — %s (%s – %s)\n", cal.Name, cal.Semester.Name,
+		cal.Semester.StartDate.Format("02.01.2006"), cal.Semester.EndDate.Format("02.01.2006"))
+	b.WriteString("Откройте файл, чтобы добавить занятия в календарь телефона.")
+	if sc.PublicURL != "" {
+		link := strings.TrimRight(sc.PublicURL, "/") + fmt.Sprintf(path, id)
+		fmt.Fprintf(&b, "\n\nЧтобы календарь обновлялся сам, подпишитесь по ссылке:\n%s", link)
+		kb.AddRow().AddLink("🔗 Подписаться на календарь", schemes.DEFAULT, link)
+	}
+
+	msg := maxbot.NewMessage()
+	setRecipient(msg, recipient)
+	msg.SetText(b.String()).AddFile(file).AddKeyboard(kb)
+	_, err = sc.API.Send(ctx, msg)
+	return err
+}
+
+// icsFileName - имя файла без символов, которые не любят файловые системы
+func icsFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" {
+		name = "timetable"
+	}
+	return name + ".ics"
+}
@@ -3,13 +3,24 @@ package services
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/Karielka/Hackaton_MAX/models"
 	maxbot "github.com/max-messenger/max-bot-api-client-go"
 	"github.com/max-messenger/max-bot-api-client-go/schemes"
 )
 
+// Маршруты кнопок корпусов
+const (
+	CampusCard    = "campus/{id}"
+	CampusShowMap = "campus/{id}/map"
+)
+
+func registerCampusRoutes(r *Router) {
+	r.Handle(ServiceCampusInfo, noParams(Campus_Handle), resetDialog)
+	r.Handle(CampusCard, handleCampusSelection)
+	r.Handle(CampusShowMap, handleCampusMap)
+}
+
 // Campus_Handle - обработчик меню "Корпуса"
 func Campus_Handle(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate) error {
 	return showCampusSelection(ctx, sc, upd.Message.Recipient)
@@ -26,41 +37,39 @@ func showCampusSelection(ctx context.Context, sc Ctx, recipient schemes.Recipien
 		msg := maxbot.NewMessage()
 		setRecipient(msg, recipient)
 		msg.SetText("Информация о корпусах временно недоступна.")
-		_, err := sc.API.Messages.Send(ctx, msg)
+		_, err := sc.API.Send(ctx, msg)
 		return err
 	}
 
-	kb := sc.API.Messages.NewKeyboardBuilder()
+	kb := sc.API.NewKeyboardBuilder()
 
 	for i := 0; i < len(campuses); i += 2 {
 		row := kb.AddRow()
-		row.AddCallback(campuses[i].ShortName, schemes.POSITIVE, fmt.Sprintf("campus_%d", campuses[i].ID))
+		row.AddCallback(campuses[i].ShortName, schemes.POSITIVE, payload(CampusCard, campuses[i].ID))
 
 		if i+1 < len(campuses) {
-			row.AddCallback(campuses[i+1].ShortName, schemes.POSITIVE, fmt.Sprintf("campus_%d", campuses[i+1].ID))
+			row.AddCallback(campuses[i+1].ShortName, schemes.POSITIVE, payload(CampusCard, campuses[i+1].ID))
 		}
 	}
 
-	kb.AddRow().AddCallback("◀️ Назад", schemes.NEGATIVE, "back_to_menu")
+	kb.AddRow().AddCallback("◀️ Назад", schemes.NEGATIVE, BackToMenu)
 
 	msg := maxbot.NewMessage()
 	setRecipient(msg, recipient)
 	msg.SetText("🏫 Выберите корпус:").AddKeyboard(kb)
 
-	_, err := sc.API.Messages.Send(ctx, msg)
+	_, err := sc.API.Send(ctx, msg)
 	return err
 }
 
 // handleCampusSelection - обработчик выбора конкретного корпуса
-func handleCampusSelection(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate) error {
-	campusID := strings.TrimPrefix(upd.Callback.Payload, "campus_")
-
+func handleCampusSelection(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
 	var campus models.Campus
-	if err := sc.DB.First(&campus, campusID).Error; err != nil {
+	if err := sc.DB.First(&campus, p.ID("id")).Error; err != nil {
 		msg := maxbot.NewMessage()
 		setRecipient(msg, upd.Message.Recipient)
 		msg.SetText("Корпус не найден.")
-		_, err := sc.API.Messages.Send(ctx, msg)
+		_, err := sc.API.Send(ctx, msg)
 		return err
 	}
 
@@ -78,88 +87,28 @@ func sendCampusInfo(ctx context.Context, sc Ctx, campus models.Campus, recipient
 		campus.Description,
 	)
 
-	kb := sc.API.Messages.NewKeyboardBuilder()
+	kb := sc.API.NewKeyboardBuilder()
 	kb.AddRow().
-		AddCallback("🗺️ Показать на карте", schemes.POSITIVE, fmt.Sprintf("%s_%d", CampusShowMap, campus.ID))
+		AddCallback("🗺️ Показать на карте", schemes.POSITIVE, payload(CampusShowMap, campus.ID))
+	favButton(kb, models.FavCampus, campus.ID)
 	kb.AddRow().
 		AddCallback("◀️ К списку корпусов", schemes.NEGATIVE, ServiceCampusInfo).
-		AddCallback("🏠 Главное меню", schemes.NEGATIVE, "back_to_menu")
-
-	//TODO сделать чтоб фотка была в виде токена - как - хз
-
-	//if campus.ImageURL != "" {
-	//	photoMsg := maxbot.NewMessage().AddPhoto()
-	//	setRecipient(photoMsg, recipient)
-	//	if _, err := sc.API.Messages.Send(ctx, photoMsg); err != nil {
-	//		// Логируем ошибку, но продолжаем отправлять текст
-	//		fmt.Printf("Failed to send image: %v\n", err)
-	//	}
-	//}
+		AddCallback("🏠 Главное меню", schemes.NEGATIVE, BackToMenu)
 
-	msg := maxbot.NewMessage()
-	setRecipient(msg, recipient)
-	msg.SetText(text).AddKeyboard(kb)
-
-	_, err := sc.API.Messages.Send(ctx, msg)
-	return err
+	return sendWithPhoto(ctx, sc, recipient, campus.ImageURL, text, kb)
 }
 
 // handleCampusMap - обработчик кнопки "Показать на карте"
-func handleCampusMap(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate) error {
-	payload := strings.TrimPrefix(upd.Callback.Payload, CampusShowMap+"_")
-
+func handleCampusMap(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
 	var campus models.Campus
-	if err := sc.DB.First(&campus, payload).Error; err != nil {
+	if err := sc.DB.First(&campus, p.ID("id")).Error; err != nil {
 		msg := maxbot.NewMessage()
 		setRecipient(msg, upd.Message.Recipient)
 		msg.SetText("Информация о расположении корпуса временно недоступна.")
-		_, err := sc.API.Messages.Send(ctx, msg)
+		_, err := sc.API.Send(ctx, msg)
 		return err
 	}
 
-	//TODO - фотка карты пока не сделана
-
-	//if campus.MapImageURL != "" {
-	//	msg := maxbot.NewMessage()
-	//	setRecipient(msg, upd.Message.Recipient)
-	//	msg.SetImage(campus.MapImageURL)
-	//	msg.SetText(fmt.Sprintf("🗺️ %s на карте", campus.FullName))
-	//
-	//	_, err := sc.API.Messages.Send(ctx, msg)
-	//	return err
-	//}
-
-	msg := maxbot.NewMessage()
-	setRecipient(msg, upd.Message.Recipient)
-	msg.SetText(fmt.Sprintf("🗺️ %s\n📍 %s\n🚇 %s", campus.FullName, campus.Address, campus.Metro))
-
-	_, err := sc.API.Messages.Send(ctx, msg)
-	return err
-}
-
-// Campus_OnMessage - обработка текстовых запросов по корпусам
-func Campus_OnMessage(ctx context.Context, sc Ctx, upd *schemes.MessageCreatedUpdate) (bool, error) {
-	text := strings.TrimSpace(upd.Message.Body.Text)
-	if text == "" {
-		return false, nil
-	}
-
-	var campus models.Campus
-	query := sc.DB.Where("LOWER(short_name) = LOWER(?) OR LOWER(full_name) LIKE LOWER(?)",
-		text, "%"+text+"%")
-
-	if err := query.First(&campus).Error; err != nil {
-		// Корпус не найден
-		return false, nil
-	}
-
-	recipient := schemes.Recipient{}
-	if upd.Message.Recipient.ChatId != 0 {
-		recipient.ChatId = upd.Message.Recipient.ChatId
-	} else {
-		recipient.UserId = upd.Message.Sender.UserId
-	}
-
-	err := sendCampusInfo(ctx, sc, campus, recipient)
-	return true, err
+	text := fmt.Sprintf("🗺️ %s\n📍 %s\n🚇 %s", campus.FullName, campus.Address, campus.Metro)
+	return sendWithPhoto(ctx, sc, upd.Message.Recipient, campus.MapImageURL, text, nil)
 }
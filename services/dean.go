@@ -2,9 +2,9 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
-	"sync"
 
 	maxbot "github.com/max-messenger/max-bot-api-client-go"
 	"github.com/max-messenger/max-bot-api-client-go/schemes"
@@ -17,46 +17,36 @@ import (
 const (
 	Dean_FindByFaculty     = "dean_find_by_faculty"
 	Dean_BackToFacultyMenu = "dean_back_to_faculty_menu"
+	Dean_Faculty           = "dean/faculty/{id}"            // расписание деканата выбранного факультета
+	Dean_FacultyPage       = "dean/search/{offset}/{q:str}" // страница найденных факультетов
 )
 
-type deanState struct {
-	WaitFacultyName bool // ждём ввод названия факультета
+func registerDeanRoutes(r *Router) {
+	r.Handle(ServiceDeanSchedule, noParams(Dean_Handle), resetDialog)
+	r.Handle(Dean_BackToFacultyMenu, noParams(Dean_ShowModeMenu), resetDialog)
+	r.Handle(Dean_Faculty, deanSelectFaculty, resetDialog)
+	r.Handle(Dean_FacultyPage, deanShowFacultyPage)
 }
 
-var (
-	deanMu   sync.RWMutex
-	deanData = map[int64]deanState{}
-)
-
-func deanPeerFromCallback(upd *schemes.MessageCallbackUpdate) int64 {
-	if upd.Message.Recipient.ChatId != 0 {
-		return upd.Message.Recipient.ChatId
-	}
-	if upd.Message.Recipient.UserId != 0 {
-		return upd.Message.Recipient.UserId
+// Dean_Handle - кнопка «Деканат»: сразу деканат своего факультета из профиля,
+// «К выбору факультета» — любой другой
+func Dean_Handle(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate) error {
+	user, ok, err := loadUser(sc, upd.Callback.User.UserId)
+	if err != nil {
+		return err
 	}
-	return 0
-}
-func deanPeerFromMessage(upd *schemes.MessageCreatedUpdate) int64 {
-	if upd.Message.Recipient.ChatId != 0 {
-		return upd.Message.Recipient.ChatId
+	if ok && user.Faculty != nil {
+		return deanShowSchedule(ctx, sc, upd.Message.Recipient, *user.Faculty)
 	}
-	return upd.Message.Sender.UserId
-}
-func deanSet(peer int64, st deanState) { deanMu.Lock(); deanData[peer] = st; deanMu.Unlock() }
-func deanGet(peer int64) (deanState, bool) {
-	deanMu.RLock()
-	s, ok := deanData[peer]
-	deanMu.RUnlock()
-	return s, ok
+	return Dean_ShowModeMenu(ctx, sc, upd)
 }
-func deanClear(peer int64) { deanMu.Lock(); delete(deanData, peer); deanMu.Unlock() }
 
 // --- шаг 1: показать подсказку и включить ожидание ввода
 // ТВОЮ Dean_ShowModeMenu переиспользуем как "попросить ввести факультет"
 func Dean_ShowModeMenu(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate) error {
-	peer := deanPeerFromCallback(upd)
-	deanSet(peer, deanState{WaitFacultyName: true})
+	if err := sc.Dialogs.Enter(ctx, peerFromCallback(upd), StateDeanFaculty, nil); err != nil {
+		return err
+	}
 
 	msg := maxbot.NewMessage()
 	if upd.Message.Recipient.ChatId != 0 {
@@ -65,95 +55,142 @@ func Dean_ShowModeMenu(ctx context.Context, sc Ctx, upd *schemes.MessageCallback
 		msg.SetUser(upd.Message.Recipient.UserId)
 	}
 	msg.SetText("Введите название факультета (например, «ИУ»):")
-	_, err := sc.API.Messages.Send(ctx, msg)
+	_, err := sc.API.Send(ctx, msg)
 	return err
 }
 
-// --- шаг 2: обработать текст и вернуть расписание деканата факультета
-func Dean_OnMessage(ctx context.Context, sc Ctx, upd *schemes.MessageCreatedUpdate) (bool, error) {
-	peer := deanPeerFromMessage(upd)
-	st, ok := deanGet(peer)
-	if !ok || !st.WaitFacultyName {
-		return false, nil // не наш сценарий — пусть разбирают другие
-	}
+// --- шаг 2: обработать текст и вернуть расписание деканата факультета (состояние dean.faculty)
+func Dean_OnMessage(ctx context.Context, sc Ctx, upd *schemes.MessageCreatedUpdate, _ Dialog) (bool, error) {
+	peer := peerFromMessage(upd)
 
 	query := strings.TrimSpace(upd.GetText())
 	if query == "" {
 		return true, deanReplyMsg(ctx, sc, upd, "Введите название факультета.")
 	}
 
-	// Находим факультеты по ILIKE
+	ids, err := matchIDs(sc, &models.Faculty{}, "name", query)
+	if err != nil {
+		return true, deanReplyMsg(ctx, sc, upd, failedText(err, "dean: find faculty"))
+	}
 	var facs []models.Faculty
-	if err := sc.DB.Where("name ILIKE ?", "%"+query+"%").
-		Order("name").Limit(10).Find(&facs).Error; err != nil {
-		return true, deanReplyMsg(ctx, sc, upd, fmt.Sprintf("Ошибка запроса: %v", err))
+	if err := sc.DB.Where("id IN ?", ids).Order("name").Find(&facs).Error; err != nil {
+		return true, deanReplyMsg(ctx, sc, upd, failedText(err, "dean: find faculty"))
+	}
+	// Точное совпадение (регистр, ё/е не важны) или единственный найденный — сразу расписание
+	for _, f := range facs {
+		if sameName(f.Name, query) {
+			facs = []models.Faculty{f}
+			break
+		}
 	}
-
 	if len(facs) == 0 {
 		return true, deanReplyMsg(ctx, sc, upd, "Факультеты не найдены. Попробуйте иначе.")
 	}
-
-	// Если найден один — показываем расписание
 	if len(facs) == 1 {
-		if err := deanShowSchedule(ctx, sc, upd, facs[0]); err != nil {
+		if err := deanShowSchedule(ctx, sc, recipientFromMessage(upd), facs[0]); err != nil {
 			return true, err
 		}
-		deanClear(peer)
-		return true, nil
+		return true, sc.Dialogs.Reset(ctx, peer)
 	}
 
-	// Попробуем точное совпадение без регистра
-	lq := strings.ToLower(query)
-	for _, f := range facs {
-		if strings.ToLower(f.Name) == lq {
-			if err := deanShowSchedule(ctx, sc, upd, f); err != nil {
-				return true, err
-			}
-			deanClear(peer)
-			return true, nil
-		}
+	// Несколько совпадений: список с кнопками, остаёмся в состоянии dean.faculty
+	return true, deanSendFacultyPage(ctx, sc, recipientFromMessage(upd), query, 0)
+}
+
+// deanSelectFaculty - выбор факультета из списка
+func deanSelectFaculty(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	var fac models.Faculty
+	if err := sc.DB.First(&fac, p.ID("id")).Error; err != nil {
+		msg := maxbot.NewMessage()
+		setRecipient(msg, upd.Message.Recipient)
+		msg.SetText("Факультет не найден.").AddKeyboard(deanScheduleKB(sc))
+		_, err := sc.API.Send(ctx, msg)
+		return err
+	}
+	return deanShowSchedule(ctx, sc, upd.Message.Recipient, fac)
+}
+
+// deanShowFacultyPage - листание списка факультетов
+func deanShowFacultyPage(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	return deanSendFacultyPage(ctx, sc, upd.Message.Recipient, decodeQuery(p.Str("q")), int(p.ID("offset")))
+}
+
+// deanSendFacultyPage - страница найденных факультетов, по кнопке на каждый
+func deanSendFacultyPage(ctx context.Context, sc Ctx, recipient schemes.Recipient, query string, offset int) error {
+	ids, err := matchIDs(sc, &models.Faculty{}, "name", query)
+	if err != nil {
+		return err
+	}
+	base := func() *gorm.DB {
+		return sc.DB.Model(&models.Faculty{}).Where("id IN ?", ids)
+	}
+	var total int64
+	if err := base().Count(&total).Error; err != nil {
+		return fmt.Errorf("failed to count faculties: %w", err)
+	}
+	page := newPage(offset, int(total))
+
+	var facs []models.Faculty
+	if err := base().Order("name").Offset(page.Offset).Limit(PageSize).Find(&facs).Error; err != nil {
+		return fmt.Errorf("failed to fetch faculties: %w", err)
 	}
 
-	// Слишком много совпадений
 	var b strings.Builder
-	b.WriteString("Нашлось несколько факультетов:\n")
+	b.WriteString(page.Header("Нашлось факультетов"))
+	b.WriteString("\n\n")
+	kb := sc.API.NewKeyboardBuilder()
 	for i, f := range facs {
-		fmt.Fprintf(&b, "%d) %s\n", i+1, f.Name)
+		fmt.Fprintf(&b, "%d) %s\n", page.Offset+i+1, f.Name)
+		kb.AddRow().AddCallback(f.Name, schemes.POSITIVE, payload(Dean_Faculty, f.ID))
 	}
-	b.WriteString("\nУточните название (например, полное наименование).")
+	b.WriteString("\nВыберите факультет или уточните название.")
+	addPager(kb, page, func(offset int) string {
+		return payload(Dean_FacultyPage, offset, encodeQuery(query))
+	})
+	kb.AddRow().AddCallback("🏠 Главное меню", schemes.NEGATIVE, BackToMenu)
 
-	// остаёмся в состоянии WaitFacultyName
-	return true, deanReplyMsg(ctx, sc, upd, b.String())
+	msg := maxbot.NewMessage()
+	setRecipient(msg, recipient)
+	msg.SetText(b.String()).AddKeyboard(kb)
+	_, err = sc.API.Send(ctx, msg)
+	return err
 }
 
 // показать расписание по факультету
-func deanShowSchedule(ctx context.Context, sc Ctx, upd *schemes.MessageCreatedUpdate, fac models.Faculty) error {
+func deanShowSchedule(ctx context.Context, sc Ctx, recipient schemes.Recipient, fac models.Faculty) error {
 	var office models.DeanOffice
 	err := sc.DB.Where("faculty_id = ?", fac.ID).First(&office).Error
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return deanReplyMsg(ctx, sc, upd, fmt.Sprintf("Для факультета %q расписание не заполнено.", fac.Name))
+	text := ""
+	kb := sc.API.NewKeyboardBuilder()
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		text = fmt.Sprintf("Для факультета %q расписание не заполнено.", fac.Name)
+	case err != nil:
+		text = failedText(err, "dean: load office")
+	default:
+		hours, herr := models.DeanOfficeHours(sc.DB, sc.now(), office.ID)
+		if herr != nil {
+			return herr
 		}
-		return deanReplyMsg(ctx, sc, upd, fmt.Sprintf("Ошибка запроса расписания: %v", err))
+		text = deanFormat(fac, office, hoursLine(sc.now(), hours[office.ID].StatusAt(sc.now())))
+		favButton(kb, models.FavFaculty, fac.ID)
 	}
-
-	text := deanFormat(fac, office)
+	deanAddBackRow(kb)
 
 	msg := maxbot.NewMessage()
-	if upd.Message.Recipient.ChatId != 0 {
-		msg.SetChat(upd.Message.Recipient.ChatId)
-	} else {
-		msg.SetUser(upd.Message.Sender.UserId)
-	}
-	msg.SetText(text).AddKeyboard(deanScheduleKB(sc))
-	_, err = sc.API.Messages.Send(ctx, msg)
+	setRecipient(msg, recipient)
+	msg.SetText(text).AddKeyboard(kb)
+	_, err = sc.API.Send(ctx, msg)
 	return err
 }
 
-// формат ответа
-func deanFormat(f models.Faculty, d models.DeanOffice) string {
+// формат ответа; status — строка «открыто сейчас» (может быть пустой)
+func deanFormat(f models.Faculty, d models.DeanOffice, status string) string {
 	var b strings.Builder
 	fmt.Fprintf(&b, "📅 Расписание деканата факультета %s\n\n", f.Name)
+	if status != "" {
+		fmt.Fprintf(&b, "%s\n\n", status)
+	}
 	if strings.TrimSpace(d.Schedule) != "" {
 		fmt.Fprintf(&b, "%s\n\n", d.Schedule)
 	} else {
@@ -177,14 +214,18 @@ func deanReplyMsg(ctx context.Context, sc Ctx, upd *schemes.MessageCreatedUpdate
 		msg.SetUser(upd.Message.Sender.UserId)
 	}
 	msg.SetText(text)
-	_, err := sc.API.Messages.Send(ctx, msg)
+	_, err := sc.API.Send(ctx, msg)
 	return err
 }
 
 func deanScheduleKB(sc Ctx) *maxbot.Keyboard {
-	kb := sc.API.Messages.NewKeyboardBuilder()
+	kb := sc.API.NewKeyboardBuilder()
+	deanAddBackRow(kb)
+	return kb
+}
+
+func deanAddBackRow(kb *maxbot.Keyboard) {
 	kb.AddRow().
 		AddCallback("◀️ К выбору факультета", schemes.POSITIVE, Dean_BackToFacultyMenu).
-		AddCallback("🏠 Главное меню", schemes.NEGATIVE, "back_to_menu")
-	return kb
+		AddCallback("🏠 Главное меню", schemes.NEGATIVE, BackToMenu)
 }
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// DialogState - имя состояния диалога (чего бот ждёт от собеседника)
+type DialogState string
+
+// Состояния всех сценариев, ожидающих текст
+const (
+	StateFTByFaculty    DialogState = "ft.faculty"      // ждём название факультета
+	StateFTByDepartment DialogState = "ft.department"   // ждём название кафедры
+	StateFTByFIO        DialogState = "ft.fio"          // ждём часть ФИО
+	StateDeanFaculty    DialogState = "dean.faculty"    // ждём факультет для расписания деканата
+	StateTTGroup        DialogState = "tt.group"        // ждём номер группы для «Моё расписание»
+	StateProfileFaculty DialogState = "profile.faculty" // ждём факультет для профиля
+	StateProfileGroup   DialogState = "profile.group"   // ждём группу для профиля
+	StateAdminForm      DialogState = "admin.form"      // ждём значение поля в форме /admin
+)
+
+// DefaultDialogTTL - сколько живёт незавершённый диалог
+const DefaultDialogTTL = 30 * time.Minute
+
+// Dialog - текущее состояние собеседника
+type Dialog struct {
+	State     DialogState
+	Data      map[string]string // параметры шага (например, выбранный режим)
+	ExpiresAt time.Time
+}
+
+// DialogStore - хранилище состояний по peer (чат или пользователь)
+type DialogStore interface {
+	Load(ctx context.Context, peer int64) (Dialog, bool, error)
+	Save(ctx context.Context, peer int64, d Dialog) error
+	Delete(ctx context.Context, peer int64) error
+	DeleteExpired(ctx context.Context, now time.Time) error
+}
+
+// TextHandler обрабатывает текст собеседника в конкретном состоянии
+type TextHandler func(ctx context.Context, sc Ctx, upd *schemes.MessageCreatedUpdate, d Dialog) (bool, error)
+
+// Обработчики текста по состояниям — единая точка входа для OnMessage
+var dialogHandlers = map[DialogState]TextHandler{
+	StateFTByFaculty:    FT_OnMessage,
+	StateFTByDepartment: FT_OnMessage,
+	StateFTByFIO:        FT_OnMessage,
+	StateDeanFaculty:    Dean_OnMessage,
+	StateTTGroup:        TT_OnMessage,
+	StateProfileFaculty: Profile_FacultyOnMessage,
+	StateProfileGroup:   Profile_GroupOnMessage,
+	StateAdminForm:      Admin_OnMessage,
+}
+
+// Dialogs - машина состояний поверх хранилища: переходы, TTL, сброс
+type Dialogs struct {
+	store DialogStore
+	ttl   time.Duration
+	now   func() time.Time
+}
+
+func NewDialogs(store DialogStore, ttl time.Duration) *Dialogs {
+	if ttl <= 0 {
+		ttl = DefaultDialogTTL
+	}
+	return &Dialogs{store: store, ttl: ttl, now: time.Now}
+}
+
+// Get возвращает активное (не истёкшее) состояние
+func (ds *Dialogs) Get(ctx context.Context, peer int64) (Dialog, bool, error) {
+	d, ok, err := ds.store.Load(ctx, peer)
+	if err != nil || !ok {
+		return Dialog{}, false, err
+	}
+	if !d.ExpiresAt.After(ds.now()) {
+		return Dialog{}, false, ds.store.Delete(ctx, peer)
+	}
+	return d, true, nil
+}
+
+// Enter переводит собеседника в состояние state (предыдущее затирается)
+func (ds *Dialogs) Enter(ctx context.Context, peer int64, state DialogState, data map[string]string) error {
+	return ds.store.Save(ctx, peer, Dialog{State: state, Data: data, ExpiresAt: ds.now().Add(ds.ttl)})
+}
+
+// Reset завершает любой сценарий собеседника
+func (ds *Dialogs) Reset(ctx context.Context, peer int64) error {
+	return ds.store.Delete(ctx, peer)
+}
+
+// RunJanitor периодически чистит истёкшие состояния, пока жив ctx
+func (ds *Dialogs) RunJanitor(ctx context.Context, every time.Duration) error {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+			if err := ds.store.DeleteExpired(ctx, ds.now()); err != nil {
+				if ctx.Err() != nil {
+					return nil // остановились посреди чистки — это не ошибка
+				}
+				return err
+			}
+		}
+	}
+}
+
+// resetDialog - middleware: кнопка сбрасывает незавершённый ввод (меню, выход из раздела)
+func resetDialog(next CallbackHandler) CallbackHandler {
+	return func(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+		if err := sc.Dialogs.Reset(ctx, peerFromCallback(upd)); err != nil {
+			return err
+		}
+		return next(ctx, sc, upd, p)
+	}
+}
+
+// peerFromCallback - чат или пользователь, нажавший кнопку
+func peerFromCallback(upd *schemes.MessageCallbackUpdate) int64 {
+	if upd.Message.Recipient.ChatId != 0 {
+		return upd.Message.Recipient.ChatId
+	}
+	if upd.Message.Recipient.UserId != 0 {
+		return upd.Message.Recipient.UserId
+	}
+	return 0
+}
+
+// peerFromMessage - чат или пользователь, написавший сообщение
+func peerFromMessage(upd *schemes.MessageCreatedUpdate) int64 {
+	if upd.Message.Recipient.ChatId != 0 {
+		return upd.Message.Recipient.ChatId
+	}
+	return upd.Message.Sender.UserId
+}
+
+// ---- хранилище в памяти (dev, один инстанс) ----
+
+type MemoryDialogStore struct {
+	mu   sync.RWMutex
+	data map[int64]Dialog
+}
+
+func NewMemoryDialogStore() *MemoryDialogStore {
+	return &MemoryDialogStore{data: map[int64]Dialog{}}
+}
+
+func (m *MemoryDialogStore) Load(_ context.Context, peer int64) (Dialog, bool, error) {
+	m.mu.RLock()
+	d, ok := m.data[peer]
+	m.mu.RUnlock()
+	return d, ok, nil
+}
+
+func (m *MemoryDialogStore) Save(_ context.Context, peer int64, d Dialog) error {
+	m.mu.Lock()
+	m.data[peer] = d
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *MemoryDialogStore) Delete(_ context.Context, peer int64) error {
+	m.mu.Lock()
+	delete(m.data, peer)
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *MemoryDialogStore) DeleteExpired(_ context.Context, now time.Time) error {
+	m.mu.Lock()
+	for peer, d := range m.data {
+		if !d.ExpiresAt.After(now) {
+			delete(m.data, peer)
+		}
+	}
+	m.mu.Unlock()
+	return nil
+}
+
+// ---- хранилище в Postgres (переживает рестарт) ----
+
+type GormDialogStore struct {
+	db *gorm.DB
+}
+
+func NewGormDialogStore(db *gorm.DB) *GormDialogStore { return &GormDialogStore{db: db} }
+
+func (g *GormDialogStore) Load(ctx context.Context, peer int64) (Dialog, bool, error) {
+	var row models.DialogState
+	err := g.db.WithContext(ctx).First(&row, "peer = ?", peer).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Dialog{}, false, nil
+	}
+	if err != nil {
+		return Dialog{}, false, fmt.Errorf("load dialog: %w", err)
+	}
+	d := Dialog{State: DialogState(row.State), ExpiresAt: row.ExpiresAt}
+	if row.Data != "" {
+		if err := json.Unmarshal([]byte(row.Data), &d.Data); err != nil {
+			return Dialog{}, false, fmt.Errorf("decode dialog data: %w", err)
+		}
+	}
+	return d, true, nil
+}
+
+func (g *GormDialogStore) Save(ctx context.Context, peer int64, d Dialog) error {
+	row := models.DialogState{Peer: peer, State: string(d.State), ExpiresAt: d.ExpiresAt}
+	if len(d.Data) > 0 {
+		raw, err := json.Marshal(d.Data)
+		if err != nil {
+			return fmt.Errorf("encode dialog data: %w", err)
+		}
+		row.Data = string(raw)
+	}
+	return g.db.WithContext(ctx).
+		Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(&row).Error
+}
+
+func (g *GormDialogStore) Delete(ctx context.Context, peer int64) error {
+	return g.db.WithContext(ctx).Delete(&models.DialogState{}, "peer = ?", peer).Error
+}
+
+func (g *GormDialogStore) DeleteExpired(ctx context.Context, now time.Time) error {
+	return g.db.WithContext(ctx).Delete(&models.DialogState{}, "expires_at <= ?", now).Error
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// dialogStores - оба хранилища; Gorm — на SQLite, как в internal/botsim
+func dialogStores(t *testing.T) map[string]DialogStore {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(t.TempDir()+"/dialogs.db"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.DialogState{}); err != nil {
+		t.Fatal(err)
+	}
+	return map[string]DialogStore{"memory": NewMemoryDialogStore(), "gorm": NewGormDialogStore(db)}
+}
+
+// testDialogs - машина состояний с ручными часами
+func testDialogs(store DialogStore, ttl time.Duration) (*Dialogs, *time.Time) {
+	now := time.Date(2030, 9, 2, 10, 0, 0, 0, time.UTC)
+	ds := NewDialogs(store, ttl)
+	ds.now = func() time.Time { return now }
+	return ds, &now
+}
+
+func TestDialogsRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range dialogStores(t) {
+		ds, now := testDialogs(store, time.Minute)
+		data := map[string]string{"mode": "fio", "page": "2"}
+
+		if _, ok, err := ds.Get(ctx, 1); ok || err != nil {
+			t.Fatalf("%s: empty store: ok %v, err %v", name, ok, err)
+		}
+		if err := ds.Enter(ctx, 1, StateFTByFIO, data); err != nil {
+			t.Fatal(err)
+		}
+		if err := ds.Enter(ctx, 2, StateTTGroup, nil); err != nil {
+			t.Fatal(err)
+		}
+		d, ok, err := ds.Get(ctx, 1)
+		if err != nil || !ok || d.State != StateFTByFIO || !maps.Equal(d.Data, data) || !d.ExpiresAt.Equal(now.Add(time.Minute)) {
+			t.Fatalf("%s: get = %+v, %v, %v", name, d, ok, err)
+		}
+
+		// новое состояние затирает прежнее вместе с данными
+		if err := ds.Enter(ctx, 1, StateDeanFaculty, nil); err != nil {
+			t.Fatal(err)
+		}
+		if d, ok, err := ds.Get(ctx, 1); err != nil || !ok || d.State != StateDeanFaculty || len(d.Data) != 0 {
+			t.Errorf("%s: after second Enter: %+v, %v, %v", name, d, ok, err)
+		}
+
+		if err := ds.Reset(ctx, 1); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok, err := ds.Get(ctx, 1); ok || err != nil {
+			t.Errorf("%s: after Reset: ok %v, err %v", name, ok, err)
+		}
+		if err := ds.Reset(ctx, 1); err != nil {
+			t.Errorf("%s: second Reset: %v", name, err)
+		}
+		if _, ok, _ := ds.Get(ctx, 2); !ok {
+			t.Errorf("%s: Reset of peer 1 removed peer 2", name)
+		}
+	}
+}
+
+func TestDialogsTTL(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range dialogStores(t) {
+		ds, now := testDialogs(store, time.Minute)
+		if err := ds.Enter(ctx, 1, StateTTGroup, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		*now = now.Add(time.Minute - time.Second)
+		if _, ok, _ := ds.Get(ctx, 1); !ok {
+			t.Fatalf("%s: dialog expired before TTL", name)
+		}
+		// ровно на границе диалог уже истёк, а Get удаляет его из хранилища
+		*now = now.Add(time.Second)
+		if _, ok, err := ds.Get(ctx, 1); ok || err != nil {
+			t.Errorf("%s: expired dialog: ok %v, err %v", name, ok, err)
+		}
+		if _, ok, err := store.Load(ctx, 1); ok || err != nil {
+			t.Errorf("%s: expired dialog left in store: ok %v, err %v", name, ok, err)
+		}
+	}
+
+	if ds := NewDialogs(NewMemoryDialogStore(), 0); ds.ttl != DefaultDialogTTL {
+		t.Errorf("zero TTL: %v, want %v", ds.ttl, DefaultDialogTTL)
+	}
+}
+
+func TestDialogsJanitor(t *testing.T) {
+	for name, store := range dialogStores(t) {
+		ds, now := testDialogs(store, time.Minute)
+		ctx, cancel := context.WithCancel(context.Background())
+		if err := ds.Enter(ctx, 1, StateTTGroup, nil); err != nil {
+			t.Fatal(err)
+		}
+		*now = now.Add(30 * time.Second)
+		if err := ds.Enter(ctx, 2, StateTTGroup, nil); err != nil {
+			t.Fatal(err)
+		}
+		// первый истёк, второму осталось 30 секунд
+		*now = now.Add(30 * time.Second)
+
+		done := make(chan error, 1)
+		go func() { done <- ds.RunJanitor(ctx, time.Millisecond) }()
+		deadline := time.Now().Add(time.Second)
+		for {
+			_, ok, err := store.Load(context.Background(), 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("%s: janitor did not delete the expired dialog", name)
+			}
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("%s: janitor after cancel: %v", name, err)
+		}
+		if _, ok, _ := store.Load(context.Background(), 2); !ok {
+			t.Errorf("%s: janitor deleted a live dialog", name)
+		}
+	}
+}
+
+// failingStore - хранилище, у которого сломана чистка
+type failingStore struct{ *MemoryDialogStore }
+
+var errStore = errors.New("store is down")
+
+func (failingStore) DeleteExpired(context.Context, time.Time) error { return errStore }
+
+func TestDialogsJanitorError(t *testing.T) {
+	ds := NewDialogs(failingStore{NewMemoryDialogStore()}, time.Minute)
+	done := make(chan error, 1)
+	go func() { done <- ds.RunJanitor(context.Background(), time.Millisecond) }()
+	select {
+	case err := <-done:
+		if !errors.Is(err, errStore) {
+			t.Errorf("janitor error = %v, want %v", err, errStore)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("janitor ignores store errors")
+	}
+}
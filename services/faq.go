@@ -2,45 +2,197 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
 	maxbot "github.com/max-messenger/max-bot-api-client-go"
 	"github.com/max-messenger/max-bot-api-client-go/schemes"
+	"gorm.io/gorm"
+
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// Частые вопросы: тема -> вопрос -> ответ. Всё берётся из faq_categories и faqs,
+// сотрудники правят их через /admin или API без выкладки бота.
+const (
+	FAQ_Cats    = "faq/cats/{offset}"        // страница тем
+	FAQ_Cat     = "faq/cat/{id}/{offset}"    // вопросы темы; id 0 — вопросы без темы
+	FAQ_Answer  = "faq/q/{id}"               // ответ на вопрос
+	FAQ_Miss    = "faq/miss/{id}/{q:str}"    // «Это не то» на ответ id по запросу q
+	FAQ_Similar = "faq/similar/{id}/{q:str}" // другие вопросы по запросу q, кроме id
 )
 
+// подпись для вопросов без темы
+const faqNoCategory = "Без темы"
+
+// максимальная длина подписи кнопки с вопросом (в рунах)
+const faqButtonLen = 48
+
+func registerFAQRoutes(r *Router) {
+	r.Handle(ServiceFAQ, noParams(FAQ_Handle), resetDialog)
+	r.Handle(FAQ_Cats, func(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+		return faqShowCategories(ctx, sc, upd.Message.Recipient, int(p.ID("offset")))
+	})
+	r.Handle(FAQ_Cat, func(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+		return faqShowCategory(ctx, sc, upd.Message.Recipient, p.ID("id"), int(p.ID("offset")))
+	})
+	r.Handle(FAQ_Answer, func(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+		return faqShowAnswer(ctx, sc, upd.Message.Recipient, p.ID("id"))
+	})
+	r.Handle(FAQ_Miss, faqMissHandle)
+	r.Handle(FAQ_Similar, faqSimilarHandle)
+}
+
 func FAQ_Handle(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate) error {
-	msg := maxbot.NewMessage()
-	if upd.Message.Recipient.ChatId != 0 {
-		msg.SetChat(upd.Message.Recipient.ChatId)
-	} else {
-		msg.SetUser(upd.Message.Recipient.UserId)
+	return faqShowCategories(ctx, sc, upd.Message.Recipient, 0)
+}
+
+// faqTopic - тема с числом вопросов; ID 0 — вопросы без темы
+type faqTopic struct {
+	ID    uint
+	Name  string
+	Count int
+}
+
+// faqTopics - непустые темы по порядку, в конце — «Без темы», если такие вопросы есть
+func faqTopics(sc Ctx) ([]faqTopic, error) {
+	var topics []faqTopic
+	if err := sc.DB.Table("faq_categories").
+		Select("faq_categories.id, faq_categories.name, COUNT(faqs.id) AS count").
+		Joins("JOIN faqs ON faqs.category_id = faq_categories.id").
+		Group("faq_categories.id, faq_categories.name, faq_categories.position").
+		Order("faq_categories.position, faq_categories.name").
+		Scan(&topics).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch faq categories: %w", err)
+	}
+	var orphans int64
+	if err := sc.DB.Model(&models.FAQ{}).Where("category_id IS NULL").Count(&orphans).Error; err != nil {
+		return nil, fmt.Errorf("failed to count faqs: %w", err)
 	}
-	str := `**Часто задаваемые вопросы**  
+	if orphans > 0 {
+		topics = append(topics, faqTopic{Name: faqNoCategory, Count: int(orphans)})
+	}
+	return topics, nil
+}
 
-	1) Как найти преподавателя? 
-	Нажмите кнопку «Поиск преподавателя» в главном меню.  
-	Затем выберите способ поиска: по кафедре, факультету или ФИО.  
-	После ввода данных бот покажет контактную информацию и расписание преподавателя.  
+// faqShowCategories - кнопки тем, по PageSize на странице
+func faqShowCategories(ctx context.Context, sc Ctx, recipient schemes.Recipient, offset int) error {
+	return faqSendTopics(ctx, sc, recipient, offset, "❓ Частые вопросы\n\nВыберите тему, которая вас интересует:")
+}
 
-	2) Как узнать расписание занятий?  
-	Для преподавателей расписание выводится автоматически при поиске.  
-	Для студентов — функция появится в ближайших обновлениях.  
+// faqSendTopics - страница тем с подписью intro
+func faqSendTopics(ctx context.Context, sc Ctx, recipient schemes.Recipient, offset int, intro string) error {
+	topics, err := faqTopics(sc)
+	if err != nil {
+		return err
+	}
+	kb := sc.API.NewKeyboardBuilder()
+	text := "❓ Частых вопросов пока нет."
+	if len(topics) > 0 {
+		page := newPage(offset, len(topics))
+		for _, t := range topics[page.Offset:page.End()] {
+			kb.AddRow().AddCallback(fmt.Sprintf("%s (%d)", t.Name, t.Count), schemes.POSITIVE,
+				payload(FAQ_Cat, t.ID, 0))
+		}
+		addPager(kb, page, func(offset int) string { return payload(FAQ_Cats, offset) })
+		text = intro
+	}
+	kb.AddRow().AddCallback("🏠 Главное меню", schemes.NEGATIVE, BackToMenu)
 
-	3) Как написать преподавателю?
-	После поиска преподавателя вы получите его e-mail.  
-	Можно кликнуть по нему или скопировать адрес и отправить письмо напрямую.  
+	msg := maxbot.NewMessage()
+	setRecipient(msg, recipient)
+	msg.SetText(text).AddKeyboard(kb)
+	_, err = sc.API.Send(ctx, msg)
+	return err
+}
 
-	4) Что делать, если не нашёл нужного преподавателя? 
-	Попробуйте ввести фамилию без сокращений и уточните кафедру.  
-	Если результата нет — возможно, данные ещё не внесены в базу.  
+// faqShowCategory - вопросы темы списком и кнопками
+func faqShowCategory(ctx context.Context, sc Ctx, recipient schemes.Recipient, categoryID uint, offset int) error {
+	name := faqNoCategory
+	if categoryID != 0 {
+		var cat models.FAQCategory
+		err := sc.DB.First(&cat, categoryID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return faqShowCategories(ctx, sc, recipient, 0)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to fetch faq category: %w", err)
+		}
+		name = cat.Name
+	}
+	base := func() *gorm.DB {
+		if categoryID == 0 {
+			return sc.DB.Model(&models.FAQ{}).Where("category_id IS NULL")
+		}
+		return sc.DB.Model(&models.FAQ{}).Where("category_id = ?", categoryID)
+	}
+	var total int64
+	if err := base().Count(&total).Error; err != nil {
+		return fmt.Errorf("failed to count faqs: %w", err)
+	}
+	if total == 0 {
+		return faqShowCategories(ctx, sc, recipient, 0)
+	}
+	page := newPage(offset, int(total))
+	var faqs []models.FAQ
+	if err := base().Order("id").Offset(page.Offset).Limit(PageSize).Find(&faqs).Error; err != nil {
+		return fmt.Errorf("failed to fetch faqs: %w", err)
+	}
 
-	5) К кому обратиться при технических проблемах с ботом?
-	Если бот не отвечает или выдаёт ошибку — напишите администратору:  
-	karielka@yandex.ru
+	var b strings.Builder
+	b.WriteString(page.Header("❓ " + name))
+	b.WriteString("\n")
+	kb := sc.API.NewKeyboardBuilder()
+	for i, f := range faqs {
+		n := page.Offset + i + 1
+		fmt.Fprintf(&b, "\n%d. %s", n, f.Question)
+		kb.AddRow().AddCallback(faqButton(n, f.Question), schemes.DEFAULT, payload(FAQ_Answer, f.ID))
+	}
+	addPager(kb, page, func(offset int) string { return payload(FAQ_Cat, categoryID, offset) })
+	kb.AddRow().
+		AddCallback("◀️ К темам", schemes.NEGATIVE, payload(FAQ_Cats, 0)).
+		AddCallback("🏠 Главное меню", schemes.NEGATIVE, BackToMenu)
 
-	Спасибо, что используете нашего бота! `
+	msg := maxbot.NewMessage()
+	setRecipient(msg, recipient)
+	msg.SetText(b.String()).AddKeyboard(kb)
+	_, err := sc.API.Send(ctx, msg)
+	return err
+}
 
-	msg.SetText(str)
-	msg.SetFormat("markdown")
-	_, err := sc.API.Messages.Send(ctx, msg)
+// faqShowAnswer - вопрос и ответ, назад — к вопросам той же темы
+func faqShowAnswer(ctx context.Context, sc Ctx, recipient schemes.Recipient, id uint) error {
+	var f models.FAQ
+	err := sc.DB.First(&f, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return faqShowCategories(ctx, sc, recipient, 0)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch faq: %w", err)
+	}
+	var categoryID uint
+	if f.CategoryID != nil {
+		categoryID = *f.CategoryID
+	}
+
+	kb := sc.API.NewKeyboardBuilder()
+	kb.AddRow().
+		AddCallback("◀️ К вопросам", schemes.NEGATIVE, payload(FAQ_Cat, categoryID, 0)).
+		AddCallback("🏠 Главное меню", schemes.NEGATIVE, BackToMenu)
+
+	msg := maxbot.NewMessage()
+	setRecipient(msg, recipient)
+	msg.SetText("❓ " + f.Question + "\n\n" + f.Answer).AddKeyboard(kb)
+	_, err = sc.API.Send(ctx, msg)
 	return err
 }
+
+// faqButton - «3. Как получить справку об обу…»
+func faqButton(n int, question string) string {
+	label := fmt.Sprintf("%d. %s", n, question)
+	if r := []rune(label); len(r) > faqButtonLen {
+		label = string(r[:faqButtonLen-1]) + "…"
+	}
+	return label
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// Ответ на вопрос, написанный своими словами: «как получить справку об обучении».
+// Уверенность — models.FAQMatch.Score; ниже порога бот показывает главное меню, как раньше.
+const (
+	faqMinScore     = 0.5 // отвечаем сразу
+	faqSimilarScore = 0.3 // показываем в «Другие похожие вопросы»
+)
+
+// FAQ_OnMessage - последний шаг разбора текста: ищем ответ среди частых вопросов
+func FAQ_OnMessage(ctx context.Context, sc Ctx, upd *schemes.MessageCreatedUpdate) (bool, error) {
+	text := strings.TrimSpace(upd.Message.Body.Text)
+	// полнотекстовый поиск есть только в Postgres; на SQLite (botsim) — главное меню, как раньше
+	if text == "" || sc.DB.Dialector.Name() != "postgres" {
+		return false, nil
+	}
+	matches, err := models.SearchFAQ(sc.DB, text)
+	if err != nil {
+		return false, err
+	}
+	if len(matches) == 0 || matches[0].Score < faqMinScore {
+		return false, nil
+	}
+	best := matches[0]
+	similar := len(faqSimilar(matches, best.ID)) > 0
+
+	q := encodeQuery(text)
+	kb := sc.API.NewKeyboardBuilder()
+	row := kb.AddRow().AddCallback("🙅 Это не то", schemes.NEGATIVE, payload(FAQ_Miss, best.ID, q))
+	if similar {
+		row.AddCallback("🔎 Другие похожие вопросы", schemes.DEFAULT, payload(FAQ_Similar, best.ID, q))
+	}
+	kb.AddRow().
+		AddCallback("❓ Все вопросы", schemes.DEFAULT, ServiceFAQ).
+		AddCallback("🏠 Главное меню", schemes.NEGATIVE, BackToMenu)
+
+	msg := maxbot.NewMessage()
+	setRecipient(msg, recipientFromMessage(upd))
+	msg.SetText("💡 Похоже, вы спрашиваете:\n\n❓ " + best.Question + "\n\n" + best.Answer).AddKeyboard(kb)
+	_, err = sc.API.Send(ctx, msg)
+	return true, err
+}
+
+// faqMissHandle - ответ не подошёл: пишем запрос в лог (по нему видно, каких вопросов не хватает) и предлагаем темы
+func faqMissHandle(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	log.Info().Str("query", decodeQuery(p.Str("q"))).Uint("faq_id", p.ID("id")).Msg("faq answer rejected")
+	return faqSendTopics(ctx, sc, upd.Message.Recipient, 0,
+		"Жаль, что ответ не подошёл. Напишите вопрос по-другому или найдите его по темам:")
+}
+
+// faqSimilarHandle - другие вопросы, похожие на запрос, кроме уже показанного
+func faqSimilarHandle(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	matches, err := models.SearchFAQ(sc.DB, decodeQuery(p.Str("q")))
+	if err != nil {
+		return err
+	}
+	similar := faqSimilar(matches, p.ID("id"))
+	if len(similar) == 0 {
+		return faqSendTopics(ctx, sc, upd.Message.Recipient, 0, "Похожих вопросов не нашлось. Поищите по темам:")
+	}
+
+	var b strings.Builder
+	b.WriteString("🔎 Похожие вопросы:\n")
+	kb := sc.API.NewKeyboardBuilder()
+	for i, m := range similar {
+		fmt.Fprintf(&b, "\n%d. %s", i+1, m.Question)
+		kb.AddRow().AddCallback(faqButton(i+1, m.Question), schemes.DEFAULT, payload(FAQ_Answer, m.ID))
+	}
+	kb.AddRow().
+		AddCallback("❓ Все вопросы", schemes.DEFAULT, ServiceFAQ).
+		AddCallback("🏠 Главное меню", schemes.NEGATIVE, BackToMenu)
+
+	msg := maxbot.NewMessage()
+	setRecipient(msg, upd.Message.Recipient)
+	msg.SetText(b.String()).AddKeyboard(kb)
+	_, err = sc.API.Send(ctx, msg)
+	return err
+}
+
+// faqSimilar - до PageSize совпадений с уверенностью не ниже faqSimilarScore, кроме вопроса shown
+func faqSimilar(matches []models.FAQMatch, shown uint) []models.FAQMatch {
+	var out []models.FAQMatch
+	for _, m := range matches {
+		if m.ID != shown && m.Score >= faqSimilarScore && len(out) < PageSize {
+			out = append(out, m)
+		}
+	}
+	return out
+}
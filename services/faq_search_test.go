@@ -0,0 +1,53 @@
+package services
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+func faqMatch(id uint, score float64) models.FAQMatch {
+	return models.FAQMatch{FAQ: models.FAQ{ID: id}, Score: score}
+}
+
+func faqIDs(matches []models.FAQMatch) []uint {
+	var ids []uint
+	for _, m := range matches {
+		ids = append(ids, m.ID)
+	}
+	return ids
+}
+
+// полнотекстовый поиск есть только в Postgres, поэтому пороги проверяем на готовых совпадениях
+func TestFAQSimilar(t *testing.T) {
+	if faqSimilarScore >= faqMinScore {
+		t.Fatalf("faqSimilarScore %v must be below faqMinScore %v", faqSimilarScore, faqMinScore)
+	}
+
+	tests := []struct {
+		name    string
+		matches []models.FAQMatch
+		shown   uint
+		want    []uint
+	}{
+		{"empty", nil, 1, nil},
+		{"only the shown one", []models.FAQMatch{faqMatch(1, 1)}, 1, nil},
+		{"threshold", []models.FAQMatch{
+			faqMatch(1, 1), faqMatch(2, faqMinScore), faqMatch(3, faqSimilarScore), faqMatch(4, faqSimilarScore-0.01),
+		}, 1, []uint{2, 3}},
+		{"shown in the middle", []models.FAQMatch{
+			faqMatch(2, 0.9), faqMatch(1, 0.8), faqMatch(3, 0.7),
+		}, 1, []uint{2, 3}},
+		{"page limit", []models.FAQMatch{
+			faqMatch(1, 1), faqMatch(2, 0.9), faqMatch(3, 0.9), faqMatch(4, 0.8), faqMatch(5, 0.8),
+			faqMatch(6, 0.7), faqMatch(7, 0.7), faqMatch(8, 0.6),
+		}, 1, []uint{2, 3, 4, 5, 6}},
+	}
+	for _, tt := range tests {
+		got := faqIDs(faqSimilar(tt.matches, tt.shown))
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// Маршруты избранного
+const (
+	FavPage   = "fav/list/{offset}"          // страница избранного
+	FavAdd    = "fav/{kind:str}/{id}/add"    // добавить: kind — models.Fav*
+	FavRemove = "fav/{kind:str}/{id}/remove" // убрать и показать список
+)
+
+// сколько объектов можно держать в избранном
+const favMaxItems = 30
+
+// колонка ссылки в user_favorites по виду избранного
+var favColumns = map[string]string{
+	models.FavTeacher: "teacher_id",
+	models.FavCampus:  "campus_id",
+	models.FavPlace:   "place_id",
+	models.FavFaculty: "faculty_id",
+}
+
+func registerFavoriteRoutes(r *Router) {
+	r.Handle(ServiceFavorites, func(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, _ Params) error {
+		return favShowList(ctx, sc, upd, 0)
+	}, resetDialog)
+	r.Handle(FavPage, func(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+		return favShowList(ctx, sc, upd, int(p.ID("offset")))
+	})
+	r.Handle(FavAdd, favAdd)
+	r.Handle(FavRemove, favRemove)
+}
+
+// favButton - кнопка «в избранное» для карточки
+func favButton(kb *maxbot.Keyboard, kind string, id uint) {
+	kb.AddRow().AddCallback("⭐ В избранное", schemes.DEFAULT, payload(FavAdd, kind, id))
+}
+
+// favAdd - добавить объект в избранное нажавшего пользователя
+func favAdd(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	kind, id := p.Str("kind"), p.ID("id")
+	if _, ok := favColumns[kind]; !ok {
+		return fmt.Errorf("unknown favorite kind: %s", kind)
+	}
+	userID := upd.Callback.User.UserId
+
+	var count int64
+	if err := sc.DB.Model(&models.UserFavorite{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to count favorites: %w", err)
+	}
+	if count >= favMaxItems {
+		return favReply(ctx, sc, upd.Message.Recipient,
+			fmt.Sprintf("В избранном уже %d записей — уберите лишнее, чтобы добавить новое.", count))
+	}
+
+	fav := models.UserFavorite{UserID: userID}
+	switch kind {
+	case models.FavTeacher:
+		fav.TeacherID = &id
+	case models.FavCampus:
+		fav.CampusID = &id
+	case models.FavPlace:
+		fav.PlaceID = &id
+	case models.FavFaculty:
+		fav.FacultyID = &id
+	}
+	res := sc.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&fav)
+	if res.Error != nil {
+		// чаще всего — объект уже удалён (нарушение внешнего ключа)
+		return favReply(ctx, sc, upd.Message.Recipient, "Не удалось добавить: запись не найдена.")
+	}
+	text := "⭐ Добавлено в избранное."
+	if res.RowsAffected == 0 {
+		text = "Это уже есть в избранном."
+	}
+	return favReply(ctx, sc, upd.Message.Recipient, text)
+}
+
+// favRemove - убрать объект из избранного
+func favRemove(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	col, ok := favColumns[p.Str("kind")]
+	if !ok {
+		return fmt.Errorf("unknown favorite kind: %s", p.Str("kind"))
+	}
+	if err := sc.DB.Where("user_id = ? AND "+col+" = ?", upd.Callback.User.UserId, p.ID("id")).
+		Delete(&models.UserFavorite{}).Error; err != nil {
+		return fmt.Errorf("failed to remove favorite: %w", err)
+	}
+	return favShowList(ctx, sc, upd, 0)
+}
+
+// favShowList - избранное пользователя: кнопка на каждый объект и «✖️» рядом
+func favShowList(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, offset int) error {
+	userID := upd.Callback.User.UserId
+	base := func() *gorm.DB {
+		return sc.DB.Model(&models.UserFavorite{}).Where("user_id = ?", userID)
+	}
+	var total int64
+	if err := base().Count(&total).Error; err != nil {
+		return fmt.Errorf("failed to count favorites: %w", err)
+	}
+	if total == 0 {
+		return favReply(ctx, sc, upd.Message.Recipient,
+			"⭐ В избранном пока пусто.\n\nДобавляйте преподавателей, корпуса, столовые и деканаты кнопкой «⭐ В избранное» в их карточках.")
+	}
+	page := newPage(offset, int(total))
+
+	var favs []models.UserFavorite
+	if err := base().Preload("Teacher").Preload("Campus").Preload("Place").Preload("Faculty").
+		Order("created_at, id").Offset(page.Offset).Limit(PageSize).
+		Find(&favs).Error; err != nil {
+		return fmt.Errorf("failed to fetch favorites: %w", err)
+	}
+
+	kb := sc.API.NewKeyboardBuilder()
+	for _, f := range favs {
+		label, open, id := favLink(f)
+		if open == "" {
+			continue
+		}
+		kb.AddRow().
+			AddCallback(label, schemes.POSITIVE, open).
+			AddCallback("✖️", schemes.NEGATIVE, payload(FavRemove, f.Kind(), id))
+	}
+	addPager(kb, page, func(offset int) string { return payload(FavPage, offset) })
+	kb.AddRow().AddCallback("🏠 Главное меню", schemes.NEGATIVE, BackToMenu)
+
+	msg := maxbot.NewMessage()
+	setRecipient(msg, upd.Message.Recipient)
+	msg.SetText(page.Header("⭐ Избранное") + "\n\nНажмите, чтобы открыть, или «✖️», чтобы убрать.").AddKeyboard(kb)
+	_, err := sc.API.Send(ctx, msg)
+	return err
+}
+
+// favLink - подпись, payload карточки и id объекта
+func favLink(f models.UserFavorite) (label, open string, id uint) {
+	switch {
+	case f.Teacher != nil:
+		return "👤 " + f.Teacher.FullName, payload(FT_Card, f.Teacher.ID), f.Teacher.ID
+	case f.Campus != nil:
+		return "🏫 " + f.Campus.FullName, payload(CampusCard, f.Campus.ID), f.Campus.ID
+	case f.Place != nil:
+		return placeIcon(f.Place.Type) + " " + f.Place.Name, payload(PlaceCard, f.Place.ID), f.Place.ID
+	case f.Faculty != nil:
+		return "🎓 Деканат " + f.Faculty.Name, payload(Dean_Faculty, f.Faculty.ID), f.Faculty.ID
+	}
+	return "", "", 0
+}
+
+func favReply(ctx context.Context, sc Ctx, recipient schemes.Recipient, text string) error {
+	kb := sc.API.NewKeyboardBuilder()
+	kb.AddRow().
+		AddCallback("⭐ Избранное", schemes.POSITIVE, ServiceFavorites).
+		AddCallback("🏠 Главное меню", schemes.NEGATIVE, BackToMenu)
+
+	msg := maxbot.NewMessage()
+	setRecipient(msg, recipient)
+	msg.SetText(text).AddKeyboard(kb)
+	_, err := sc.API.Send(ctx, msg)
+	return err
+}
@@ -3,11 +3,12 @@ package services
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
-	"sync"
 
 	maxbot "github.com/max-messenger/max-bot-api-client-go"
 	"github.com/max-messenger/max-bot-api-client-go/schemes"
+	"gorm.io/gorm"
 
 	"github.com/Karielka/Hackaton_MAX/models"
 )
@@ -17,49 +18,47 @@ const (
 	FT_FindByFaculty    = "find_by_faculty"
 	FT_FindByDepartment = "find_by_department"
 	FT_FindByFIO        = "find_by_fio"
+	FT_WhereNow         = "teacher/{id}/now"               // где преподаватель сейчас или будет дальше
+	FT_Page             = "ft/{mode:str}/{offset}/{q:str}" // страница результатов поиска
 )
 
-// --- состояние диалога (по peerId) ---
-type ftState struct{ Mode string } // faculty | department | fio
-
-var (
-	ftMu   sync.RWMutex
-	ftData = map[int64]ftState{}
+// Режимы поиска в payload страниц
+const (
+	ftModeFaculty    = "faculty"
+	ftModeDepartment = "department"
+	ftModeFIO        = "fio"
 )
 
-func ftPeerFromCallback(upd *schemes.MessageCallbackUpdate) int64 {
-	if upd.Message.Recipient.ChatId != 0 {
-		return upd.Message.Recipient.ChatId
-	}
-	if upd.Message.Recipient.UserId != 0 {
-		return upd.Message.Recipient.UserId
-	}
-	return 0
-}
-func ftPeerFromMessage(upd *schemes.MessageCreatedUpdate) int64 {
-	if upd.Message.Recipient.ChatId != 0 {
-		return upd.Message.Recipient.ChatId
-	}
-	return upd.Message.Sender.UserId
+var ftModeByState = map[DialogState]string{
+	StateFTByFaculty:    ftModeFaculty,
+	StateFTByDepartment: ftModeDepartment,
+	StateFTByFIO:        ftModeFIO,
 }
-func ftSet(peer int64, st ftState) { ftMu.Lock(); ftData[peer] = st; ftMu.Unlock() }
-func ftGet(peer int64) (ftState, bool) {
-	ftMu.RLock()
-	s, ok := ftData[peer]
-	ftMu.RUnlock()
-	return s, ok
+
+func registerTeacherRoutes(r *Router) {
+	r.Handle(ServiceFindTeacher, noParams(FT_ShowModeMenu), resetDialog)
+	// Под-обработчики поиска (выбор режима)
+	r.Handle(FT_FindByFaculty, noParams(FT_AskForQuery))
+	r.Handle(FT_FindByDepartment, noParams(FT_AskForQuery))
+	r.Handle(FT_FindByFIO, noParams(FT_AskForQuery))
+	r.Handle(FT_WhereNow, ftWhereNow)
+	r.Handle(FT_Page, ftShowPage)
+	// Карточка преподавателя и кафедры
+	r.Handle(FT_Card, ftShowCard)
+	r.Handle(FT_Colleagues, ftShowColleagues)
+	r.Handle(FT_Department, ftShowDepartment)
+	r.Handle(FT_DepartmentTeachers, ftShowDepartmentTeachers)
 }
-func ftClear(peer int64) { ftMu.Lock(); delete(ftData, peer); ftMu.Unlock() }
 
 // --- UI подменю выбора режима поиска ---
 func FT_ShowModeMenu(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate) error {
-	kb := sc.API.Messages.NewKeyboardBuilder()
+	kb := sc.API.NewKeyboardBuilder()
 	kb.AddRow().
 		AddCallback("По факультету", schemes.POSITIVE, FT_FindByFaculty).
 		AddCallback("По кафедре", schemes.POSITIVE, FT_FindByDepartment)
 	kb.AddRow().
 		AddCallback("По ФИО", schemes.POSITIVE, FT_FindByFIO)
-	kb.AddRow().AddCallback("◀️ Назад", schemes.NEGATIVE, "back_to_menu")
+	kb.AddRow().AddCallback("◀️ Назад", schemes.NEGATIVE, BackToMenu)
 
 	msg := maxbot.NewMessage()
 	if upd.Message.Recipient.ChatId != 0 {
@@ -68,30 +67,26 @@ func FT_ShowModeMenu(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUp
 		msg.SetUser(upd.Message.Recipient.UserId)
 	}
 	msg.SetText("Как будем искать?").AddKeyboard(kb)
-	_, err := sc.API.Messages.Send(ctx, msg)
+	_, err := sc.API.Send(ctx, msg)
 	return err
 }
 
 // --- выбор режима и запрос ввода ---
 func FT_AskForQuery(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate) error {
-	peer := ftPeerFromCallback(upd)
-
-	mode := ""
-	switch upd.Callback.Payload {
-	case FT_FindByFaculty:
-		mode = "faculty"
-	case FT_FindByDepartment:
-		mode = "department"
-	case FT_FindByFIO:
-		mode = "fio"
+	state := map[string]DialogState{
+		FT_FindByFaculty:    StateFTByFaculty,
+		FT_FindByDepartment: StateFTByDepartment,
+		FT_FindByFIO:        StateFTByFIO,
+	}[upd.Callback.Payload]
+	if err := sc.Dialogs.Enter(ctx, peerFromCallback(upd), state, nil); err != nil {
+		return err
 	}
-	ftSet(peer, ftState{Mode: mode})
 
-	prompt := map[string]string{
-		"faculty":    "Введите название факультета:",
-		"department": "Введите название кафедры:",
-		"fio":        "Введите часть ФИО (например, «иванов»):",
-	}[mode]
+	prompt := map[DialogState]string{
+		StateFTByFaculty:    "Введите название факультета:",
+		StateFTByDepartment: "Введите название кафедры:",
+		StateFTByFIO:        "Введите часть ФИО (например, «иванов»):",
+	}[state]
 
 	msg := maxbot.NewMessage()
 	if upd.Message.Recipient.ChatId != 0 {
@@ -100,66 +95,117 @@ func FT_AskForQuery(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpd
 		msg.SetUser(upd.Message.Recipient.UserId)
 	}
 	msg.SetText(prompt)
-	_, err := sc.API.Messages.Send(ctx, msg)
+	_, err := sc.API.Send(ctx, msg)
 	return err
 }
 
-// --- обработка пользовательского ввода из чата ---
-func FT_OnMessage(ctx context.Context, sc Ctx, upd *schemes.MessageCreatedUpdate) (bool, error) {
-	peer := ftPeerFromMessage(upd)
-	st, ok := ftGet(peer)
-	if !ok || st.Mode == "" {
-		return false, nil // это не наш сценарий
-	}
+// --- обработка пользовательского ввода из чата (состояния ft.*) ---
+func FT_OnMessage(ctx context.Context, sc Ctx, upd *schemes.MessageCreatedUpdate, d Dialog) (bool, error) {
+	peer := peerFromMessage(upd)
 
-	// правильно
 	query := strings.TrimSpace(upd.GetText())
 
 	if query == "" {
 		return true, ftReplyMsg(ctx, sc, upd, "Введите текст запроса.")
 	}
 
-	// Готовим запрос в БД с нужными JOIN по цепочке N-1
-	var res []models.Teacher
-	q := sc.DB.Model(&models.Teacher{}).
-		Preload("Department").
-		Preload("Department.Faculty").
-		Preload("Department.Faculty.Institute")
+	if err := ftSendPage(ctx, sc, recipientFromMessage(upd), ftModeByState[d.State], query, 0); err != nil {
+		return true, err
+	}
+	return true, sc.Dialogs.Reset(ctx, peer)
+}
 
-	switch st.Mode {
-	case "faculty":
-		// Teacher -> Department -> Faculty (по имени факультета)
-		q = q.Joins("JOIN departments d ON d.id = teachers.department_id").
-			Joins("JOIN faculties f ON f.id = d.faculty_id").
-			Where("f.name ILIKE ?", "%"+query+"%")
+// ftShowPage - листание результатов кнопками «◀️ / ▶️»
+func ftShowPage(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	return ftSendPage(ctx, sc, upd.Message.Recipient, p.Str("mode"), decodeQuery(p.Str("q")), int(p.ID("offset")))
+}
+
+// ftSendPage - одна страница результатов: краткий список и кнопка «подробнее» на каждого
+func ftSendPage(ctx context.Context, sc Ctx, recipient schemes.Recipient, mode, query string, offset int) error {
+	res, page, err := ftSearch(sc, mode, query, offset)
+	if err != nil {
+		return ftSend(ctx, sc, recipient, failedText(err, "find teacher: search"), nil)
+	}
+	if page.Total == 0 {
+		return ftSend(ctx, sc, recipient, "Совпадений не найдено. Попробуйте иначе.", ftSearchAgainKB(sc))
+	}
 
-	case "department":
-		// Teacher -> Department (по имени кафедры)
-		q = q.Joins("JOIN departments d ON d.id = teachers.department_id").
-			Where("d.name ILIKE ?", "%"+query+"%")
+	return ftSendTeacherList(ctx, sc, recipient, page.Header("Найдено преподавателей"), res, page,
+		func(offset int) string { return payload(FT_Page, mode, offset, encodeQuery(query)) })
+}
 
-	case "fio":
-		q = q.Where("full_name ILIKE ?", "%"+query+"%")
+// ftSendTeacherList - краткий список преподавателей с кнопкой «подробнее» на каждого и листанием
+func ftSendTeacherList(ctx context.Context, sc Ctx, recipient schemes.Recipient, header string, res []models.Teacher, page Page, link func(offset int) string) error {
+	var b strings.Builder
+	b.WriteString(header)
+	b.WriteString("\n\n")
+	for i, t := range res {
+		fmt.Fprintf(&b, "%d. %s\n", page.Offset+i+1, ftFormatShort(t))
 	}
+	b.WriteString("\nНайти кого-нибудь еще?")
 
-	if err := q.Limit(10).Find(&res).Error; err != nil {
-		return true, ftReplyMsg(ctx, sc, upd, fmt.Sprintf("Ошибка поиска: %v", err))
+	kb := sc.API.NewKeyboardBuilder()
+	for _, t := range res {
+		kb.AddRow().AddCallback("ℹ️ Подробнее: "+shortFIO(t.FullName), schemes.POSITIVE, payload(FT_Card, t.ID))
 	}
-	if len(res) == 0 {
-		_ = ftReplyMsgWithKeyboard(ctx, sc, upd, "Совпадений не найдено. Попробуйте иначе.")
-		ftClear(peer)
-		return true, nil
+	addPager(kb, page, link)
+	ftAddSearchRows(kb)
+	return ftSend(ctx, sc, recipient, b.String(), kb)
+}
+
+// ftSearch - страница поиска в выбранном режиме: по ФИО — нечёткий с ранжированием,
+// по факультету/кафедре — по названию, в алфавитном порядке
+func ftSearch(sc Ctx, mode, query string, offset int) ([]models.Teacher, Page, error) {
+	if mode == ftModeFIO {
+		all, err := ftSearchByName(sc, query)
+		if err != nil {
+			return nil, Page{}, err
+		}
+		page := newPage(offset, len(all))
+		return all[page.Offset:page.End()], page, nil
 	}
 
-	var b strings.Builder
-	b.WriteString("Найдено:\n")
-	for _, t := range res {
-		b.WriteString(ftFormatTeacher(t))
-		b.WriteString("\n")
+	// Факультеты или кафедры, подходящие под запрос
+	var ids []uint
+	var err error
+	switch mode {
+	case ftModeFaculty:
+		ids, err = matchIDs(sc, &models.Faculty{}, "name", query)
+	case ftModeDepartment:
+		ids, err = matchIDs(sc, &models.Department{}, "name", query)
+	}
+	if err != nil {
+		return nil, Page{}, err
+	}
+
+	// Готовим запрос в БД с нужными JOIN по цепочке N-1
+	base := func() *gorm.DB {
+		q := sc.DB.Model(&models.Teacher{}).
+			Joins("JOIN departments d ON d.id = teachers.department_id")
+		switch mode {
+		case ftModeFaculty:
+			// Teacher -> Department -> Faculty
+			return q.Where("d.faculty_id IN ?", ids)
+		case ftModeDepartment:
+			// Teacher -> Department
+			return q.Where("d.id IN ?", ids)
+		default:
+			return q.Where("1 = 0")
+		}
+	}
+
+	var total int64
+	if err := base().Count(&total).Error; err != nil {
+		return nil, Page{}, err
 	}
-	_ = ftReplyMsgWithKeyboard(ctx, sc, upd, b.String())
-	ftClear(peer)
-	return true, nil
+	page := newPage(offset, int(total))
+
+	var res []models.Teacher
+	err = base().Preload("Department").
+		Order("teachers.full_name").
+		Offset(page.Offset).Limit(PageSize).
+		Find(&res).Error
+	return res, page, err
 }
 
 // ---- утилиты ответа/форматирования ----
@@ -172,60 +218,69 @@ func ftReplyMsg(ctx context.Context, sc Ctx, upd *schemes.MessageCreatedUpdate,
 		msg.SetUser(upd.Message.Sender.UserId)
 	}
 	msg.SetText(text)
-	_, err := sc.API.Messages.Send(ctx, msg)
+	_, err := sc.API.Send(ctx, msg)
 	return err
 }
 
-func ftReplyMsgWithKeyboard(ctx context.Context, sc Ctx, upd *schemes.MessageCreatedUpdate, text string) error {
-	kb := sc.API.Messages.NewKeyboardBuilder()
+// ftSend - ответ с клавиатурой (kb может быть nil)
+func ftSend(ctx context.Context, sc Ctx, recipient schemes.Recipient, text string, kb *maxbot.Keyboard) error {
+	msg := maxbot.NewMessage()
+	setRecipient(msg, recipient)
+	msg.SetText(text)
+	if kb != nil {
+		msg.AddKeyboard(kb)
+	}
+	_, err := sc.API.Send(ctx, msg)
+	return err
+}
+
+// ftAddSearchRows - повтор поиска в любом режиме и выход в меню
+func ftAddSearchRows(kb *maxbot.Keyboard) {
 	kb.AddRow().
 		AddCallback("По факультету", schemes.POSITIVE, FT_FindByFaculty).
 		AddCallback("По кафедре", schemes.POSITIVE, FT_FindByDepartment)
 	kb.AddRow().
 		AddCallback("По ФИО", schemes.POSITIVE, FT_FindByFIO)
-	kb.AddRow().AddCallback("◀️ Назад", schemes.NEGATIVE, "back_to_menu")
+	kb.AddRow().AddCallback("◀️ Назад", schemes.NEGATIVE, BackToMenu)
+}
 
-	msg := maxbot.NewMessage()
-	if upd.Message.Recipient.ChatId != 0 {
-		msg.SetChat(upd.Message.Recipient.ChatId)
-	} else {
-		msg.SetUser(upd.Message.Sender.UserId)
-	}
-	msg.SetText(text + "\n Найти кого-нибудь еще?").AddKeyboard(kb)
-	_, err := sc.API.Messages.Send(ctx, msg)
-	return err
+func ftSearchAgainKB(sc Ctx) *maxbot.Keyboard {
+	kb := sc.API.NewKeyboardBuilder()
+	ftAddSearchRows(kb)
+	return kb
 }
 
-func ftFormatTeacher(t models.Teacher) string {
-	fac := "—"
-	inst := "—"
-	dep := "—"
-	if t.Department.ID != 0 {
-		dep = t.Department.Name
-		if t.Department.Faculty.ID != 0 {
-			fac = t.Department.Faculty.Name
-			if t.Department.Faculty.Institute.ID != 0 {
-				inst = t.Department.Faculty.Institute.Name
-			}
-		}
-	}
-	email := t.Email
-	if strings.TrimSpace(email) == "" {
-		email = "—"
-	}
-	sch := t.Schedule
-	if strings.TrimSpace(sch) == "" {
-		sch = "расписание не добавлено"
+// ftFormatShort - строка списка: ФИО и кафедра
+func ftFormatShort(t models.Teacher) string {
+	if t.Department.ID == 0 {
+		return t.FullName
 	}
-
-	return fmt.Sprintf(
-		"• %s\n  Институт: %s\n  Факультет: %s\n  Кафедра: %s\n  Почта: %s\n  %s",
-		t.FullName, inst, fac, dep, email, ftFormatSchedule(sch),
-	)
+	return fmt.Sprintf("%s — %s", t.FullName, t.Department.Name)
 }
 
-// отдельная функция — чтобы унифицировать печать расписания по проекту
-func ftFormatSchedule(raw string) string {
-	// позже заменишь на парсер/табличный вывод
-	return "Расписание: " + raw
+// отдельная функция — чтобы унифицировать печать расписания по проекту:
+// занятия группируются по дням недели и сортируются по времени
+func ftFormatSchedule(lessons []models.Lesson) string {
+	if len(lessons) == 0 {
+		return "  Расписание: не добавлено"
+	}
+	sorted := append([]models.Lesson(nil), lessons...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Weekday != sorted[j].Weekday {
+			return sorted[i].Weekday < sorted[j].Weekday
+		}
+		return sorted[i].StartMinute < sorted[j].StartMinute
+	})
+
+	var b strings.Builder
+	b.WriteString("  📅 Расписание:")
+	day := 0
+	for _, l := range sorted {
+		if l.Weekday != day {
+			day = l.Weekday
+			fmt.Fprintf(&b, "\n  %s", weekdayName(day))
+		}
+		fmt.Fprintf(&b, "\n    %s", formatLesson(l))
+	}
+	return b.String()
 }
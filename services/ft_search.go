@@ -0,0 +1,313 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+
+	"github.com/Karielka/Hackaton_MAX/internal/text"
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// Нечёткий поиск преподавателя по ФИО.
+//
+// Кандидатов отбирает Postgres через pg_trgm (word_similarity устойчив к опечаткам),
+// затем они ранжируются в Go: каждое слово запроса сравнивается с каждой частью ФИО
+// (порядок слов не важен), инициалы «И.И.» сверяются с именем и отчеством.
+// Запрос латиницей транслитерируется («ivanov») или набирается заново в русской раскладке
+// («bdfyjd»), ё приравнивается к е, падеж не важен («у Иванова»).
+
+const (
+	ftTrgmThreshold = 0.3  // порог word_similarity при отборе кандидатов
+	ftMinScore      = 0.55 // ниже этого результат не показываем
+	ftMaxCandidates = 200
+)
+
+// SQL-выражение ФИО в той же нормализации, что и запрос; по нему построен trigram-индекс
+// (миграция 0011_teacher_name_trgm)
+const ftNormNameSQL = "lower(replace(replace(teachers.full_name, 'ё', 'е'), 'Ё', 'Е'))"
+
+type nameQuery struct {
+	words    []string // слова фамилии/имени/отчества (нормализованные, кириллица)
+	initials []rune   // инициалы по порядку: «И.П.» -> [и п]
+}
+
+// ftSearchByName возвращает подходящих преподавателей, лучшие совпадения первыми
+func ftSearchByName(sc Ctx, raw string) ([]models.Teacher, error) {
+	q := parseNameQuery(raw)
+	if len(q.words) == 0 {
+		return nil, nil
+	}
+
+	var ids []uint
+	var err error
+	if sc.DB.Dialector.Name() == "postgres" {
+		ids, err = ftCandidatesTrgm(sc, q)
+	}
+	if sc.DB.Dialector.Name() != "postgres" || trgmMissing(err) {
+		// не Postgres (симулятор на SQLite) или pg_trgm не установлен — отбираем по началу слов
+		ids, err = ftCandidatesPrefix(sc, q)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var teachers []models.Teacher
+	if err := sc.DB.Preload("Department").Find(&teachers, ids).Error; err != nil {
+		return nil, err
+	}
+
+	type scored struct {
+		t     models.Teacher
+		score float64
+	}
+	ranked := make([]scored, 0, len(teachers))
+	for _, t := range teachers {
+		if s := scoreName(q, t.FullName); s >= ftMinScore {
+			ranked = append(ranked, scored{t, s})
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].t.FullName < ranked[j].t.FullName
+	})
+
+	out := make([]models.Teacher, len(ranked))
+	for i, r := range ranked {
+		out[i] = r.t
+	}
+	return out, nil
+}
+
+// ftCandidatesTrgm - отбор оператором <% (word_similarity не ниже порога): в отличие от вызова
+// функции он использует индекс. Порог задаётся только на время транзакции.
+func ftCandidatesTrgm(sc Ctx, q nameQuery) ([]uint, error) {
+	conds := make([]string, 0, len(q.words))
+	args := make([]any, 0, len(q.words))
+	for _, w := range q.words {
+		conds = append(conds, "? <% "+ftNormNameSQL)
+		args = append(args, w)
+	}
+	var ids []uint
+	err := sc.DB.Transaction(func(tx *gorm.DB) error {
+		threshold := strconv.FormatFloat(ftTrgmThreshold, 'f', -1, 64)
+		if err := tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)", threshold).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Teacher{}).
+			Where(strings.Join(conds, " OR "), args...).
+			Limit(ftMaxCandidates).
+			Pluck("teachers.id", &ids).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search teachers by trigrams: %w", err)
+	}
+	return ids, nil
+}
+
+// trgmMissing - ошибка означает, что в базе нет pg_trgm: неизвестный оператор <%
+// (SQLSTATE 42883) или параметр pg_trgm.* (42704). Прочие ошибки — настоящие.
+func trgmMissing(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == "42883" || pgErr.Code == "42704")
+}
+
+// ftCandidatesPrefix - отбор без pg_trgm: в ФИО встречаются первые три буквы какого-нибудь
+// слова запроса. ФИО нормализуются в Go — lower() в SQL понимает кириллицу не везде.
+func ftCandidatesPrefix(sc Ctx, q nameQuery) ([]uint, error) {
+	var rows []struct {
+		ID       uint
+		FullName string
+	}
+	if err := sc.DB.Model(&models.Teacher{}).Select("id, full_name").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("search teachers: %w", err)
+	}
+	var ids []uint
+	for _, r := range rows {
+		name := text.Fold(r.FullName)
+		for _, w := range q.words {
+			if p := []rune(w); strings.Contains(name, string(p[:min(len(p), 3)])) {
+				ids = append(ids, r.ID)
+				break
+			}
+		}
+		if len(ids) == ftMaxCandidates {
+			break
+		}
+	}
+	return ids, nil
+}
+
+// parseNameQuery разбирает «Иванов И.И.», «И. И. Иванов», «ivanov ivan», «Иван Иванов»
+func parseNameQuery(raw string) nameQuery {
+	var q nameQuery
+	toks := strings.FieldsFunc(raw, func(r rune) bool {
+		return unicode.IsSpace(r) || r == ',' || r == ';'
+	})
+	for i, tok := range toks {
+		// предлог перед фамилией («у Иванова», «к Петрову») — не инициал
+		if i == 0 && len(toks) > 1 && text.IsStop(text.Fold(tok)) {
+			continue
+		}
+		// «И.И.» или «И.» — инициалы
+		if parts := strings.Split(strings.Trim(tok, "."), "."); isInitials(parts) {
+			for _, p := range parts {
+				q.initials = append(q.initials, []rune(text.NormalizeName(p))[0])
+			}
+			continue
+		}
+		w := text.NormalizeName(strings.Trim(tok, "."))
+		switch n := len([]rune(w)); {
+		case n == 0:
+		case n == 1:
+			q.initials = append(q.initials, []rune(w)[0])
+		default:
+			q.words = append(q.words, w)
+		}
+	}
+	return q
+}
+
+func isInitials(parts []string) bool {
+	if len(parts) < 2 {
+		return false
+	}
+	for _, p := range parts {
+		if len([]rune(p)) != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// scoreName - насколько ФИО подходит под запрос (0..~1.2)
+func scoreName(q nameQuery, fullName string) float64 {
+	var parts []string
+	for _, f := range strings.Fields(strings.ReplaceAll(fullName, "-", " ")) {
+		if p := text.NormalizeName(f); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	if len(parts) == 0 {
+		return 0
+	}
+
+	// каждое слово запроса — к лучшей части ФИО, части не переиспользуем
+	used := make([]bool, len(parts))
+	total := 0.0
+	for _, w := range q.words {
+		best, bestIdx := 0.0, -1
+		for i, p := range parts {
+			if used[i] {
+				continue
+			}
+			if s := wordScore(w, p); s > best {
+				best, bestIdx = s, i
+			}
+		}
+		if bestIdx >= 0 {
+			used[bestIdx] = true
+		}
+		total += best
+	}
+	score := total / float64(len(q.words))
+
+	// инициалы сверяем с оставшимися частями (обычно имя и отчество) по порядку
+	if len(q.initials) > 0 {
+		rest := make([]rune, 0, len(parts))
+		for i, p := range parts {
+			if !used[i] {
+				rest = append(rest, []rune(p)[0])
+			}
+		}
+		matched := 0
+		for i, r := range q.initials {
+			if i < len(rest) && rest[i] == r {
+				matched++
+			}
+		}
+		if matched == len(q.initials) {
+			score += 0.2
+		} else {
+			score -= 0.3
+		}
+	}
+	return score
+}
+
+// wordScore - похожесть слова запроса на часть ФИО: префикс, триграммы или
+// расстояние Левенштейна (одна опечатка в короткой фамилии сильно бьёт по триграммам)
+func wordScore(w, part string) float64 {
+	if w == part {
+		return 1
+	}
+	if len([]rune(w)) >= 3 && strings.HasPrefix(part, w) {
+		return 0.9
+	}
+	// та же фамилия в другом падеже: «Иванову» — «Иванов»
+	if text.Stem(w) == text.Stem(part) {
+		return 0.9
+	}
+	return max(trigramSimilarity(w, part), editSimilarity(w, part))
+}
+
+// editSimilarity = 1 - levenshtein / длина большего слова
+func editSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	n := max(len(ra), len(rb))
+	if n == 0 {
+		return 0
+	}
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return 1 - float64(prev[len(rb)])/float64(n)
+}
+
+// trigramSimilarity повторяет similarity() из pg_trgm для одного слова
+func trigramSimilarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	common := 0
+	for t := range ta {
+		if tb[t] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(ta)+len(tb)-common)
+}
+
+func trigrams(w string) map[string]bool {
+	r := []rune("  " + w + " ")
+	out := make(map[string]bool, len(r))
+	for i := 0; i+3 <= len(r); i++ {
+		out[string(r[i:i+3])] = true
+	}
+	return out
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestTrgmMissing(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("near \"<%\": syntax error"), false},
+		{context.DeadlineExceeded, false},
+		{&pgconn.PgError{Code: "42883", Message: "operator does not exist: text <% text"}, true},
+		{fmt.Errorf("search teachers by trigrams: %w", &pgconn.PgError{Code: "42704"}), true},
+		{&pgconn.PgError{Code: "57014", Message: "canceling statement due to statement timeout"}, false},
+		{&pgconn.PgError{Code: "42601", Message: "syntax error"}, false},
+	} {
+		if got := trgmMissing(tt.err); got != tt.want {
+			t.Errorf("trgmMissing(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"slices"
+	"time"
+
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// hoursLine - «🟢 Открыто до 17:00» или «🔴 Откроется завтра в 9:00»; пусто, если режим работы не заполнен
+func hoursLine(now time.Time, st models.OpenStatus) string {
+	switch {
+	case !st.Known:
+		return ""
+	case st.Open && st.Until.Sub(now) > 24*time.Hour:
+		return "🟢 Открыто круглосуточно"
+	case st.Open:
+		line := "🟢 Открыто до " + clockAt(now, st.Until)
+		if !st.Next.IsZero() && sameDay(st.Until, st.Next) {
+			line += ", перерыв до " + clockAt(now, st.Next)
+		}
+		return line
+	case st.Next.IsZero():
+		return "🔴 Закрыто"
+	default:
+		return "🔴 Откроется " + relativeDay(now, st.Next) + " в " + clockAt(now, st.Next)
+	}
+}
+
+// clockAt - время t; полночь после now печатается как «24:00» того же дня
+func clockAt(now, t time.Time) string {
+	m := t.Hour()*60 + t.Minute()
+	if m == 0 && t.After(now) && !sameDay(now, t) && sameDay(now, t.Add(-time.Minute)) {
+		m = 24 * 60
+	}
+	return models.FormatMinute(m)
+}
+
+func sameDay(a, b time.Time) bool {
+	return a.Format(time.DateOnly) == b.Format(time.DateOnly)
+}
+
+// placesOpenFirst сортирует места: сначала открытые сейчас, затем с известным режимом, затем остальные.
+// Возвращает статусы по id места.
+func placesOpenFirst(sc Ctx, places []models.Place) (map[uint]models.OpenStatus, error) {
+	ids := make([]uint, len(places))
+	for i, p := range places {
+		ids[i] = p.ID
+	}
+	now := sc.now()
+	hours, err := models.PlaceHours(sc.DB, now, ids...)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make(map[uint]models.OpenStatus, len(places))
+	for _, id := range ids {
+		statuses[id] = hours[id].StatusAt(now)
+	}
+	rank := func(st models.OpenStatus) int {
+		switch {
+		case st.Open:
+			return 0
+		case st.Known:
+			return 1
+		default:
+			return 2
+		}
+	}
+	slices.SortStableFunc(places, func(a, b models.Place) int {
+		return rank(statuses[a.ID]) - rank(statuses[b.ID])
+	})
+	return statuses, nil
+}
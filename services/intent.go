@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+	"gorm.io/gorm"
+
+	"github.com/Karielka/Hackaton_MAX/internal/intent"
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// Свободный текст вне диалога: классификатор intent оценивает все разделы, текст уходит
+// в лучший вместе с найденными сущностями, при близких оценках бот переспрашивает кнопками.
+
+// classifier обучается один раз при первом сообщении
+var classifier = sync.OnceValues(intent.New)
+
+// как часто перечитывать справочник сущностей (правки из /admin и API подхватываются с этой задержкой)
+const gazetteerTTL = time.Minute
+
+var gazetteerCache struct {
+	sync.Mutex
+	db *gorm.DB
+	at time.Time
+	g  intent.Gazetteer
+}
+
+// loadGazetteer - названия корпусов, факультетов, групп и ФИО преподавателей для поиска сущностей
+func loadGazetteer(sc Ctx) (intent.Gazetteer, error) {
+	c := &gazetteerCache
+	c.Lock()
+	defer c.Unlock()
+	if c.db == sc.DB && time.Since(c.at) < gazetteerTTL {
+		return c.g, nil
+	}
+
+	var g intent.Gazetteer
+	var campuses []models.Campus
+	if err := sc.DB.Select("id, short_name, full_name").Find(&campuses).Error; err != nil {
+		return g, fmt.Errorf("failed to fetch campuses: %w", err)
+	}
+	for _, cm := range campuses {
+		g.Campuses = append(g.Campuses, intent.Name{ID: cm.ID, Text: cm.ShortName}, intent.Name{ID: cm.ID, Text: cm.FullName})
+	}
+	for _, src := range []struct {
+		model any
+		col   string
+		dst   *[]intent.Name
+	}{
+		{&models.Faculty{}, "name", &g.Faculties},
+		{&models.Group{}, "name", &g.Groups},
+		{&models.Teacher{}, "full_name", &g.Teachers},
+	} {
+		if err := sc.DB.Model(src.model).Select("id, " + src.col + " AS text").Scan(src.dst).Error; err != nil {
+			return g, fmt.Errorf("failed to fetch %T names: %w", src.model, err)
+		}
+	}
+	c.db, c.at, c.g = sc.DB, time.Now(), g
+	return g, nil
+}
+
+// Intent_OnMessage - определить раздел по тексту и открыть его
+func Intent_OnMessage(ctx context.Context, sc Ctx, upd *schemes.MessageCreatedUpdate) (bool, error) {
+	text := strings.TrimSpace(upd.Message.Body.Text)
+	if text == "" {
+		return false, nil
+	}
+	c, err := classifier()
+	if err != nil {
+		return false, err
+	}
+	g, err := loadGazetteer(sc)
+	if err != nil {
+		return false, err
+	}
+	q := intent.Parse(text, g)
+	picked := intent.Pick(c.Classify(q))
+	switch len(picked) {
+	case 0:
+		return false, nil
+	case 1:
+		return true, intentRoute(ctx, sc, upd, picked[0].Intent, q.Entities)
+	default:
+		return true, intentAsk(ctx, sc, recipientFromMessage(upd), picked, q.Entities, g)
+	}
+}
+
+// intentRoute открывает раздел in с учётом сущностей и профиля пользователя
+func intentRoute(ctx context.Context, sc Ctx, upd *schemes.MessageCreatedUpdate, in intent.Intent, e intent.Entities) error {
+	recipient := recipientFromMessage(upd)
+	userID := upd.Message.Sender.UserId
+	user, _, err := loadUser(sc, userID)
+	if err != nil {
+		return err
+	}
+
+	switch in {
+	case intent.Teacher:
+		if e.Teacher != "" {
+			return ftSendPage(ctx, sc, recipient, ftModeFIO, e.Teacher, 0)
+		}
+		return intentOffer(ctx, sc, recipient, "👤 Кого ищем? Поиск — по ФИО, кафедре или факультету.",
+			"👤 Найти преподавателя", ServiceFindTeacher)
+
+	case intent.Dean:
+		fac := user.Faculty
+		if e.FacultyID != 0 {
+			fac = &models.Faculty{}
+			if err := sc.DB.First(fac, e.FacultyID).Error; err != nil {
+				return fmt.Errorf("failed to fetch faculty: %w", err)
+			}
+		}
+		if fac != nil {
+			return deanShowSchedule(ctx, sc, recipient, *fac)
+		}
+		return intentOffer(ctx, sc, recipient, "🎓 Деканат какого факультета нужен?", "🎓 Выбрать факультет", ServiceDeanSchedule)
+
+	case intent.Campus:
+		if e.CampusID == 0 {
+			return showCampusSelection(ctx, sc, recipient)
+		}
+		var campus models.Campus
+		if err := sc.DB.First(&campus, e.CampusID).Error; err != nil {
+			return fmt.Errorf("failed to fetch campus: %w", err)
+		}
+		return sendCampusInfo(ctx, sc, campus, recipient)
+
+	case intent.Places:
+		campus := user.Campus
+		if e.CampusID != 0 {
+			campus = &models.Campus{}
+			if err := sc.DB.First(campus, e.CampusID).Error; err != nil {
+				return fmt.Errorf("failed to fetch campus: %w", err)
+			}
+		}
+		switch {
+		case campus != nil && e.PlaceType != "":
+			return showPlacesByType(ctx, sc, e.PlaceType, campus.ID, recipient)
+		case campus != nil:
+			return showPlaceTypesMenu(ctx, sc, *campus, recipient)
+		default:
+			return showCampusSelectionForPlaces(ctx, sc, recipient)
+		}
+
+	case intent.OpenNow:
+		if kind := openNowKind(e.PlaceType); kind != "" {
+			return showOpenNow(ctx, sc, userID, kind, recipient)
+		}
+		return showOpenNowPick(ctx, sc, recipient)
+
+	case intent.Timetable:
+		group := user.Group
+		if e.GroupID != 0 {
+			group = &models.Group{}
+			if err := sc.DB.First(group, e.GroupID).Error; err != nil {
+				return fmt.Errorf("failed to fetch group: %w", err)
+			}
+		}
+		if group != nil {
+			return ttSendTimetable(ctx, sc, recipient, *group, ttToday)
+		}
+		return intentOffer(ctx, sc, recipient, "🗓 Чьё расписание показать? Выберите группу — бот её запомнит.",
+			"🗓 Моё расписание", ServiceMyTimetable)
+	}
+	return fmt.Errorf("unknown intent: %s", in)
+}
+
+// intentAsk - оценки близки: кнопка на каждый подходящий раздел
+func intentAsk(ctx context.Context, sc Ctx, recipient schemes.Recipient, picked []intent.Result, e intent.Entities, g intent.Gazetteer) error {
+	kb := sc.API.NewKeyboardBuilder()
+	for _, r := range picked {
+		label, link := intentButton(r.Intent, e, g)
+		kb.AddRow().AddCallback(label, schemes.POSITIVE, link)
+	}
+	kb.AddRow().AddCallback("🏠 Главное меню", schemes.NEGATIVE, BackToMenu)
+
+	msg := maxbot.NewMessage()
+	setRecipient(msg, recipient)
+	msg.SetText("🤔 Уточните, что вы ищете:").AddKeyboard(kb)
+	_, err := sc.API.Send(ctx, msg)
+	return err
+}
+
+// intentButton - подпись и payload кнопки раздела in с учётом сущностей
+func intentButton(in intent.Intent, e intent.Entities, g intent.Gazetteer) (string, string) {
+	switch in {
+	case intent.Teacher:
+		if e.Teacher != "" {
+			return "👤 Преподаватель " + e.Teacher, payload(FT_Page, ftModeFIO, 0, encodeQuery(e.Teacher))
+		}
+		return "👤 Найти преподавателя", ServiceFindTeacher
+	case intent.Dean:
+		if e.FacultyID != 0 {
+			return "🎓 Деканат " + gazetteerName(g.Faculties, e.FacultyID), payload(Dean_Faculty, e.FacultyID)
+		}
+		return "🎓 Деканат", ServiceDeanSchedule
+	case intent.Campus:
+		if e.CampusID != 0 {
+			return "🏫 " + gazetteerName(g.Campuses, e.CampusID), payload(CampusCard, e.CampusID)
+		}
+		return "🏫 Корпуса", ServiceCampusInfo
+	case intent.Places:
+		switch {
+		case e.CampusID != 0 && e.PlaceType != "":
+			return placeIcon(e.PlaceType) + " " + placeTypeTitles[e.PlaceType] + " — " + gazetteerName(g.Campuses, e.CampusID),
+				payload(PlacesByType, e.CampusID, e.PlaceType)
+		case e.CampusID != 0:
+			return "🍽️ Столовые и копирки — " + gazetteerName(g.Campuses, e.CampusID), payload(PlacesCampus, e.CampusID)
+		}
+		return "🍽️ Столовые и копирки", ServiceFoodAndCopy
+	case intent.OpenNow:
+		if kind := openNowKind(e.PlaceType); kind != "" {
+			return "🟢 Что открыто сейчас", payload(OpenNowList, kind)
+		}
+		return "🟢 Что открыто сейчас", OpenNowPick
+	case intent.Timetable:
+		if e.GroupID != 0 {
+			return "🗓 Расписание " + gazetteerName(g.Groups, e.GroupID), payload(TT_Show, e.GroupID, ttToday)
+		}
+		return "🗓 Моё расписание", ServiceMyTimetable
+	}
+	return "🏠 Главное меню", BackToMenu
+}
+
+// подписи типов мест в кнопках
+var placeTypeTitles = map[string]string{"canteen": "Столовые", "buffet": "Буфеты", "copy": "Копировальные центры"}
+
+// openNowKind - вид «открыто сейчас» по типу места
+func openNowKind(placeType string) string {
+	switch placeType {
+	case "canteen", "buffet":
+		return "food"
+	case "copy":
+		return "copy"
+	}
+	return ""
+}
+
+// gazetteerName - первое название сущности с данным ID (у корпуса — короткое)
+func gazetteerName(names []intent.Name, id uint) string {
+	for _, n := range names {
+		if n.ID == id {
+			return n.Text
+		}
+	}
+	return ""
+}
+
+// intentOffer - раздел понятен, но без уточнения не открыть: подсказка и кнопка раздела
+func intentOffer(ctx context.Context, sc Ctx, recipient schemes.Recipient, text, label, link string) error {
+	kb := sc.API.NewKeyboardBuilder()
+	kb.AddRow().AddCallback(label, schemes.POSITIVE, link)
+	kb.AddRow().AddCallback("🏠 Главное меню", schemes.NEGATIVE, BackToMenu)
+
+	msg := maxbot.NewMessage()
+	setRecipient(msg, recipient)
+	msg.SetText(text).AddKeyboard(kb)
+	_, err := sc.API.Send(ctx, msg)
+	return err
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/Karielka/Hackaton_MAX/internal/text"
+)
+
+// Поиск справочников по введённому тексту.
+//
+// Справочники маленькие (факультеты, кафедры, группы), поэтому названия читаются целиком и
+// сравниваются в Go через internal/text: падеж, ё/е и раскладка не важны — «информационные
+// системы» находит «Кафедру информационных систем», а «byajhvfnbrf» — «Информатику».
+// Лишние слова вроде «деканате» в «деканате ИУ» не мешают. Найденные ID затем подставляются
+// в обычный запрос со своей сортировкой и страницами.
+
+// matchIDs - ID записей model, у которых колонка col подходит под запрос query (text.Filter)
+func matchIDs(sc Ctx, model any, col, query string) ([]uint, error) {
+	var rows []struct {
+		ID   uint
+		Name string
+	}
+	if err := sc.DB.Model(model).Select("id, " + col + " AS name").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch %T names: %w", model, err)
+	}
+	names := make([]string, len(rows))
+	for i, r := range rows {
+		names[i] = r.Name
+	}
+	ids := []uint{}
+	for _, i := range text.Filter(query, names) {
+		ids = append(ids, rows[i].ID)
+	}
+	return ids, nil
+}
+
+// sameName - название совпадает с запросом с точностью до регистра, ё/е и пробелов
+func sameName(name, query string) bool {
+	return text.Fold(name) == text.Fold(query)
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+	"github.com/rs/zerolog/log"
+)
+
+// Messenger - часть MAX API, которой пользуются сервисы.
+// В проде это клиент SDK, в тестах — запись отправленных сообщений (internal/botsim).
+type Messenger interface {
+	Send(ctx context.Context, m *maxbot.Message) (string, error)
+	NewKeyboardBuilder() *maxbot.Keyboard
+	// UploadFile загружает файл в MAX; результат прикладывается к сообщению через AddFile
+	UploadFile(ctx context.Context, name string, data []byte) (*schemes.UploadedInfo, error)
+	// UploadPhoto загружает картинку; результат прикладывается через AddPhoto
+	UploadPhoto(ctx context.Context, name string, data []byte) (*schemes.PhotoTokens, error)
+}
+
+// sendWithPhoto - сообщение с картинкой image (ссылка или файл из MEDIA_DIR, см. internal/media).
+// Картинка — не главное: если её не удалось загрузить или MAX не принял токены,
+// сообщение уходит без неё, а токены забываются, чтобы в следующий раз загрузить заново.
+func sendWithPhoto(ctx context.Context, sc Ctx, recipient schemes.Recipient, image, text string, kb *maxbot.Keyboard) error {
+	build := func(photo *schemes.PhotoTokens) *maxbot.Message {
+		msg := maxbot.NewMessage()
+		setRecipient(msg, recipient)
+		msg.SetText(text)
+		if photo != nil {
+			msg.AddPhoto(photo)
+		}
+		if kb != nil {
+			msg.AddKeyboard(kb)
+		}
+		return msg
+	}
+
+	if sc.Media != nil && image != "" {
+		photo, err := sc.Media.Photo(ctx, sc.API, image)
+		if err != nil {
+			log.Warn().Err(err).Str("image", image).Msg("image upload failed, sending text only")
+		}
+		if photo != nil {
+			_, err := sc.API.Send(ctx, build(photo))
+			if err == nil {
+				return nil
+			}
+			log.Warn().Err(err).Str("image", image).Msg("message with image rejected, sending text only")
+			if err := sc.Media.Forget(image); err != nil {
+				log.Warn().Err(err).Str("image", image).Msg("failed to forget image tokens")
+			}
+		}
+	}
+	_, err := sc.API.Send(ctx, build(nil))
+	return err
+}
+
+// maxMessenger - Messenger поверх *maxbot.Api
+type maxMessenger struct {
+	api     *maxbot.Api
+	token   string // токен бота и адрес Bot API — для загрузки файлов в обход SDK
+	baseURL string
+	http    *http.Client
+}
+
+// NewMaxMessenger - Messenger для бота с токеном token; baseURL — адрес Bot API из конфига
+func NewMaxMessenger(api *maxbot.Api, token, baseURL string) Messenger {
+	if baseURL == "" {
+		baseURL = "https://botapi.max.ru/"
+	}
+	return maxMessenger{api: api, token: token, baseURL: baseURL, http: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// попытки отправить сообщение, пока MAX обрабатывает только что загруженный файл
+const (
+	attachmentRetries = 5
+	attachmentPause   = time.Second
+)
+
+func (m maxMessenger) Send(ctx context.Context, msg *maxbot.Message) (string, error) {
+	for i := 0; ; i++ {
+		id, err := m.api.Messages.Send(ctx, msg)
+		if err == nil || i == attachmentRetries || !strings.Contains(err.Error(), "attachment.not.ready") {
+			return id, err
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(attachmentPause):
+		}
+	}
+}
+
+func (m maxMessenger) NewKeyboardBuilder() *maxbot.Keyboard {
+	return m.api.Messages.NewKeyboardBuilder()
+}
+
+func (m maxMessenger) UploadFile(ctx context.Context, name string, data []byte) (*schemes.UploadedInfo, error) {
+	var info schemes.UploadedInfo
+	endpoint, err := m.upload(ctx, schemes.FILE, name, data, &info)
+	if err != nil {
+		return nil, err
+	}
+	if info.Token == "" {
+		info.Token = endpoint.Token // для части типов токен выдаётся вместе с адресом
+	}
+	if info.Token == "" {
+		return nil, fmt.Errorf("failed to upload %s: no token in response", name)
+	}
+	return &info, nil
+}
+
+// UploadPhoto - картинка через SDK: имя файла для фото не важно, MAX отвечает токенами в поле photos.
+// Ответ с ошибкой SDK не проверяет, поэтому пустые токены — тоже ошибка.
+func (m maxMessenger) UploadPhoto(ctx context.Context, name string, data []byte) (*schemes.PhotoTokens, error) {
+	photo, err := m.api.Uploads.UploadPhotoFromReader(ctx, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload %s: %w", name, err)
+	}
+	if len(photo.Photos) == 0 {
+		return nil, fmt.Errorf("failed to upload %s: no photo tokens in response", name)
+	}
+	return photo, nil
+}
+
+// upload - загрузка файла в два шага: POST /uploads выдаёт адрес, туда уходит multipart с файлом, ответ — в out.
+// Не через SDK: его UploadMediaFromReader отправляет файл под именем «file» (телефону для .ics нужно
+// настоящее имя с расширением), а ответ сервера загрузки только печатает в лог. Токен бота идёт
+// параметром access_token так же, как во всех запросах SDK этой версии.
+func (m maxMessenger) upload(ctx context.Context, typ schemes.UploadType, name string, data []byte, out any) (schemes.UploadEndpoint, error) {
+	var endpoint schemes.UploadEndpoint
+	u, err := url.JoinPath(m.baseURL, "uploads")
+	if err != nil {
+		return endpoint, fmt.Errorf("bad bot api url: %w", err)
+	}
+	q := url.Values{"type": {string(typ)}, "access_token": {m.token}}
+	if err := m.postJSON(ctx, u+"?"+q.Encode(), "", nil, &endpoint); err != nil {
+		return endpoint, fmt.Errorf("failed to get upload url: %w", err)
+	}
+	if endpoint.Url == "" {
+		return endpoint, errors.New("failed to get upload url: empty url")
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("data", name)
+	if err != nil {
+		return endpoint, err
+	}
+	if _, err := fw.Write(data); err != nil {
+		return endpoint, err
+	}
+	if err := mw.Close(); err != nil {
+		return endpoint, err
+	}
+	if err := m.postJSON(ctx, endpoint.Url, mw.FormDataContentType(), &body, out); err != nil {
+		return endpoint, fmt.Errorf("failed to upload %s: %w", name, err)
+	}
+	return endpoint, nil
+}
+
+func (m maxMessenger) postJSON(ctx context.Context, u, contentType string, body io.Reader, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, body)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := m.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("http %d: %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, out)
+}
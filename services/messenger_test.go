@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/Karielka/Hackaton_MAX/internal/media"
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// stubMessenger отклоняет первые reject отправок (как MAX — протухшие токены фото)
+type stubMessenger struct {
+	reject  int
+	sends   int
+	uploads int
+}
+
+func (m *stubMessenger) Send(context.Context, *maxbot.Message) (string, error) {
+	m.sends++
+	if m.sends <= m.reject {
+		return "", errors.New("attachment.token.invalid")
+	}
+	return "mid", nil
+}
+
+func (m *stubMessenger) NewKeyboardBuilder() *maxbot.Keyboard { return &maxbot.Keyboard{} }
+
+func (m *stubMessenger) UploadFile(context.Context, string, []byte) (*schemes.UploadedInfo, error) {
+	return &schemes.UploadedInfo{Token: "file"}, nil
+}
+
+func (m *stubMessenger) UploadPhoto(context.Context, string, []byte) (*schemes.PhotoTokens, error) {
+	m.uploads++
+	return &schemes.PhotoTokens{Photos: map[string]schemes.PhotoToken{"1": {Token: "photo"}}}, nil
+}
+
+func TestSendWithPhotoForgetsRejectedTokens(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(t.TempDir()+"/messenger.db"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.MediaUpload{}); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "gz.jpg"), []byte("jpg"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	api := &stubMessenger{reject: 1}
+	sc := Ctx{DB: db, API: api, Media: media.New(db, dir)}
+	ctx := context.Background()
+	cached := func() int64 {
+		var n int64
+		if err := db.Model(&models.MediaUpload{}).Count(&n).Error; err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	// MAX отклонил сообщение с фото: ушло текстом, токены забыты
+	if err := sendWithPhoto(ctx, sc, schemes.Recipient{UserId: 1}, "gz.jpg", "ГУК", nil); err != nil {
+		t.Fatal(err)
+	}
+	if api.sends != 2 || api.uploads != 1 || cached() != 0 {
+		t.Fatalf("rejected: sends %d, uploads %d, cached %d; want 2, 1, 0", api.sends, api.uploads, cached())
+	}
+
+	// следующая отправка загружает картинку заново и снова кеширует
+	if err := sendWithPhoto(ctx, sc, schemes.Recipient{UserId: 1}, "gz.jpg", "ГУК", nil); err != nil {
+		t.Fatal(err)
+	}
+	if api.sends != 3 || api.uploads != 2 || cached() != 1 {
+		t.Errorf("accepted: sends %d, uploads %d, cached %d; want 3, 2, 1", api.sends, api.uploads, cached())
+	}
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// «🟢 Открыто сейчас»: открытые места нужного вида во всех корпусах, ближайшие к корпусу из профиля — сверху
+const (
+	OpenNowPick = "open_now"            // выбор: поесть или распечатать
+	OpenNowList = "open_now/{kind:str}" // список: food | copy
+)
+
+// виды «что нужно» и типы мест под ними
+var openNowKinds = map[string][]string{
+	"food": {"canteen", "buffet"},
+	"copy": {"copy"},
+}
+
+// сколько мест показываем в ответе
+const openNowLimit = 8
+
+func registerOpenNowRoutes(r *Router) {
+	r.Handle(OpenNowPick, func(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, _ Params) error {
+		return showOpenNowPick(ctx, sc, upd.Message.Recipient)
+	}, resetDialog)
+	r.Handle(OpenNowList, func(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+		if _, ok := openNowKinds[p.Str("kind")]; !ok {
+			return fmt.Errorf("unknown open-now kind: %s", p.Str("kind"))
+		}
+		return showOpenNow(ctx, sc, upd.Callback.User.UserId, p.Str("kind"), upd.Message.Recipient)
+	})
+}
+
+// showOpenNowPick - что ищем: поесть или распечатать
+func showOpenNowPick(ctx context.Context, sc Ctx, recipient schemes.Recipient) error {
+	kb := sc.API.NewKeyboardBuilder()
+	kb.AddRow().
+		AddCallback("🍽️ Поесть", schemes.POSITIVE, payload(OpenNowList, "food")).
+		AddCallback("📄 Распечатать", schemes.POSITIVE, payload(OpenNowList, "copy"))
+	kb.AddRow().AddCallback("🏠 Главное меню", schemes.NEGATIVE, BackToMenu)
+
+	msg := maxbot.NewMessage()
+	setRecipient(msg, recipient)
+	msg.SetText("🟢 Что ищем из открытого прямо сейчас?").AddKeyboard(kb)
+	_, err := sc.API.Send(ctx, msg)
+	return err
+}
+
+// openPlace - место в ответе «открыто сейчас»
+type openPlace struct {
+	place  models.Place
+	campus models.Campus
+	status models.OpenStatus
+	km     float64 // от корпуса пользователя; +Inf — неизвестно
+}
+
+// showOpenNow - открытые сейчас места вида kind во всех корпусах.
+// Ранжирование: свой корпус, затем ближние по расстоянию между корпусами, при равенстве — кто дольше открыт.
+func showOpenNow(ctx context.Context, sc Ctx, userID int64, kind string, recipient schemes.Recipient) error {
+	user, _, err := loadUser(sc, userID)
+	if err != nil {
+		return err
+	}
+	var places []models.Place
+	if err := sc.DB.Where("type IN ?", openNowKinds[kind]).Find(&places).Error; err != nil {
+		return fmt.Errorf("failed to fetch places: %w", err)
+	}
+	var campuses []models.Campus
+	if err := sc.DB.Find(&campuses).Error; err != nil {
+		return fmt.Errorf("failed to fetch campuses: %w", err)
+	}
+	campusByID := make(map[uint]models.Campus, len(campuses))
+	for _, c := range campuses {
+		campusByID[c.ID] = c
+	}
+
+	now := sc.now()
+	ids := make([]uint, len(places))
+	for i, p := range places {
+		ids[i] = p.ID
+	}
+	hours, err := models.PlaceHours(sc.DB, now, ids...)
+	if err != nil {
+		return err
+	}
+
+	var open, later []openPlace
+	for _, p := range places {
+		op := openPlace{place: p, campus: campusByID[p.CampusID], status: hours[p.ID].StatusAt(now), km: math.Inf(1)}
+		if user.Campus != nil {
+			if p.CampusID == user.Campus.ID {
+				op.km = 0
+			} else if km, ok := user.Campus.DistanceKm(op.campus); ok {
+				op.km = km
+			}
+		}
+		switch {
+		case op.status.Open:
+			open = append(open, op)
+		case !op.status.Next.IsZero():
+			later = append(later, op)
+		}
+	}
+	slices.SortStableFunc(open, func(a, b openPlace) int {
+		if c := cmp.Compare(a.km, b.km); c != 0 {
+			return c
+		}
+		return b.status.Until.Compare(a.status.Until)
+	})
+
+	what := map[string]string{"food": "поесть", "copy": "распечатать"}[kind]
+	var b strings.Builder
+	kb := sc.API.NewKeyboardBuilder()
+	if len(open) == 0 {
+		fmt.Fprintf(&b, "🔴 Сейчас (%s) %s негде — всё закрыто.", now.Format("15:04"), what)
+		if len(later) > 0 {
+			soonest := slices.MinFunc(later, func(a, b openPlace) int { return a.status.Next.Compare(b.status.Next) })
+			fmt.Fprintf(&b, "\n\nРаньше всех откроется %s (%s) — %s в %s.", soonest.place.Name, soonest.campus.ShortName,
+				relativeDay(now, soonest.status.Next), clockAt(now, soonest.status.Next))
+			kb.AddRow().AddCallback(placeIcon(soonest.place.Type)+" "+soonest.place.Name, schemes.POSITIVE,
+				payload(PlaceCard, soonest.place.ID))
+		}
+	} else {
+		fmt.Fprintf(&b, "🟢 Где %s прямо сейчас (%s):\n", what, now.Format("15:04"))
+		for i, op := range open[:min(len(open), openNowLimit)] {
+			fmt.Fprintf(&b, "\n%d. %s %s — %s%s\n", i+1, placeIcon(op.place.Type), op.place.Name,
+				op.campus.ShortName, distanceNote(op.km))
+			if op.place.Location != "" {
+				fmt.Fprintf(&b, "   📍 %s\n", op.place.Location)
+			}
+			fmt.Fprintf(&b, "   %s\n", hoursLine(now, op.status))
+			kb.AddRow().AddCallback(fmt.Sprintf("%d. %s", i+1, op.place.Name), schemes.DEFAULT,
+				payload(PlaceCard, op.place.ID))
+		}
+		if len(open) > openNowLimit {
+			fmt.Fprintf(&b, "\n…и ещё %d.", len(open)-openNowLimit)
+		}
+	}
+	text := strings.TrimRight(b.String(), "\n")
+	if user.Campus == nil {
+		text += "\n\nУкажите свой корпус в профиле — ближайшие места будут сверху."
+	}
+
+	kb.AddRow().
+		AddCallback("◀️ Назад", schemes.NEGATIVE, OpenNowPick).
+		AddCallback("🏠 Главное меню", schemes.NEGATIVE, BackToMenu)
+
+	msg := maxbot.NewMessage()
+	setRecipient(msg, recipient)
+	msg.SetText(text).AddKeyboard(kb)
+	_, err = sc.API.Send(ctx, msg)
+	return err
+}
+
+// distanceNote - « (ваш корпус)», «, 1,2 км» или пусто, если расстояние неизвестно
+func distanceNote(km float64) string {
+	switch {
+	case km == 0:
+		return " (ваш корпус)"
+	case math.IsInf(km, 1):
+		return ""
+	case km < 1:
+		return fmt.Sprintf(", %d м", int(math.Round(km*1000/50))*50)
+	default:
+		return ", " + strings.Replace(fmt.Sprintf("%.1f км", km), ".", ",", 1)
+	}
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+)
+
+// Постраничный вывод списков.
+//
+// Кнопки «◀️ / ▶️» несут в payload сам запрос и смещение, поэтому листать
+// можно без состояния диалога — в том числе старые сообщения после рестарта.
+
+// PageSize - сколько результатов на одной странице
+const PageSize = 5
+
+// максимальная длина запроса, которую кладём в payload (в рунах, до экранирования)
+const maxPagedQuery = 64
+
+// Page - окно [Offset, Offset+PageSize) в списке из Total элементов
+type Page struct {
+	Offset int
+	Total  int
+}
+
+// newPage выравнивает смещение: отрицательное -> 0, за концом списка -> последняя страница
+func newPage(offset, total int) Page {
+	if offset >= total {
+		offset = (total - 1) / PageSize * PageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return Page{Offset: offset, Total: total}
+}
+
+// End - индекс за последним элементом страницы
+func (p Page) End() int { return min(p.Offset+PageSize, p.Total) }
+
+func (p Page) HasPrev() bool { return p.Offset > 0 }
+func (p Page) HasNext() bool { return p.End() < p.Total }
+
+// Header - «Найдено преподавателей: 23 · стр. 2 из 5»
+func (p Page) Header(title string) string {
+	pages := max((p.Total+PageSize-1)/PageSize, 1)
+	if pages == 1 {
+		return fmt.Sprintf("%s: %d", title, p.Total)
+	}
+	return fmt.Sprintf("%s: %d · стр. %d из %d", title, p.Total, p.Offset/PageSize+1, pages)
+}
+
+// addPager добавляет ряд «◀️ / ▶️», если есть куда листать.
+// link строит payload для страницы с заданным смещением.
+func addPager(kb *maxbot.Keyboard, p Page, link func(offset int) string) {
+	if !p.HasPrev() && !p.HasNext() {
+		return
+	}
+	row := kb.AddRow()
+	if p.HasPrev() {
+		row.AddCallback("◀️", schemes.DEFAULT, link(max(p.Offset-PageSize, 0)))
+	}
+	if p.HasNext() {
+		row.AddCallback("▶️", schemes.DEFAULT, link(p.Offset+PageSize))
+	}
+}
+
+// encodeQuery готовит текст запроса к передаче сегментом payload ({q:str})
+func encodeQuery(q string) string {
+	if r := []rune(q); len(r) > maxPagedQuery {
+		q = string(r[:maxPagedQuery])
+	}
+	return url.PathEscape(q)
+}
+
+// decodeQuery - обратное к encodeQuery; битый сегмент возвращаем как есть
+func decodeQuery(s string) string {
+	q, err := url.PathUnescape(s)
+	if err != nil {
+		return s
+	}
+	return q
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+)
+
+func TestNewPage(t *testing.T) {
+	tests := []struct {
+		offset, total int
+		want          Page
+		end           int
+		prev, next    bool
+		header        string
+	}{
+		{0, 0, Page{0, 0}, 0, false, false, "Найдено: 0"},
+		{0, 3, Page{0, 3}, 3, false, false, "Найдено: 3"},
+		{0, 5, Page{0, 5}, 5, false, false, "Найдено: 5"},
+		{0, 6, Page{0, 6}, 5, false, true, "Найдено: 6 · стр. 1 из 2"},
+		{5, 6, Page{5, 6}, 6, true, false, "Найдено: 6 · стр. 2 из 2"},
+		{5, 23, Page{5, 23}, 10, true, true, "Найдено: 23 · стр. 2 из 5"},
+		{-5, 23, Page{0, 23}, 5, false, true, "Найдено: 23 · стр. 1 из 5"},
+		{100, 23, Page{20, 23}, 23, true, false, "Найдено: 23 · стр. 5 из 5"},
+		{10, 10, Page{5, 10}, 10, true, false, "Найдено: 10 · стр. 2 из 2"},
+	}
+	for _, tt := range tests {
+		p := newPage(tt.offset, tt.total)
+		if p != tt.want {
+			t.Errorf("newPage(%d, %d) = %+v, want %+v", tt.offset, tt.total, p, tt.want)
+			continue
+		}
+		if p.End() != tt.end || p.HasPrev() != tt.prev || p.HasNext() != tt.next {
+			t.Errorf("%+v: End %d, HasPrev %v, HasNext %v", p, p.End(), p.HasPrev(), p.HasNext())
+		}
+		if h := p.Header("Найдено"); h != tt.header {
+			t.Errorf("%+v: Header = %q, want %q", p, h, tt.header)
+		}
+	}
+}
+
+func TestAddPager(t *testing.T) {
+	link := func(offset int) string { return payload(FT_Page, ftModeFIO, offset, encodeQuery("Иванов")) }
+	buttons := func(p Page) []string {
+		kb := &maxbot.Keyboard{}
+		addPager(kb, p, link)
+		var out []string
+		for _, row := range kb.Build().Buttons {
+			for _, b := range row {
+				out = append(out, b.GetText())
+			}
+		}
+		return out
+	}
+	if got := buttons(newPage(0, 5)); got != nil {
+		t.Errorf("single page has pager %q", got)
+	}
+	if got := buttons(newPage(5, 23)); !reflect.DeepEqual(got, []string{"◀️", "▶️"}) {
+		t.Errorf("middle page pager = %q", got)
+	}
+	if got := buttons(newPage(20, 23)); !reflect.DeepEqual(got, []string{"◀️"}) {
+		t.Errorf("last page pager = %q", got)
+	}
+}
+
+// Запрос проходит через payload и роутер и возвращается тем же (с обрезкой до maxPagedQuery рун)
+func TestQueryRoundTrip(t *testing.T) {
+	var got string
+	var gotOffset uint
+	r := &Router{}
+	r.Handle(FT_Page, func(_ context.Context, _ Ctx, _ *schemes.MessageCallbackUpdate, p Params) error {
+		got, gotOffset = decodeQuery(p.Str("q")), p.ID("offset")
+		return nil
+	})
+	long := strings.Repeat("я", maxPagedQuery+10)
+	for _, q := range []string{
+		"Иванов",
+		"иванов и.и.",
+		"ИУ5-31Б",
+		"a/b/c",
+		"100% правда?",
+		"  пробелы  по краям ",
+		"#hash&amp=1",
+		long,
+	} {
+		data := payload(FT_Page, ftModeFIO, 10, encodeQuery(q))
+		got, gotOffset = "", 0
+		if err := r.Route(context.Background(), Ctx{}, callback(data)); err != nil {
+			t.Fatal(err)
+		}
+		want := q
+		if q == long {
+			want = strings.Repeat("я", maxPagedQuery)
+		}
+		if got != want || gotOffset != 10 {
+			t.Errorf("%q -> payload %q -> %q, offset %d", q, data, got, gotOffset)
+		}
+	}
+	if got := decodeQuery("%zz"); got != "%zz" {
+		t.Errorf("decodeQuery of a broken segment = %q", got)
+	}
+}
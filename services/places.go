@@ -4,15 +4,95 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/Karielka/Hackaton_MAX/models"
 	maxbot "github.com/max-messenger/max-bot-api-client-go"
 	"github.com/max-messenger/max-bot-api-client-go/schemes"
 )
 
-// Places_Handle - обработчик меню "Столовые/копирки"
+// Маршруты кнопок мест
+const (
+	PlacesCampus = "places/campus/{id}"            // типы мест в корпусе
+	PlacesByType = "places/campus/{id}/{type:str}" // места одного типа: canteen | buffet | copy
+	PlaceCard    = "place/{id}"                    // одно место (из избранного)
+	PlaceMenu    = "place/{id}/menu/{date:str}"    // меню на день, дата в формате 2006-01-02
+	PlacesPick   = "places_pick_campus"            // выбрать корпус вместо своего
+)
+
+func registerPlacesRoutes(r *Router) {
+	r.Handle(ServiceFoodAndCopy, noParams(Places_Handle), resetDialog)
+	r.Handle(PlacesPick, func(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, _ Params) error {
+		return showCampusSelectionForPlaces(ctx, sc, upd.Message.Recipient)
+	}, resetDialog)
+	r.Handle(PlacesCampus, handleCampusSelectionForPlaces)
+	r.Handle(PlacesByType, handlePlaceTypeSelection)
+	r.Handle(PlaceCard, handlePlaceCard)
+	r.Handle(PlaceMenu, handlePlaceMenu)
+}
+
+// placeIcon - значок типа места
+func placeIcon(placeType string) string {
+	switch placeType {
+	case "canteen":
+		return "🍽️"
+	case "buffet":
+		return "☕"
+	case "copy":
+		return "📄"
+	default:
+		return "📍"
+	}
+}
+
+// handlePlaceCard - одно место: столовая или буфет — с меню на сегодня, копирка — как список из одного
+func handlePlaceCard(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	var place models.Place
+	if err := sc.DB.First(&place, p.ID("id")).Error; err != nil {
+		msg := maxbot.NewMessage()
+		setRecipient(msg, upd.Message.Recipient)
+		msg.SetText("Место не найдено.")
+		_, err := sc.API.Send(ctx, msg)
+		return err
+	}
+	if place.HasMenu() {
+		return showFoodCard(ctx, sc, place, sc.now(), upd.Message.Recipient)
+	}
+	return showPlacesList(ctx, sc, []models.Place{place}, place.Type, place.CampusID, upd.Message.Recipient)
+}
+
+// handlePlaceMenu - карточка столовой или буфета с меню на выбранный день
+func handlePlaceMenu(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	day, err := time.ParseInLocation(time.DateOnly, p.Str("date"), Location)
+	if err != nil {
+		return fmt.Errorf("bad menu date %q: %w", p.Str("date"), err)
+	}
+	var place models.Place
+	if err := sc.DB.First(&place, p.ID("id")).Error; err != nil || !place.HasMenu() {
+		msg := maxbot.NewMessage()
+		setRecipient(msg, upd.Message.Recipient)
+		msg.SetText("Место не найдено.")
+		_, err := sc.API.Send(ctx, msg)
+		return err
+	}
+	return showFoodCard(ctx, sc, place, day, upd.Message.Recipient)
+}
+
+// Places_Handle - обработчик меню "Столовые/копирки": сразу свой корпус из профиля, если он указан
 func Places_Handle(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate) error {
-	return showCampusSelectionForPlaces(ctx, sc, upd.Message.Recipient)
+	return showPlacesDefault(ctx, sc, upd.Callback.User.UserId, upd.Message.Recipient)
+}
+
+// showPlacesDefault - места в корпусе пользователя, без корпуса в профиле — выбор корпуса
+func showPlacesDefault(ctx context.Context, sc Ctx, userID int64, recipient schemes.Recipient) error {
+	user, ok, err := loadUser(sc, userID)
+	if err != nil {
+		return err
+	}
+	if ok && user.Campus != nil {
+		return showPlaceTypesMenu(ctx, sc, *user.Campus, recipient)
+	}
+	return showCampusSelectionForPlaces(ctx, sc, recipient)
 }
 
 // showCampusSelectionForPlaces - показывает выбор корпуса для мест
@@ -26,43 +106,41 @@ func showCampusSelectionForPlaces(ctx context.Context, sc Ctx, recipient schemes
 		msg := maxbot.NewMessage()
 		setRecipient(msg, recipient)
 		msg.SetText("Информация о корпусах временно недоступна.")
-		_, err := sc.API.Messages.Send(ctx, msg)
+		_, err := sc.API.Send(ctx, msg)
 		return err
 	}
 
-	kb := sc.API.Messages.NewKeyboardBuilder()
+	kb := sc.API.NewKeyboardBuilder()
 
 	for i := 0; i < len(campuses); i += 2 {
 		row := kb.AddRow()
 		row.AddCallback(campuses[i].ShortName, schemes.POSITIVE,
-			fmt.Sprintf("places_campus_%d", campuses[i].ID))
+			payload(PlacesCampus, campuses[i].ID))
 
 		if i+1 < len(campuses) {
 			row.AddCallback(campuses[i+1].ShortName, schemes.POSITIVE,
-				fmt.Sprintf("places_campus_%d", campuses[i+1].ID))
+				payload(PlacesCampus, campuses[i+1].ID))
 		}
 	}
 
-	kb.AddRow().AddCallback("◀️ Назад", schemes.NEGATIVE, "back_to_menu")
+	kb.AddRow().AddCallback("◀️ Назад", schemes.NEGATIVE, BackToMenu)
 
 	msg := maxbot.NewMessage()
 	setRecipient(msg, recipient)
 	msg.SetText("🏢 О каком корпусе идет речь?").AddKeyboard(kb)
 
-	_, err := sc.API.Messages.Send(ctx, msg)
+	_, err := sc.API.Send(ctx, msg)
 	return err
 }
 
 // handleCampusSelectionForPlaces - обработчик выбора корпуса для мест
-func handleCampusSelectionForPlaces(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate) error {
-	campusID := strings.TrimPrefix(upd.Callback.Payload, "places_campus_")
-
+func handleCampusSelectionForPlaces(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
 	var campus models.Campus
-	if err := sc.DB.First(&campus, campusID).Error; err != nil {
+	if err := sc.DB.First(&campus, p.ID("id")).Error; err != nil {
 		msg := maxbot.NewMessage()
 		setRecipient(msg, upd.Message.Recipient)
 		msg.SetText("Корпус не найден.")
-		_, err := sc.API.Messages.Send(ctx, msg)
+		_, err := sc.API.Send(ctx, msg)
 		return err
 	}
 
@@ -79,7 +157,7 @@ func showPlaceTypesMenu(ctx context.Context, sc Ctx, campus models.Campus, recip
 		return fmt.Errorf("failed to fetch place types: %w", err)
 	}
 
-	kb := sc.API.Messages.NewKeyboardBuilder()
+	kb := sc.API.NewKeyboardBuilder()
 
 	hasCanteen := false
 	hasBuffet := false
@@ -90,24 +168,25 @@ func showPlaceTypesMenu(ctx context.Context, sc Ctx, campus models.Campus, recip
 		case "canteen":
 			hasCanteen = true
 			kb.AddRow().AddCallback("🍽️ Столовая", schemes.POSITIVE,
-				fmt.Sprintf("places_canteen_%d", campus.ID))
+				payload(PlacesByType, campus.ID, "canteen"))
 		case "buffet":
 			hasBuffet = true
 		case "copy":
 			hasCopy = true
 			kb.AddRow().AddCallback("📄 Копирки", schemes.POSITIVE,
-				fmt.Sprintf("places_copy_%d", campus.ID))
+				payload(PlacesByType, campus.ID, "copy"))
 		}
 	}
 
 	if hasBuffet {
 		kb.AddRow().AddCallback("☕ Буфеты", schemes.POSITIVE,
-			fmt.Sprintf("places_buffet_%d", campus.ID))
+			payload(PlacesByType, campus.ID, "buffet"))
 	}
 
+	kb.AddRow().AddCallback("🟢 Что открыто сейчас во всех корпусах", schemes.DEFAULT, OpenNowPick)
 	kb.AddRow().
-		AddCallback("◀️ К выбору корпуса", schemes.NEGATIVE, ServiceFoodAndCopy).
-		AddCallback("🏠 Главное меню", schemes.NEGATIVE, "back_to_menu")
+		AddCallback("◀️ К выбору корпуса", schemes.NEGATIVE, PlacesPick).
+		AddCallback("🏠 Главное меню", schemes.NEGATIVE, BackToMenu)
 
 	msg := maxbot.NewMessage()
 	setRecipient(msg, recipient)
@@ -118,35 +197,24 @@ func showPlaceTypesMenu(ctx context.Context, sc Ctx, campus models.Campus, recip
 	}
 
 	msg.SetText(text).AddKeyboard(kb)
-	_, err := sc.API.Messages.Send(ctx, msg)
+	_, err := sc.API.Send(ctx, msg)
 	return err
 }
 
 // handlePlaceTypeSelection - обработчик выбора типа места
-func handlePlaceTypeSelection(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate) error {
-	payload := upd.Callback.Payload
-
-	var placeType string
-	var campusID string
-
-	if strings.HasPrefix(payload, "places_canteen_") {
-		placeType = "canteen"
-		campusID = strings.TrimPrefix(payload, "places_canteen_")
-	} else if strings.HasPrefix(payload, "places_buffet_") {
-		placeType = "buffet"
-		campusID = strings.TrimPrefix(payload, "places_buffet_")
-	} else if strings.HasPrefix(payload, "places_copy_") {
-		placeType = "copy"
-		campusID = strings.TrimPrefix(payload, "places_copy_")
-	} else {
-		return fmt.Errorf("unknown place type payload: %s", payload)
+func handlePlaceTypeSelection(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	placeType := p.Str("type")
+	switch placeType {
+	case "canteen", "buffet", "copy":
+	default:
+		return fmt.Errorf("unknown place type: %s", placeType)
 	}
 
-	return showPlacesByType(ctx, sc, placeType, campusID, upd.Message.Recipient)
+	return showPlacesByType(ctx, sc, placeType, p.ID("id"), upd.Message.Recipient)
 }
 
 // showPlacesByType - показывает места определенного типа в корпусе
-func showPlacesByType(ctx context.Context, sc Ctx, placeType, campusID string, recipient schemes.Recipient) error {
+func showPlacesByType(ctx context.Context, sc Ctx, placeType string, campusID uint, recipient schemes.Recipient) error {
 	var places []models.Place
 	if err := sc.DB.Where("campus_id = ? AND type = ?", campusID, placeType).Find(&places).Error; err != nil {
 		return fmt.Errorf("failed to fetch places: %w", err)
@@ -163,60 +231,126 @@ func showPlacesByType(ctx context.Context, sc Ctx, placeType, campusID string, r
 		}[placeType]
 
 		msg.SetText(fmt.Sprintf("В этом корпусе нет %s.", typeName))
-		_, err := sc.API.Messages.Send(ctx, msg)
+		_, err := sc.API.Send(ctx, msg)
 		return err
 	}
 
 	if placeType == "canteen" && len(places) > 0 {
-		return showCanteenDetails(ctx, sc, places[0], campusID, recipient)
+		// несколько столовых — показываем ту, что открыта
+		if _, err := placesOpenFirst(sc, places); err != nil {
+			return err
+		}
+		return showFoodCard(ctx, sc, places[0], sc.now(), recipient)
 	}
 
 	return showPlacesList(ctx, sc, places, placeType, campusID, recipient)
 }
 
-// showCanteenDetails - показывает детальную информацию о столовой
-func showCanteenDetails(ctx context.Context, sc Ctx, place models.Place, campusID string, recipient schemes.Recipient) error {
+// showFoodCard - столовая или буфет: адрес, режим работы и меню на день day
+func showFoodCard(ctx context.Context, sc Ctx, place models.Place, day time.Time, recipient schemes.Recipient) error {
 	var campus models.Campus
-	if err := sc.DB.First(&campus, campusID).Error; err != nil {
+	if err := sc.DB.First(&campus, place.CampusID).Error; err != nil {
 		return fmt.Errorf("failed to fetch campus: %w", err)
 	}
+	menu, ok, err := models.FindMenu(sc.DB, place.ID, day)
+	if err != nil {
+		return err
+	}
+	hours, err := models.PlaceHours(sc.DB, sc.now(), place.ID)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s (%s)\n📍 Расположение: %s\n🕐 Режим работы: %s\n",
+		placeIcon(place.Type), place.Name, campus.ShortName, place.Location, place.Schedule)
+	if line := hoursLine(sc.now(), hours[place.ID].StatusAt(sc.now())); line != "" {
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("\n")
+	when := relativeDay(sc.now(), day)
+	if ok && len(menu.Dishes) > 0 {
+		fmt.Fprintf(&b, "📋 Меню на %s:\n", when)
+		writeMenu(&b, menu.Dishes)
+	} else {
+		fmt.Fprintf(&b, "📋 Меню на %s не опубликовано.", when)
+	}
 
-	text := fmt.Sprintf(
-		"🍽️ %s (%s)\n📍 Расположение: %s\n🕐 Режим работы: %s\n\n📋 Меню на сегодня:\n%s",
-		place.Name,
-		campus.ShortName,
-		place.Location,
-		place.Schedule,
-		place.MenuToday,
-	)
+	kb := sc.API.NewKeyboardBuilder()
 
-	kb := sc.API.Messages.NewKeyboardBuilder()
+	// Соседний день: завтрашнее меню — если его уже загрузили, с любого другого дня — назад к сегодняшнему
+	today := sc.now()
+	if today.Format(time.DateOnly) == day.Format(time.DateOnly) {
+		tomorrow := today.AddDate(0, 0, 1)
+		next, ok, err := models.FindMenu(sc.DB, place.ID, tomorrow)
+		if err != nil {
+			return err
+		}
+		if ok && len(next.Dishes) > 0 {
+			kb.AddRow().AddCallback("📅 Меню на завтра", schemes.POSITIVE,
+				payload(PlaceMenu, place.ID, tomorrow.Format(time.DateOnly)))
+		}
+	} else {
+		kb.AddRow().AddCallback("📋 Меню на сегодня", schemes.POSITIVE,
+			payload(PlaceMenu, place.ID, today.Format(time.DateOnly)))
+	}
 
-	var otherTypes []string
-	sc.DB.Model(&models.Place{}).
-		Where("campus_id = ? AND type != ?", campusID, "canteen").
-		Distinct("type").
-		Pluck("type", &otherTypes)
+	if place.Type == "canteen" {
+		var otherTypes []string
+		sc.DB.Model(&models.Place{}).
+			Where("campus_id = ? AND type != ?", place.CampusID, "canteen").
+			Distinct("type").
+			Pluck("type", &otherTypes)
 
-	if len(otherTypes) > 0 {
-		kb.AddRow().AddCallback("📋 Буфеты и копирки в этом корпусе", schemes.POSITIVE,
-			fmt.Sprintf("places_back_to_campus_%s", campusID))
+		if len(otherTypes) > 0 {
+			kb.AddRow().AddCallback("📋 Буфеты и копирки в этом корпусе", schemes.POSITIVE,
+				payload(PlacesCampus, place.CampusID))
+		}
 	}
+	favButton(kb, models.FavPlace, place.ID)
 
-	kb.AddRow().
-		AddCallback("◀️ К выбору типа", schemes.NEGATIVE, fmt.Sprintf("places_campus_%s", campusID)).
-		AddCallback("🏠 Главное меню", schemes.NEGATIVE, "back_to_menu")
+	back := kb.AddRow()
+	if place.Type == "buffet" {
+		back.AddCallback("◀️ К буфетам", schemes.NEGATIVE, payload(PlacesByType, place.CampusID, "buffet"))
+	} else {
+		back.AddCallback("◀️ К выбору типа", schemes.NEGATIVE, payload(PlacesCampus, place.CampusID))
+	}
+	back.AddCallback("🏠 Главное меню", schemes.NEGATIVE, BackToMenu)
 
 	msg := maxbot.NewMessage()
 	setRecipient(msg, recipient)
-	msg.SetText(text).AddKeyboard(kb)
+	msg.SetText(b.String()).AddKeyboard(kb)
 
-	_, err := sc.API.Messages.Send(ctx, msg)
+	_, err = sc.API.Send(ctx, msg)
 	return err
 }
 
+// writeMenu - блюда по категориям в порядке меню: «• Борщ, 250 г — 95 ₽»
+func writeMenu(b *strings.Builder, dishes []models.Dish) {
+	category := ""
+	for i, d := range dishes {
+		if d.Category != category {
+			category = d.Category
+			if category != "" {
+				if i > 0 {
+					b.WriteString("\n")
+				}
+				fmt.Fprintf(b, "%s:\n", category)
+			}
+		}
+		b.WriteString("• " + d.Name)
+		if d.Weight != "" {
+			b.WriteString(", " + d.Weight)
+		}
+		fmt.Fprintf(b, " — %s\n", models.FormatPrice(d.PriceKop))
+		if d.Allergens != "" {
+			fmt.Fprintf(b, "   ⚠️ Аллергены: %s\n", d.Allergens)
+		}
+	}
+}
+
 // showPlacesList - показывает список мест (буфетов или копирок)
-func showPlacesList(ctx context.Context, sc Ctx, places []models.Place, placeType, campusID string, recipient schemes.Recipient) error {
+func showPlacesList(ctx context.Context, sc Ctx, places []models.Place, placeType string, campusID uint, recipient schemes.Recipient) error {
 	var campus models.Campus
 	if err := sc.DB.First(&campus, campusID).Error; err != nil {
 		return fmt.Errorf("failed to fetch campus: %w", err)
@@ -230,16 +364,32 @@ func showPlacesList(ctx context.Context, sc Ctx, places []models.Place, placeTyp
 	var b strings.Builder
 	b.WriteString(fmt.Sprintf("📋 %s в %s:\n\n", typeName, campus.ShortName))
 
+	var hasMenu []models.Place // места, у которых есть меню на сегодня
+
+	statuses, err := placesOpenFirst(sc, places)
+	if err != nil {
+		return err
+	}
+
 	for i, place := range places {
 		b.WriteString(fmt.Sprintf("%d. %s\n", i+1, place.Name))
 		b.WriteString(fmt.Sprintf("   📍 %s\n", place.Location))
 		b.WriteString(fmt.Sprintf("   🕐 %s\n", place.Schedule))
+		if line := hoursLine(sc.now(), statuses[place.ID]); line != "" {
+			b.WriteString(fmt.Sprintf("   %s\n", line))
+		}
 
-		if placeType == "buffet" {
-			// Для буфетов показываем примеры из меню
-			lines := strings.Split(place.MenuToday, "\n")
-			if len(lines) > 0 {
-				b.WriteString(fmt.Sprintf("   🍽️ %s\n", lines[0]))
+		if place.HasMenu() {
+			// Для буфетов — что есть сегодня, полное меню по кнопке
+			menu, ok, err := models.FindMenu(sc.DB, place.ID, sc.now())
+			if err != nil {
+				return err
+			}
+			if ok && len(menu.Dishes) > 0 {
+				b.WriteString(fmt.Sprintf("   🍽️ Сегодня: %s\n", menuPreview(menu.Dishes)))
+				hasMenu = append(hasMenu, place)
+			} else {
+				b.WriteString("   🍽️ Меню на сегодня не опубликовано\n")
 			}
 		} else if placeType == "copy" {
 			// Для копирок показываем услуги
@@ -251,46 +401,37 @@ func showPlacesList(ctx context.Context, sc Ctx, places []models.Place, placeTyp
 		b.WriteString("\n")
 	}
 
-	kb := sc.API.Messages.NewKeyboardBuilder()
+	kb := sc.API.NewKeyboardBuilder()
+	for _, place := range hasMenu {
+		kb.AddRow().AddCallback("📋 Меню: "+place.Name, schemes.POSITIVE,
+			payload(PlaceMenu, place.ID, sc.now().Format(time.DateOnly)))
+	}
+	for _, place := range places {
+		kb.AddRow().AddCallback("⭐ В избранное: "+place.Name, schemes.DEFAULT,
+			payload(FavAdd, models.FavPlace, place.ID))
+	}
 	kb.AddRow().
-		AddCallback("◀️ К выбору типа", schemes.NEGATIVE, fmt.Sprintf("places_campus_%s", campusID)).
-		AddCallback("🏠 Главное меню", schemes.NEGATIVE, "back_to_menu")
+		AddCallback("◀️ К выбору типа", schemes.NEGATIVE, payload(PlacesCampus, campusID)).
+		AddCallback("🏠 Главное меню", schemes.NEGATIVE, BackToMenu)
 
 	msg := maxbot.NewMessage()
 	setRecipient(msg, recipient)
 	msg.SetText(b.String()).AddKeyboard(kb)
 
-	_, err := sc.API.Messages.Send(ctx, msg)
+	_, err = sc.API.Send(ctx, msg)
 	return err
 }
 
-// Places_OnMessage - обработка текстовых запросов по местам
-func Places_OnMessage(ctx context.Context, sc Ctx, upd *schemes.MessageCreatedUpdate) (bool, error) {
-	text := strings.TrimSpace(strings.ToLower(upd.Message.Body.Text))
-	if text == "" {
-		return false, nil
+// menuPreview - первые блюда меню одной строкой: «Кофе, Чай, Печенье и ещё 2»
+func menuPreview(dishes []models.Dish) string {
+	const shown = 3
+	names := make([]string, 0, shown)
+	for _, d := range dishes[:min(shown, len(dishes))] {
+		names = append(names, d.Name)
 	}
-
-	keywords := []string{"столовая", "буфет", "копирка", "копир", "еда", "печать", "распечатать"}
-	hasKeyword := false
-	for _, keyword := range keywords {
-		if strings.Contains(text, keyword) {
-			hasKeyword = true
-			break
-		}
-	}
-
-	if !hasKeyword {
-		return false, nil
+	text := strings.Join(names, ", ")
+	if len(dishes) > shown {
+		text += fmt.Sprintf(" и ещё %d", len(dishes)-shown)
 	}
-
-	recipient := schemes.Recipient{}
-	if upd.Message.Recipient.ChatId != 0 {
-		recipient.ChatId = upd.Message.Recipient.ChatId
-	} else {
-		recipient.UserId = upd.Message.Sender.UserId
-	}
-
-	err := showCampusSelectionForPlaces(ctx, sc, recipient)
-	return true, err
+	return text
 }
@@ -0,0 +1,550 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// Маршруты профиля и знакомства
+const (
+	ProfileEditFaculty = "profile_edit_faculty"
+	ProfileEditGroup   = "profile_edit_group"
+	ProfileEditCampus  = "profile_edit_campus"
+	ProfileEditForm    = "profile_edit_form"
+	ProfileEditCourse  = "profile_edit_course"
+	ProfileSkip        = "profile_skip"               // пропустить шаг знакомства / вернуться к профилю
+	ProfileSetFaculty  = "profile/faculty/{id}"       // выбрать факультет
+	ProfileFaculties   = "profile/faculties/{offset}" // страница списка факультетов
+	ProfileSetGroup    = "profile/group/{id}"         // выбрать группу
+	ProfileSetCampus   = "profile/campus/{id}"        // выбрать основной корпус
+	ProfileSetCourse   = "profile/course/{id}"        // курс 1..6
+	ProfileSetForm     = "profile/form/{form:str}"    // форма обучения: models.Study*
+)
+
+// ключ Dialog.Data: шаг идёт в рамках знакомства после первого /start
+const dlgOnboarding = "onboarding"
+
+// максимальный курс (специалитет)
+const maxCourse = 6
+
+func registerProfileRoutes(r *Router) {
+	r.Handle(ServiceProfile, func(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, _ Params) error {
+		return showProfile(ctx, sc, upd.Callback.User, upd.Message.Recipient)
+	}, resetDialog)
+	r.Handle(ProfileEditFaculty, func(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, _ Params) error {
+		return profileAskFaculty(ctx, sc, peerFromCallback(upd), upd.Message.Recipient, false)
+	})
+	r.Handle(ProfileEditGroup, func(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, _ Params) error {
+		return profileAskGroup(ctx, sc, peerFromCallback(upd), upd.Message.Recipient, false)
+	})
+	r.Handle(ProfileEditCampus, noParams(profileAskCampus), resetDialog)
+	r.Handle(ProfileEditForm, noParams(profileAskForm), resetDialog)
+	r.Handle(ProfileEditCourse, noParams(profileAskCourse), resetDialog)
+	r.Handle(ProfileSkip, noParams(profileSkip))
+	r.Handle(ProfileSetFaculty, profileSelectFaculty)
+	r.Handle(ProfileFaculties, profileFacultyPage)
+	r.Handle(ProfileSetGroup, profileSelectGroup)
+	r.Handle(ProfileSetCampus, profileSelectCampus, resetDialog)
+	r.Handle(ProfileSetCourse, profileSelectCourse, resetDialog)
+	r.Handle(ProfileSetForm, profileSelectForm, resetDialog)
+}
+
+// ensureUser создаёт профиль при первом обращении
+func ensureUser(sc Ctx, who schemes.User) error {
+	err := sc.DB.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.User{ID: who.UserId, DisplayName: who.Name}).Error
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+	return nil
+}
+
+// markOnboarded отмечает, что знакомство предложено; first — впервые.
+// Профиль к этому времени мог появиться и без /start (группа из «Моего расписания»).
+func markOnboarded(sc Ctx, userID int64) (first bool, err error) {
+	res := sc.DB.Model(&models.User{}).Where("id = ? AND NOT onboarded", userID).Update("onboarded", true)
+	if res.Error != nil {
+		return false, fmt.Errorf("failed to update user: %w", res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// loadUser - профиль со всеми связями; ok=false, если профиля нет
+func loadUser(sc Ctx, userID int64) (user models.User, ok bool, err error) {
+	err = sc.DB.Preload("Faculty").Preload("Department").Preload("Group").Preload("Campus").
+		First(&user, userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.User{}, false, nil
+	}
+	if err != nil {
+		return models.User{}, false, fmt.Errorf("failed to fetch user: %w", err)
+	}
+	return user, true, nil
+}
+
+// updateUser меняет поля профиля (профиль создаётся, если его ещё нет)
+func updateUser(sc Ctx, who schemes.User, fields map[string]any) error {
+	if err := ensureUser(sc, who); err != nil {
+		return err
+	}
+	if err := sc.DB.Model(&models.User{ID: who.UserId}).Updates(fields).Error; err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	return nil
+}
+
+// startOnboarding - знакомство после первого /start: факультет, затем группа
+func startOnboarding(ctx context.Context, sc Ctx, peer int64, recipient schemes.Recipient) error {
+	msg := maxbot.NewMessage()
+	setRecipient(msg, recipient)
+	msg.SetText("👋 Привет! Давайте познакомимся — тогда бот сразу покажет ваш деканат, расписание и столовые поблизости.")
+	if _, err := sc.API.Send(ctx, msg); err != nil {
+		return err
+	}
+	return profileAskFaculty(ctx, sc, peer, recipient, true)
+}
+
+// profileOnboarding - идёт ли у собеседника знакомство
+func profileOnboarding(ctx context.Context, sc Ctx, peer int64) (DialogState, bool) {
+	d, ok, err := sc.Dialogs.Get(ctx, peer)
+	if err != nil || !ok {
+		return "", false
+	}
+	return d.State, d.Data[dlgOnboarding] != ""
+}
+
+func onboardingData(onboarding bool) map[string]string {
+	if !onboarding {
+		return nil
+	}
+	return map[string]string{dlgOnboarding: "1"}
+}
+
+// ---- факультет ----
+
+// profileAskFaculty - список факультетов кнопками; можно и написать название (состояние profile.faculty)
+func profileAskFaculty(ctx context.Context, sc Ctx, peer int64, recipient schemes.Recipient, onboarding bool) error {
+	if err := sc.Dialogs.Enter(ctx, peer, StateProfileFaculty, onboardingData(onboarding)); err != nil {
+		return err
+	}
+	return profileSendFaculties(ctx, sc, recipient, onboarding, 0)
+}
+
+func profileFacultyPage(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	_, onboarding := profileOnboarding(ctx, sc, peerFromCallback(upd))
+	return profileSendFaculties(ctx, sc, upd.Message.Recipient, onboarding, int(p.ID("offset")))
+}
+
+func profileSendFaculties(ctx context.Context, sc Ctx, recipient schemes.Recipient, onboarding bool, offset int) error {
+	var total int64
+	if err := sc.DB.Model(&models.Faculty{}).Count(&total).Error; err != nil {
+		return fmt.Errorf("failed to count faculties: %w", err)
+	}
+	page := newPage(offset, int(total))
+	var facs []models.Faculty
+	if err := sc.DB.Order("name").Offset(page.Offset).Limit(PageSize).Find(&facs).Error; err != nil {
+		return fmt.Errorf("failed to fetch faculties: %w", err)
+	}
+
+	kb := sc.API.NewKeyboardBuilder()
+	for i := 0; i < len(facs); i += 2 {
+		row := kb.AddRow()
+		row.AddCallback(facs[i].Name, schemes.POSITIVE, payload(ProfileSetFaculty, facs[i].ID))
+		if i+1 < len(facs) {
+			row.AddCallback(facs[i+1].Name, schemes.POSITIVE, payload(ProfileSetFaculty, facs[i+1].ID))
+		}
+	}
+	addPager(kb, page, func(offset int) string { return payload(ProfileFaculties, offset) })
+	profileSkipRow(kb, onboarding)
+
+	msg := maxbot.NewMessage()
+	setRecipient(msg, recipient)
+	msg.SetText("🎓 С какого вы факультета? Выберите кнопкой или напишите название.").AddKeyboard(kb)
+	_, err := sc.API.Send(ctx, msg)
+	return err
+}
+
+// Profile_FacultyOnMessage - название факультета текстом (состояние profile.faculty)
+func Profile_FacultyOnMessage(ctx context.Context, sc Ctx, upd *schemes.MessageCreatedUpdate, d Dialog) (bool, error) {
+	recipient := recipientFromMessage(upd)
+	onboarding := d.Data[dlgOnboarding] != ""
+	query := strings.TrimSpace(upd.GetText())
+	if query == "" {
+		return true, profileReply(ctx, sc, recipient, "Напишите название факультета.", onboarding)
+	}
+
+	ids, err := matchIDs(sc, &models.Faculty{}, "name", query)
+	if err != nil {
+		return true, err
+	}
+	var facs []models.Faculty
+	if err := sc.DB.Where("id IN ?", ids).Order("name").Limit(PageSize).Find(&facs).Error; err != nil {
+		return true, fmt.Errorf("failed to search faculties: %w", err)
+	}
+	for _, f := range facs {
+		if sameName(f.Name, query) {
+			facs = []models.Faculty{f}
+			break
+		}
+	}
+	switch len(facs) {
+	case 0:
+		return true, profileReply(ctx, sc, recipient, "Факультет не найден. Попробуйте иначе.", onboarding)
+	case 1:
+		return true, profileSetFaculty(ctx, sc, upd.Message.Sender, peerFromMessage(upd), recipient, facs[0], onboarding)
+	}
+
+	kb := sc.API.NewKeyboardBuilder()
+	for _, f := range facs {
+		kb.AddRow().AddCallback(f.Name, schemes.POSITIVE, payload(ProfileSetFaculty, f.ID))
+	}
+	profileSkipRow(kb, onboarding)
+	msg := maxbot.NewMessage()
+	setRecipient(msg, recipient)
+	msg.SetText("Нашлось несколько факультетов — выберите свой:").AddKeyboard(kb)
+	_, err = sc.API.Send(ctx, msg)
+	return true, err
+}
+
+func profileSelectFaculty(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	var fac models.Faculty
+	if err := sc.DB.First(&fac, p.ID("id")).Error; err != nil {
+		return profileReply(ctx, sc, upd.Message.Recipient, "Факультет не найден.", false)
+	}
+	state, onboarding := profileOnboarding(ctx, sc, peerFromCallback(upd))
+	onboarding = onboarding && state == StateProfileFaculty
+	return profileSetFaculty(ctx, sc, upd.Callback.User, peerFromCallback(upd), upd.Message.Recipient, fac, onboarding)
+}
+
+// profileSetFaculty сохраняет факультет; кафедра другого факультета сбрасывается
+func profileSetFaculty(ctx context.Context, sc Ctx, who schemes.User, peer int64, recipient schemes.Recipient, fac models.Faculty, onboarding bool) error {
+	if err := updateUser(sc, who, map[string]any{"faculty_id": fac.ID}); err != nil {
+		return err
+	}
+	if err := sc.DB.Model(&models.User{}).
+		Where("id = ? AND department_id IN (?)", who.UserId,
+			sc.DB.Model(&models.Department{}).Select("id").Where("faculty_id <> ?", fac.ID)).
+		Update("department_id", nil).Error; err != nil {
+		return fmt.Errorf("failed to reset department: %w", err)
+	}
+	if onboarding {
+		return profileAskGroup(ctx, sc, peer, recipient, true)
+	}
+	if err := sc.Dialogs.Reset(ctx, peer); err != nil {
+		return err
+	}
+	return showProfile(ctx, sc, who, recipient)
+}
+
+// ---- группа ----
+
+// profileAskGroup - просим номер группы (состояние profile.group)
+func profileAskGroup(ctx context.Context, sc Ctx, peer int64, recipient schemes.Recipient, onboarding bool) error {
+	if err := sc.Dialogs.Enter(ctx, peer, StateProfileGroup, onboardingData(onboarding)); err != nil {
+		return err
+	}
+	return profileReply(ctx, sc, recipient, "👥 Введите номер группы (например, «ИУ1-31Б»):", onboarding)
+}
+
+// Profile_GroupOnMessage - номер группы текстом (состояние profile.group)
+func Profile_GroupOnMessage(ctx context.Context, sc Ctx, upd *schemes.MessageCreatedUpdate, d Dialog) (bool, error) {
+	recipient := recipientFromMessage(upd)
+	onboarding := d.Data[dlgOnboarding] != ""
+	query := strings.TrimSpace(upd.GetText())
+	if query == "" {
+		return true, profileReply(ctx, sc, recipient, "Введите номер группы.", onboarding)
+	}
+
+	ids, err := matchIDs(sc, &models.Group{}, "name", query)
+	if err != nil {
+		return true, err
+	}
+	var groups []models.Group
+	if err := sc.DB.Where("id IN ?", ids).Order("name").Limit(PageSize).Find(&groups).Error; err != nil {
+		return true, fmt.Errorf("failed to search groups: %w", err)
+	}
+	for _, g := range groups {
+		if sameName(g.Name, query) {
+			groups = []models.Group{g}
+			break
+		}
+	}
+	switch len(groups) {
+	case 0:
+		return true, profileReply(ctx, sc, recipient, "Группа не найдена. Попробуйте иначе.", onboarding)
+	case 1:
+		return true, profileSetGroup(ctx, sc, upd.Message.Sender, peerFromMessage(upd), recipient, groups[0], onboarding)
+	}
+
+	kb := sc.API.NewKeyboardBuilder()
+	for _, g := range groups {
+		kb.AddRow().AddCallback(g.Name, schemes.POSITIVE, payload(ProfileSetGroup, g.ID))
+	}
+	profileSkipRow(kb, onboarding)
+	msg := maxbot.NewMessage()
+	setRecipient(msg, recipient)
+	msg.SetText("Нашлось несколько групп — выберите свою:").AddKeyboard(kb)
+	_, err = sc.API.Send(ctx, msg)
+	return true, err
+}
+
+func profileSelectGroup(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	var group models.Group
+	if err := sc.DB.First(&group, p.ID("id")).Error; err != nil {
+		return profileReply(ctx, sc, upd.Message.Recipient, "Группа не найдена.", false)
+	}
+	state, onboarding := profileOnboarding(ctx, sc, peerFromCallback(upd))
+	onboarding = onboarding && state == StateProfileGroup
+	return profileSetGroup(ctx, sc, upd.Callback.User, peerFromCallback(upd), upd.Message.Recipient, group, onboarding)
+}
+
+// profileSetGroup сохраняет группу и достраивает по ней пустые поля профиля:
+// кафедра — по префиксу номера («ИУ1-31Б» -> «ИУ1»), если она с выбранного факультета,
+// корпус — где у группы больше всего занятий. Выбранное пользователем не перезаписывается.
+func profileSetGroup(ctx context.Context, sc Ctx, who schemes.User, peer int64, recipient schemes.Recipient, group models.Group, onboarding bool) error {
+	fields := map[string]any{"group_id": group.ID}
+
+	user, _, err := loadUser(sc, who.UserId)
+	if err != nil {
+		return err
+	}
+	if prefix, _, ok := strings.Cut(group.Name, "-"); ok && user.DepartmentID == nil {
+		dep, err := departmentByName(sc, prefix)
+		if err != nil {
+			return err
+		}
+		if dep != nil && (user.FacultyID == nil || *user.FacultyID == dep.FacultyID) {
+			fields["department_id"] = dep.ID
+			fields["faculty_id"] = dep.FacultyID
+		}
+	}
+	if user.CampusID == nil {
+		var campusIDs []uint
+		if err := sc.DB.Model(&models.Lesson{}).
+			Joins("JOIN lesson_groups lg ON lg.lesson_id = lessons.id").
+			Where("lg.group_id = ? AND lessons.campus_id IS NOT NULL", group.ID).
+			Group("lessons.campus_id").
+			Order("COUNT(*) DESC").
+			Limit(1).
+			Pluck("lessons.campus_id", &campusIDs).Error; err != nil {
+			return fmt.Errorf("failed to guess campus: %w", err)
+		}
+		if len(campusIDs) > 0 {
+			fields["campus_id"] = campusIDs[0]
+		}
+	}
+
+	if err := updateUser(sc, who, fields); err != nil {
+		return err
+	}
+	if err := sc.Dialogs.Reset(ctx, peer); err != nil {
+		return err
+	}
+	if onboarding {
+		return finishOnboarding(ctx, sc, recipient)
+	}
+	return showProfile(ctx, sc, who, recipient)
+}
+
+// departmentByName - кафедра с точно таким названием (без учёта регистра и ё/е), nil — нет такой
+func departmentByName(sc Ctx, name string) (*models.Department, error) {
+	ids, err := matchIDs(sc, &models.Department{}, "name", name)
+	if err != nil {
+		return nil, err
+	}
+	var deps []models.Department
+	if len(ids) > 0 {
+		if err := sc.DB.Where("id IN ?", ids).Find(&deps).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch department: %w", err)
+		}
+	}
+	for _, d := range deps {
+		if sameName(d.Name, name) {
+			return &d, nil
+		}
+	}
+	return nil, nil
+}
+
+// profileSkip - «Пропустить» на шаге знакомства или «К профилю» при правке
+func profileSkip(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate) error {
+	peer := peerFromCallback(upd)
+	state, onboarding := profileOnboarding(ctx, sc, peer)
+	if onboarding && state == StateProfileFaculty {
+		return profileAskGroup(ctx, sc, peer, upd.Message.Recipient, true)
+	}
+	if err := sc.Dialogs.Reset(ctx, peer); err != nil {
+		return err
+	}
+	if onboarding {
+		return finishOnboarding(ctx, sc, upd.Message.Recipient)
+	}
+	return showProfile(ctx, sc, upd.Callback.User, upd.Message.Recipient)
+}
+
+func finishOnboarding(ctx context.Context, sc Ctx, recipient schemes.Recipient) error {
+	msg := maxbot.NewMessage()
+	setRecipient(msg, recipient)
+	msg.SetText("✅ Готово! Изменить данные можно в разделе «👤 Профиль».\n\n" + WelcomeText()).
+		AddKeyboard(MenuKeyboard(sc.API))
+	_, err := sc.API.Send(ctx, msg)
+	return err
+}
+
+// ---- корпус, форма обучения, курс, язык ----
+
+func profileAskCampus(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate) error {
+	var campuses []models.Campus
+	if err := sc.DB.Order("short_name").Find(&campuses).Error; err != nil {
+		return fmt.Errorf("failed to fetch campuses: %w", err)
+	}
+	kb := sc.API.NewKeyboardBuilder()
+	for _, c := range campuses {
+		kb.AddRow().AddCallback(c.FullName, schemes.POSITIVE, payload(ProfileSetCampus, c.ID))
+	}
+	profileSkipRow(kb, false)
+	return profileSendKB(ctx, sc, upd.Message.Recipient, "🏫 В каком корпусе вы учитесь чаще всего?", kb)
+}
+
+func profileSelectCampus(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	var campus models.Campus
+	if err := sc.DB.First(&campus, p.ID("id")).Error; err != nil {
+		return profileReply(ctx, sc, upd.Message.Recipient, "Корпус не найден.", false)
+	}
+	if err := updateUser(sc, upd.Callback.User, map[string]any{"campus_id": campus.ID}); err != nil {
+		return err
+	}
+	return showProfile(ctx, sc, upd.Callback.User, upd.Message.Recipient)
+}
+
+func profileAskForm(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate) error {
+	kb := sc.API.NewKeyboardBuilder()
+	for _, f := range []string{models.StudyFullTime, models.StudyPartTime, models.StudyExtramural} {
+		kb.AddRow().AddCallback(models.StudyFormName(f), schemes.POSITIVE, payload(ProfileSetForm, f))
+	}
+	profileSkipRow(kb, false)
+	return profileSendKB(ctx, sc, upd.Message.Recipient, "📘 Форма обучения:", kb)
+}
+
+func profileSelectForm(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	form := p.Str("form")
+	switch form {
+	case models.StudyFullTime, models.StudyPartTime, models.StudyExtramural:
+	default:
+		return fmt.Errorf("unknown study form: %s", form)
+	}
+	if err := updateUser(sc, upd.Callback.User, map[string]any{"study_form": form}); err != nil {
+		return err
+	}
+	return showProfile(ctx, sc, upd.Callback.User, upd.Message.Recipient)
+}
+
+func profileAskCourse(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate) error {
+	kb := sc.API.NewKeyboardBuilder()
+	row := kb.AddRow()
+	for c := 1; c <= maxCourse; c++ {
+		row.AddCallback(fmt.Sprint(c), schemes.POSITIVE, payload(ProfileSetCourse, c))
+	}
+	profileSkipRow(kb, false)
+	return profileSendKB(ctx, sc, upd.Message.Recipient, "🔢 На каком вы курсе?", kb)
+}
+
+func profileSelectCourse(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	course := p.ID("id")
+	if course < 1 || course > maxCourse {
+		return fmt.Errorf("bad course: %d", course)
+	}
+	if err := updateUser(sc, upd.Callback.User, map[string]any{"course": course}); err != nil {
+		return err
+	}
+	return showProfile(ctx, sc, upd.Callback.User, upd.Message.Recipient)
+}
+
+// ---- экран профиля ----
+
+func showProfile(ctx context.Context, sc Ctx, who schemes.User, recipient schemes.Recipient) error {
+	if err := ensureUser(sc, who); err != nil {
+		return err
+	}
+	user, _, err := loadUser(sc, who.UserId)
+	if err != nil {
+		return err
+	}
+
+	orDash := func(s string) string {
+		if s == "" {
+			return "—"
+		}
+		return s
+	}
+	var fac, dep, group, campus, course string
+	if user.Faculty != nil {
+		fac = user.Faculty.Name
+	}
+	if user.Department != nil {
+		dep = user.Department.Name
+	}
+	if user.Group != nil {
+		group = user.Group.Name
+	}
+	if user.Campus != nil {
+		campus = user.Campus.FullName
+	}
+	if user.Course > 0 {
+		course = fmt.Sprint(user.Course)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "👤 Профиль: %s\n\n", orDash(user.DisplayName))
+	fmt.Fprintf(&b, "🎓 Факультет: %s\n", orDash(fac))
+	fmt.Fprintf(&b, "🏛 Кафедра: %s\n", orDash(dep))
+	fmt.Fprintf(&b, "👥 Группа: %s\n", orDash(group))
+	fmt.Fprintf(&b, "🏫 Корпус: %s\n", orDash(campus))
+	fmt.Fprintf(&b, "📘 Форма обучения: %s\n", orDash(models.StudyFormName(user.StudyForm)))
+	fmt.Fprintf(&b, "🔢 Курс: %s", orDash(course))
+
+	kb := sc.API.NewKeyboardBuilder()
+	kb.AddRow().
+		AddCallback("🎓 Факультет", schemes.DEFAULT, ProfileEditFaculty).
+		AddCallback("👥 Группа", schemes.DEFAULT, ProfileEditGroup)
+	kb.AddRow().
+		AddCallback("🏫 Корпус", schemes.DEFAULT, ProfileEditCampus).
+		AddCallback("📘 Форма", schemes.DEFAULT, ProfileEditForm).
+		AddCallback("🔢 Курс", schemes.DEFAULT, ProfileEditCourse)
+	kb.AddRow().AddCallback("🏠 Главное меню", schemes.NEGATIVE, BackToMenu)
+	return profileSendKB(ctx, sc, recipient, b.String(), kb)
+}
+
+// ---- утилиты ----
+
+// profileSkipRow - «Пропустить» при знакомстве, иначе «К профилю»
+func profileSkipRow(kb *maxbot.Keyboard, onboarding bool) {
+	if onboarding {
+		kb.AddRow().AddCallback("Пропустить", schemes.NEGATIVE, ProfileSkip)
+		return
+	}
+	kb.AddRow().AddCallback("◀️ К профилю", schemes.NEGATIVE, ProfileSkip)
+}
+
+func profileReply(ctx context.Context, sc Ctx, recipient schemes.Recipient, text string, onboarding bool) error {
+	kb := sc.API.NewKeyboardBuilder()
+	profileSkipRow(kb, onboarding)
+	return profileSendKB(ctx, sc, recipient, text, kb)
+}
+
+func profileSendKB(ctx context.Context, sc Ctx, recipient schemes.Recipient, text string, kb *maxbot.Keyboard) error {
+	msg := maxbot.NewMessage()
+	setRecipient(msg, recipient)
+	msg.SetText(text).AddKeyboard(kb)
+	_, err := sc.API.Send(ctx, msg)
+	return err
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+)
+
+// Роутер callback-кнопок.
+//
+// Каждый сервис регистрирует шаблоны payload вида "campus/{id}/map":
+//   - литеральные сегменты сравниваются как есть;
+//   - {name}     — беззнаковое целое (ID записи), доступно через Params.ID;
+//   - {name:str} — произвольный непустой сегмент, доступен через Params.Str.
+//
+// Пересекающиеся шаблоны (такие, под которые подходит один и тот же payload)
+// отклоняются при регистрации, поэтому порядок Handle не важен.
+
+// Params - разобранные параметры payload
+type Params struct {
+	ids  map[string]uint
+	strs map[string]string
+}
+
+// ID возвращает числовой параметр шаблона ({name})
+func (p Params) ID(name string) uint { return p.ids[name] }
+
+// Str возвращает строковый параметр шаблона ({name:str})
+func (p Params) Str(name string) string { return p.strs[name] }
+
+// CallbackHandler - обработчик нажатия кнопки
+type CallbackHandler func(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error
+
+// Middleware оборачивает обработчик (логирование, сброс состояния и т.п.)
+type Middleware func(next CallbackHandler) CallbackHandler
+
+type segKind int
+
+const (
+	segLiteral segKind = iota
+	segID
+	segStr
+)
+
+type segment struct {
+	kind segKind
+	lit  string // для segLiteral
+	name string // для параметров
+}
+
+type route struct {
+	pattern string
+	segs    []segment
+	h       CallbackHandler
+}
+
+type Router struct {
+	routes   []route
+	mw       []Middleware
+	notFound CallbackHandler
+	errs     []error
+}
+
+// NewRouter собирает роутер со всеми кнопками сервисов.
+// Ошибка означает конфликт шаблонов — бот не должен стартовать.
+func NewRouter() (*Router, error) {
+	r := &Router{notFound: unknownCallback}
+	r.Use(recoverCallback)
+
+	registerMenuRoutes(r)
+	registerTeacherRoutes(r)
+	registerDeanRoutes(r)
+	registerCampusRoutes(r)
+	registerPlacesRoutes(r)
+	registerOpenNowRoutes(r)
+	registerFAQRoutes(r)
+	registerTimetableRoutes(r)
+	registerCalendarRoutes(r)
+	registerFavoriteRoutes(r)
+	registerProfileRoutes(r)
+	registerAdminRoutes(r)
+
+	if err := errors.Join(r.errs...); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Use добавляет middleware для всех маршрутов
+func (r *Router) Use(mw ...Middleware) { r.mw = append(r.mw, mw...) }
+
+// Handle регистрирует шаблон. Ошибки копятся и возвращаются из NewRouter.
+func (r *Router) Handle(pattern string, h CallbackHandler, mw ...Middleware) {
+	segs, err := parsePattern(pattern)
+	if err != nil {
+		r.errs = append(r.errs, err)
+		return
+	}
+	for _, rt := range r.routes {
+		if patternsOverlap(rt.segs, segs) {
+			r.errs = append(r.errs, fmt.Errorf("router: pattern %q overlaps with %q", pattern, rt.pattern))
+			return
+		}
+	}
+	for i := len(mw) - 1; i >= 0; i-- {
+		h = mw[i](h)
+	}
+	r.routes = append(r.routes, route{pattern: pattern, segs: segs, h: h})
+}
+
+// Route - точка входа для MessageCallbackUpdate (вызывается из main.go)
+func (r *Router) Route(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate) error {
+	h, p := r.notFound, Params{}
+	for _, rt := range r.routes {
+		if params, ok := matchSegments(rt.segs, upd.Callback.Payload); ok {
+			h, p = rt.h, params
+			break
+		}
+	}
+	for i := len(r.mw) - 1; i >= 0; i-- {
+		h = r.mw[i](h)
+	}
+	return h(ctx, sc, upd, p)
+}
+
+// payload подставляет аргументы в шаблон по порядку: payload("campus/{id}/map", 3) -> "campus/3/map"
+func payload(pattern string, args ...any) string {
+	segs, err := parsePattern(pattern)
+	if err != nil {
+		panic(err)
+	}
+	parts := make([]string, len(segs))
+	n := 0
+	for i, s := range segs {
+		if s.kind == segLiteral {
+			parts[i] = s.lit
+			continue
+		}
+		if n >= len(args) {
+			panic(fmt.Sprintf("payload %q: not enough arguments", pattern))
+		}
+		parts[i] = fmt.Sprint(args[n])
+		n++
+	}
+	if n != len(args) {
+		panic(fmt.Sprintf("payload %q: too many arguments", pattern))
+	}
+	return strings.Join(parts, "/")
+}
+
+func parsePattern(pattern string) ([]segment, error) {
+	if pattern == "" {
+		return nil, errors.New("router: empty pattern")
+	}
+	parts := strings.Split(pattern, "/")
+	segs := make([]segment, 0, len(parts))
+	seen := map[string]bool{}
+	for _, part := range parts {
+		if part == "" {
+			return nil, fmt.Errorf("router: empty segment in %q", pattern)
+		}
+		if !strings.HasPrefix(part, "{") {
+			segs = append(segs, segment{kind: segLiteral, lit: part})
+			continue
+		}
+		if !strings.HasSuffix(part, "}") {
+			return nil, fmt.Errorf("router: bad parameter %q in %q", part, pattern)
+		}
+		name, typ, _ := strings.Cut(part[1:len(part)-1], ":")
+		if name == "" || seen[name] {
+			return nil, fmt.Errorf("router: bad or duplicate parameter name %q in %q", name, pattern)
+		}
+		seen[name] = true
+		switch typ {
+		case "":
+			segs = append(segs, segment{kind: segID, name: name})
+		case "str":
+			segs = append(segs, segment{kind: segStr, name: name})
+		default:
+			return nil, fmt.Errorf("router: unknown parameter type %q in %q", typ, pattern)
+		}
+	}
+	return segs, nil
+}
+
+func matchSegments(segs []segment, raw string) (Params, bool) {
+	parts := strings.Split(raw, "/")
+	if len(parts) != len(segs) {
+		return Params{}, false
+	}
+	p := Params{ids: map[string]uint{}, strs: map[string]string{}}
+	for i, s := range segs {
+		part := parts[i]
+		switch s.kind {
+		case segLiteral:
+			if part != s.lit {
+				return Params{}, false
+			}
+		case segID:
+			id, err := strconv.ParseUint(part, 10, 64)
+			if err != nil {
+				return Params{}, false
+			}
+			p.ids[s.name] = uint(id)
+		case segStr:
+			if part == "" {
+				return Params{}, false
+			}
+			p.strs[s.name] = part
+		}
+	}
+	return p, true
+}
+
+// patternsOverlap - существует ли payload, подходящий под оба шаблона
+func patternsOverlap(a, b []segment) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !segmentsOverlap(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func segmentsOverlap(a, b segment) bool {
+	if a.kind != segLiteral && b.kind != segLiteral {
+		return true
+	}
+	if a.kind == segLiteral && b.kind == segLiteral {
+		return a.lit == b.lit
+	}
+	lit, param := a, b
+	if b.kind == segLiteral {
+		lit, param = b, a
+	}
+	if param.kind == segStr {
+		return true
+	}
+	_, err := strconv.ParseUint(lit.lit, 10, 64)
+	return err == nil
+}
+
+// recoverCallback не даёт панике в одном обработчике уронить цикл апдейтов
+func recoverCallback(next CallbackHandler) CallbackHandler {
+	return func(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) (err error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				err = fmt.Errorf("callback %q panicked: %v", upd.Callback.Payload, rec)
+			}
+		}()
+		return next(ctx, sc, upd, p)
+	}
+}
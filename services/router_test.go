@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+)
+
+func callback(data string) *schemes.MessageCallbackUpdate {
+	return &schemes.MessageCallbackUpdate{Callback: schemes.Callback{Payload: data}}
+}
+
+func TestNewRouter(t *testing.T) {
+	if _, err := NewRouter(); err != nil {
+		t.Fatalf("service routes conflict: %v", err)
+	}
+}
+
+func TestRouterOverlap(t *testing.T) {
+	nop := func(context.Context, Ctx, *schemes.MessageCallbackUpdate, Params) error { return nil }
+	tests := []struct {
+		a, b    string
+		overlap bool
+	}{
+		{"campus/{id}", "campus/{other}", true},
+		{"campus/{id}", "campus/list", false},
+		{"campus/{id}", "campus/7", true},
+		{"campus/{name:str}", "campus/list", true},
+		{"campus/{name:str}", "campus/{id}", true},
+		{"campus/{id}/map", "campus/{id}", false},
+		{"campus/{id}/map", "campus/{id}/info", false},
+		{"faq/{q:str}/{id}", "faq/cat/{id}", true},
+		{"faq/{q:str}/{id}", "faq/{q:str}/next", false},
+		{"menu", "menu", true},
+	}
+	for _, tt := range tests {
+		r := &Router{}
+		r.Handle(tt.a, nop)
+		r.Handle(tt.b, nop)
+		err := errors.Join(r.errs...)
+		if got := err != nil; got != tt.overlap {
+			t.Errorf("%q vs %q: overlap error = %v, want overlap %v", tt.a, tt.b, err, tt.overlap)
+		}
+	}
+}
+
+func TestRouterBadPatterns(t *testing.T) {
+	for _, p := range []string{"", "a//b", "a/{id", "a/{}", "a/{id}/{id}", "a/{id:int}"} {
+		if _, err := parsePattern(p); err == nil {
+			t.Errorf("parsePattern(%q) accepted a bad pattern", p)
+		}
+	}
+}
+
+func TestRouterParams(t *testing.T) {
+	var got Params
+	var hit string
+	handler := func(name string) CallbackHandler {
+		return func(_ context.Context, _ Ctx, _ *schemes.MessageCallbackUpdate, p Params) error {
+			hit, got = name, p
+			return nil
+		}
+	}
+	r := &Router{notFound: handler("notFound")}
+	r.Handle("teacher/{id}", handler("card"))
+	r.Handle("teacher/{id}/colleagues/{offset}", handler("colleagues"))
+	r.Handle("ft/fio/{offset}/{q:str}", handler("fio"))
+	r.Handle("svc_menu", handler("menu"))
+	if err := errors.Join(r.errs...); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		data string
+		hit  string
+		ids  map[string]uint
+		strs map[string]string
+	}{
+		{"teacher/15", "card", map[string]uint{"id": 15}, nil},
+		{"teacher/15/colleagues/10", "colleagues", map[string]uint{"id": 15, "offset": 10}, nil},
+		{"ft/fio/5/%D0%98%D0%B2", "fio", map[string]uint{"offset": 5}, map[string]string{"q": "%D0%98%D0%B2"}},
+		{"svc_menu", "menu", nil, nil},
+		// не подходят ни под один шаблон
+		{"teacher/abc", "notFound", nil, nil},
+		{"teacher/-1", "notFound", nil, nil},
+		{"teacher/15/", "notFound", nil, nil},
+		{"ft/fio/5/", "notFound", nil, nil},
+		{"unknown", "notFound", nil, nil},
+		{"", "notFound", nil, nil},
+	}
+	for _, tt := range tests {
+		hit, got = "", Params{}
+		if err := r.Route(context.Background(), Ctx{}, callback(tt.data)); err != nil {
+			t.Fatalf("Route(%q): %v", tt.data, err)
+		}
+		if hit != tt.hit {
+			t.Errorf("Route(%q) hit %q, want %q", tt.data, hit, tt.hit)
+			continue
+		}
+		for name, want := range tt.ids {
+			if got.ID(name) != want {
+				t.Errorf("Route(%q): ID(%q) = %d, want %d", tt.data, name, got.ID(name), want)
+			}
+		}
+		for name, want := range tt.strs {
+			if got.Str(name) != want {
+				t.Errorf("Route(%q): Str(%q) = %q, want %q", tt.data, name, got.Str(name), want)
+			}
+		}
+	}
+}
+
+func TestRouterMiddleware(t *testing.T) {
+	var trace []string
+	mw := func(name string) Middleware {
+		return func(next CallbackHandler) CallbackHandler {
+			return func(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+				trace = append(trace, name)
+				return next(ctx, sc, upd, p)
+			}
+		}
+	}
+	r := &Router{notFound: unknownCallback}
+	r.Use(recoverCallback, mw("global"))
+	r.Handle("boom", func(context.Context, Ctx, *schemes.MessageCallbackUpdate, Params) error {
+		trace = append(trace, "handler")
+		panic("boom")
+	}, mw("route1"), mw("route2"))
+
+	err := r.Route(context.Background(), Ctx{}, callback("boom"))
+	if err == nil || !strings.Contains(err.Error(), "panicked") {
+		t.Errorf("panic not recovered: %v", err)
+	}
+	if want := []string{"global", "route1", "route2", "handler"}; !reflect.DeepEqual(trace, want) {
+		t.Errorf("middleware order = %v, want %v", trace, want)
+	}
+}
+
+func TestPayload(t *testing.T) {
+	if got := payload("teacher/{id}/colleagues/{offset}", 15, 10); got != "teacher/15/colleagues/10" {
+		t.Errorf("payload = %q", got)
+	}
+	if got := payload("ft/fio/{offset}/{q:str}", 0, "abc"); got != "ft/fio/0/abc" {
+		t.Errorf("payload = %q", got)
+	}
+	for _, args := range [][]any{{}, {1, 2}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("payload with %d args did not panic", len(args))
+				}
+			}()
+			payload("teacher/{id}", args...)
+		}()
+	}
+}
@@ -2,12 +2,14 @@ package services
 
 import (
 	"context"
-	"fmt"
-	"github.com/Karielka/Hackaton_MAX/models"
+	"time"
+
 	maxbot "github.com/max-messenger/max-bot-api-client-go"
 	"github.com/max-messenger/max-bot-api-client-go/schemes"
+	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
-	"strings"
+
+	"github.com/Karielka/Hackaton_MAX/internal/media"
 )
 
 // Пэйлоады главного меню
@@ -17,119 +19,133 @@ const (
 	ServiceCampusInfo   = "svc_campus_info"
 	ServiceFoodAndCopy  = "svc_food_copy"
 	ServiceFAQ          = "svc_faq"
+	ServiceMyTimetable  = "svc_my_timetable"
+	ServiceFavorites    = "svc_favorites"
+	ServiceProfile      = "svc_profile"
 )
 
-// Пэйлоады для корпусов
-const (
-	CampusShowMap = "campus_show_map"
-)
+// Пэйлоад возврата в главное меню
+const BackToMenu = "back_to_menu"
 
 // Контекст сервисов
 type Ctx struct {
-	API *maxbot.Api
-	DB  *gorm.DB
+	API     Messenger
+	DB      *gorm.DB
+	Dialogs *Dialogs         // состояния диалогов всех сценариев
+	Now     func() time.Time // часы; nil — time.Now (подменяется в симуляторе)
+	Admins  map[int64]bool   // администраторы из ADMIN_IDS; ещё можно добавить в таблицу admins
+	// PublicURL - внешний адрес HTTP-сервера (PUBLIC_URL) для ссылок на подписку; пусто — без ссылок
+	PublicURL string
+	// Media - картинки корпусов (ссылки и файлы из MEDIA_DIR) с кешем токенов MAX; nil — без картинок
+	Media *media.Library
 }
 
-// ГЛАВНЫЙ РОУТЕР КНОПОК (из main.go для MessageCallbackUpdate)
-func Route(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate) error {
-	// Обработка возврата в главное меню
-	if upd.Callback.Payload == "back_to_menu" {
-		// Очищаем состояние поиска если есть
-		peer := ftPeerFromCallback(upd)
-		ftClear(peer)
-		return showMainMenu(ctx, sc, upd.Message.Recipient)
-	}
+// Location - часовой пояс университета: в нём считаются «сегодня» и время занятий
+var Location = time.FixedZone("MSK", 3*60*60)
 
-	// Обработка выбора корпуса для мест (формат: "places_campus_1")
-	if strings.HasPrefix(upd.Callback.Payload, "places_campus_") {
-		return handleCampusSelectionForPlaces(ctx, sc, upd)
+// SetTimezone задаёт часовой пояс по имени IANA (например, Europe/Moscow)
+func SetTimezone(name string) error {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return err
 	}
+	Location = loc
+	return nil
+}
 
-	// Обработка выбора типа места (столовая, буфет, копирка)
-	if strings.HasPrefix(upd.Callback.Payload, "places_canteen_") ||
-		strings.HasPrefix(upd.Callback.Payload, "places_buffet_") ||
-		strings.HasPrefix(upd.Callback.Payload, "places_copy_") {
-		return handlePlaceTypeSelection(ctx, sc, upd)
+// now - текущее время в часовом поясе университета
+func (sc Ctx) now() time.Time {
+	if sc.Now != nil {
+		return sc.Now().In(Location)
 	}
+	return time.Now().In(Location)
+}
 
-	// Обработка возврата к выбору типа мест в корпусе
-	if strings.HasPrefix(upd.Callback.Payload, "places_back_to_campus_") {
-		campusID := strings.TrimPrefix(upd.Callback.Payload, "places_back_to_campus_")
-		var campus models.Campus
-		if err := sc.DB.First(&campus, campusID).Error; err != nil {
-			return fmt.Errorf("failed to fetch campus: %w", err)
-		}
-		return showPlaceTypesMenu(ctx, sc, campus, upd.Message.Recipient)
-	}
+// Кнопки главного меню
+func registerMenuRoutes(r *Router) {
+	r.Handle(BackToMenu, func(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, _ Params) error {
+		return showMainMenu(ctx, sc, upd.Message.Recipient)
+	}, resetDialog)
+}
 
-	// Обработка выбора корпуса (формат: "campus_1", "campus_2")
-	if strings.HasPrefix(upd.Callback.Payload, "campus_") {
-		// Проверяем, это не кнопка "показать на карте"
-		if !strings.HasPrefix(upd.Callback.Payload, CampusShowMap) {
-			return handleCampusSelection(ctx, sc, upd)
-		}
+// noParams адаптирует обработчик без параметров шаблона
+func noParams(h func(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate) error) CallbackHandler {
+	return func(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, _ Params) error {
+		return h(ctx, sc, upd)
 	}
+}
+
+// unknownCallback - ответ на неизвестный payload
+func unknownCallback(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, _ Params) error {
+	msg := maxbot.NewMessage()
+	setRecipient(msg, upd.Message.Recipient)
+	msg.SetText("Неизвестная команда. Нажмите кнопку меню.")
+	_, err := sc.API.Send(ctx, msg)
+	return err
+}
 
-	// Обработка кнопки "Показать на карте"
-	if strings.HasPrefix(upd.Callback.Payload, CampusShowMap) {
-		return handleCampusMap(ctx, sc, upd)
+// HandleMessage - точка входа для MessageCreatedUpdate (вызывается из main.go):
+// /start, /menu — показываем меню (новому пользователю — знакомство),
+// иначе пробуем сценарии, а по умолчанию — снова меню
+func HandleMessage(ctx context.Context, sc Ctx, upd *schemes.MessageCreatedUpdate) error {
+	recipient := recipientFromMessage(upd)
+
+	switch upd.GetCommand() {
+	case "/start", "/menu":
+		// меню прерывает любой незавершённый сценарий
+		peer := peerFromMessage(upd)
+		if err := sc.Dialogs.Reset(ctx, peer); err != nil {
+			return err
+		}
+		if err := ensureUser(sc, upd.Message.Sender); err != nil {
+			return err
+		}
+		if upd.GetCommand() == "/start" {
+			first, err := markOnboarded(sc, upd.Message.Sender.UserId)
+			if err != nil {
+				return err
+			}
+			if first {
+				return startOnboarding(ctx, sc, peer, recipient)
+			}
+		}
+		return showMainMenu(ctx, sc, recipient)
+	case "/admin":
+		return Admin_Handle(ctx, sc, upd)
 	}
 
-	switch upd.Callback.Payload {
-	case ServiceFindTeacher:
-		return FT_ShowModeMenu(ctx, sc, upd)
-
-	// Под-обработчики поиска (выбор режима)
-	case FT_FindByFaculty, FT_FindByDepartment, FT_FindByFIO:
-		return FT_AskForQuery(ctx, sc, upd)
-
-	// Обработчики корпусов
-	case ServiceCampusInfo:
-		return Campus_Handle(ctx, sc, upd)
-
-	// Обработчики мест
-	case ServiceFoodAndCopy:
-		return Places_Handle(ctx, sc, upd)
-
-	// Обработчики деканата
-	case Dean_BackToFacultyMenu:
-		return Dean_ShowModeMenu(ctx, sc, upd)
-
-	// Остальные разделы
-	case ServiceDeanSchedule:
-		return Dean_ShowModeMenu(ctx, sc, upd)
-	case ServiceFAQ:
-		return FAQ_Handle(ctx, sc, upd)
-
-	default:
-		// неизвестный payload
-		msg := maxbot.NewMessage()
-		setRecipient(msg, upd.Message.Recipient)
-		msg.SetText("Неизвестная команда. Нажмите кнопку меню.")
-		_, err := sc.API.Messages.Send(ctx, msg)
+	handled, err := OnMessage(ctx, sc, upd)
+	if err != nil || handled {
 		return err
 	}
+	return showMainMenu(ctx, sc, recipient)
 }
 
-// ОБРАБОТКА ТЕКСТОВЫХ СООБЩЕНИЙ (делегируем в сервис поиска)
+// ОБРАБОТКА ТЕКСТОВЫХ СООБЩЕНИЙ
 func OnMessage(ctx context.Context, sc Ctx, upd *schemes.MessageCreatedUpdate) (bool, error) {
-	// Сначала пробуем обработать как запрос преподавателя
-	if handled, err := FT_OnMessage(ctx, sc, upd); handled || err != nil {
-		return handled, err
+	// 1) активный диалог: текст — ответ на вопрос бота
+	peer := peerFromMessage(upd)
+	d, ok, err := sc.Dialogs.Get(ctx, peer)
+	if err != nil {
+		return false, err
 	}
-
-	// 2) деканат (ожидаем название факультета)
-	if handled, err := Dean_OnMessage(ctx, sc, upd); handled || err != nil {
-		return handled, err
+	if ok {
+		if h, known := dialogHandlers[d.State]; known {
+			return h(ctx, sc, upd, d)
+		}
+		// состояние из старой версии бота — просто забываем
+		if err := sc.Dialogs.Reset(ctx, peer); err != nil {
+			return false, err
+		}
 	}
 
-	// 3) места (столовые, буфеты, копирки)
-	if handled, err := Places_OnMessage(ctx, sc, upd); handled || err != nil {
+	// 2) свободный текст: раздел бота по классификатору намерений
+	if handled, err := Intent_OnMessage(ctx, sc, upd); handled || err != nil {
 		return handled, err
 	}
 
-	// 4) корпуса
-	if handled, err := Campus_OnMessage(ctx, sc, upd); handled || err != nil {
+	// 3) вопрос своими словами — ищем ответ в FAQ
+	if handled, err := FAQ_OnMessage(ctx, sc, upd); handled || err != nil {
 		return handled, err
 	}
 
@@ -142,7 +158,30 @@ func showMainMenu(ctx context.Context, sc Ctx, recipient schemes.Recipient) erro
 	msg := maxbot.NewMessage()
 	setRecipient(msg, recipient)
 	msg.SetText(WelcomeText()).AddKeyboard(MenuKeyboard(sc.API))
-	_, err := sc.API.Messages.Send(ctx, msg)
+	_, err := sc.API.Send(ctx, msg)
+	return err
+}
+
+// recipientFromMessage - кому отвечать на сообщение
+func recipientFromMessage(upd *schemes.MessageCreatedUpdate) schemes.Recipient {
+	if upd.Message.Recipient.ChatId != 0 {
+		return schemes.Recipient{ChatId: upd.Message.Recipient.ChatId}
+	}
+	return schemes.Recipient{UserId: upd.Message.Sender.UserId}
+}
+
+// failedText пишет ошибку в лог и возвращает ответ пользователю без текста драйвера БД
+func failedText(err error, op string) string {
+	log.Error().Err(err).Str("op", op).Msg("request failed")
+	return "Не удалось выполнить запрос, попробуйте позже."
+}
+
+// translateDBError - нарушение ограничения как gorm.ErrDuplicatedKey / gorm.ErrForeignKeyViolated
+// (независимо от TranslateError в конфиге); прочие ошибки без изменений
+func translateDBError(db *gorm.DB, err error) error {
+	if t, ok := db.Dialector.(gorm.ErrorTranslator); ok {
+		return t.Translate(err)
+	}
 	return err
 }
 
@@ -156,8 +195,8 @@ func setRecipient(msg *maxbot.Message, recipient schemes.Recipient) {
 }
 
 // Главное меню (клавиатура)
-func MenuKeyboard(api *maxbot.Api) *maxbot.Keyboard {
-	kb := api.Messages.NewKeyboardBuilder()
+func MenuKeyboard(api Messenger) *maxbot.Keyboard {
+	kb := api.NewKeyboardBuilder()
 	kb.AddRow().
 		AddCallback("1) Поиск препода", schemes.POSITIVE, ServiceFindTeacher).
 		AddCallback("2) Деканат", schemes.POSITIVE, ServiceDeanSchedule)
@@ -165,7 +204,12 @@ func MenuKeyboard(api *maxbot.Api) *maxbot.Keyboard {
 		AddCallback("3) Корпуса", schemes.POSITIVE, ServiceCampusInfo).
 		AddCallback("4) Столовые/копирки", schemes.POSITIVE, ServiceFoodAndCopy)
 	kb.AddRow().
-		AddCallback("5) Частые вопросы", schemes.NEGATIVE, ServiceFAQ)
+		AddCallback("5) Частые вопросы", schemes.NEGATIVE, ServiceFAQ).
+		AddCallback("6) Моё расписание", schemes.POSITIVE, ServiceMyTimetable)
+	kb.AddRow().AddCallback("🟢 Где поесть или распечатать сейчас", schemes.POSITIVE, OpenNowPick)
+	kb.AddRow().
+		AddCallback("⭐ Избранное", schemes.POSITIVE, ServiceFavorites).
+		AddCallback("👤 Профиль", schemes.POSITIVE, ServiceProfile)
 	return kb
 }
 
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+	"gorm.io/gorm"
+
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// Карточки преподавателя и кафедры
+const (
+	FT_Card               = "teacher/{id}"                      // карточка преподавателя
+	FT_Colleagues         = "teacher/{id}/colleagues/{offset}"  // остальные преподаватели кафедры
+	FT_Department         = "department/{id}"                   // карточка кафедры
+	FT_DepartmentTeachers = "department/{id}/teachers/{offset}" // все преподаватели кафедры
+)
+
+// ftShowCard - карточка одного преподавателя
+func ftShowCard(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	var t models.Teacher
+	err := sc.DB.Preload("Department").
+		Preload("Department.Faculty").
+		Preload("Department.Faculty.Institute").
+		First(&t, p.ID("id")).Error
+	if err != nil {
+		return ftSend(ctx, sc, upd.Message.Recipient, "Преподаватель не найден.", ftSearchAgainKB(sc))
+	}
+	// только текущий семестр — как в расписании и «где сейчас»
+	sem, err := currentSemester(sc, sc.now())
+	if err != nil {
+		return err
+	}
+	if t.Lessons, err = teacherLessons(sc, t.ID, sem); err != nil {
+		return err
+	}
+
+	kb := sc.API.NewKeyboardBuilder()
+	if email := strings.TrimSpace(t.Email); email != "" {
+		kb.AddRow().AddLink("✉️ Написать на почту", schemes.DEFAULT, "mailto:"+email)
+	}
+	kb.AddRow().AddCallback("📍 Где сейчас", schemes.POSITIVE, payload(FT_WhereNow, t.ID))
+	if sem != nil && len(t.Lessons) > 0 {
+		kb.AddRow().AddCallback("📅 Экспорт в календарь", schemes.DEFAULT, payload(ICS_Teacher, t.ID))
+	}
+	if t.DepartmentID != 0 {
+		kb.AddRow().
+			AddCallback("🏛 Кафедра", schemes.DEFAULT, payload(FT_Department, t.DepartmentID)).
+			AddCallback("👥 Коллеги", schemes.DEFAULT, payload(FT_Colleagues, t.ID, 0))
+	}
+	favButton(kb, models.FavTeacher, t.ID)
+	ftAddSearchRows(kb)
+	return ftSend(ctx, sc, upd.Message.Recipient, ftFormatTeacher(t), kb)
+}
+
+// ftShowColleagues - преподаватели той же кафедры, кроме выбранного
+func ftShowColleagues(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	var t models.Teacher
+	if err := sc.DB.Preload("Department").First(&t, p.ID("id")).Error; err != nil || t.DepartmentID == 0 {
+		return ftSend(ctx, sc, upd.Message.Recipient, "Кафедра преподавателя не указана.", ftSearchAgainKB(sc))
+	}
+	header := "Коллеги на кафедре " + t.Department.Name
+	return ftSendDepartmentTeachers(ctx, sc, upd.Message.Recipient, header, t.DepartmentID, t.ID, int(p.ID("offset")),
+		func(offset int) string { return payload(FT_Colleagues, t.ID, offset) })
+}
+
+// ftShowDepartmentTeachers - все преподаватели кафедры
+func ftShowDepartmentTeachers(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	var dep models.Department
+	if err := sc.DB.First(&dep, p.ID("id")).Error; err != nil {
+		return ftSend(ctx, sc, upd.Message.Recipient, "Кафедра не найдена.", ftSearchAgainKB(sc))
+	}
+	header := "Преподаватели кафедры " + dep.Name
+	return ftSendDepartmentTeachers(ctx, sc, upd.Message.Recipient, header, dep.ID, 0, int(p.ID("offset")),
+		func(offset int) string { return payload(FT_DepartmentTeachers, dep.ID, offset) })
+}
+
+// ftSendDepartmentTeachers - страница преподавателей кафедры (exclude — кого не показывать, 0 — никого)
+func ftSendDepartmentTeachers(ctx context.Context, sc Ctx, recipient schemes.Recipient, header string, depID, exclude uint, offset int, link func(offset int) string) error {
+	base := func() *gorm.DB {
+		return sc.DB.Model(&models.Teacher{}).Where("department_id = ? AND id <> ?", depID, exclude)
+	}
+	var total int64
+	if err := base().Count(&total).Error; err != nil {
+		return fmt.Errorf("failed to count teachers: %w", err)
+	}
+	if total == 0 {
+		return ftSend(ctx, sc, recipient, header+": никого не нашлось.", ftSearchAgainKB(sc))
+	}
+	page := newPage(offset, int(total))
+
+	var res []models.Teacher
+	if err := base().Preload("Department").Order("full_name").
+		Offset(page.Offset).Limit(PageSize).Find(&res).Error; err != nil {
+		return fmt.Errorf("failed to fetch teachers: %w", err)
+	}
+	return ftSendTeacherList(ctx, sc, recipient, page.Header(header), res, page, link)
+}
+
+// ftShowDepartment - карточка кафедры: кабинет, факультет, институт, число преподавателей
+func ftShowDepartment(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	var dep models.Department
+	err := sc.DB.Preload("Faculty").Preload("Faculty.Institute").First(&dep, p.ID("id")).Error
+	if err != nil {
+		return ftSend(ctx, sc, upd.Message.Recipient, "Кафедра не найдена.", ftSearchAgainKB(sc))
+	}
+	var count int64
+	if err := sc.DB.Model(&models.Teacher{}).Where("department_id = ?", dep.ID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to count teachers: %w", err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "🏛 Кафедра %s\n", dep.Name)
+	if dep.Office != "" {
+		fmt.Fprintf(&b, "🚪 %s\n", dep.Office)
+	}
+	if dep.Faculty.ID != 0 {
+		fmt.Fprintf(&b, "🎓 Факультет: %s\n", dep.Faculty.Name)
+		if dep.Faculty.Institute.ID != 0 {
+			fmt.Fprintf(&b, "🏫 Институт: %s\n", dep.Faculty.Institute.Name)
+		}
+	}
+	fmt.Fprintf(&b, "👥 Преподавателей: %d", count)
+
+	kb := sc.API.NewKeyboardBuilder()
+	if count > 0 {
+		kb.AddRow().AddCallback("👥 Преподаватели кафедры", schemes.POSITIVE, payload(FT_DepartmentTeachers, dep.ID, 0))
+	}
+	ftAddSearchRows(kb)
+	return ftSend(ctx, sc, upd.Message.Recipient, b.String(), kb)
+}
+
+// ftFormatTeacher - текст карточки: предмет, кафедра, контакты, расписание
+func ftFormatTeacher(t models.Teacher) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "👤 %s\n", t.FullName)
+	if t.Subject != "" {
+		fmt.Fprintf(&b, "📚 Предмет: %s\n", t.Subject)
+	}
+
+	fac, inst, dep := "—", "—", "—"
+	if t.Department.ID != 0 {
+		dep = t.Department.Name
+		if t.Department.Office != "" {
+			dep += " (" + t.Department.Office + ")"
+		}
+		if t.Department.Faculty.ID != 0 {
+			fac = t.Department.Faculty.Name
+			if t.Department.Faculty.Institute.ID != 0 {
+				inst = t.Department.Faculty.Institute.Name
+			}
+		}
+	}
+	fmt.Fprintf(&b, "🏛 Кафедра: %s\n🎓 Факультет: %s\n🏫 Институт: %s\n", dep, fac, inst)
+
+	// контакты — только заполненные
+	for _, f := range []struct{ label, value string }{
+		{"🚪 Кабинет", t.OfficeRoom},
+		{"☎️ Телефон", t.Phone},
+		{"🕒 Консультации", t.OfficeHours},
+		{"✉️ Почта", t.Email},
+	} {
+		if v := strings.TrimSpace(f.value); v != "" {
+			fmt.Fprintf(&b, "%s: %s\n", f.label, v)
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(ftFormatSchedule(t.Lessons))
+	return b.String()
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// сколько дней вперёд ищем ближайшее занятие
+const whereNowHorizonDays = 14
+
+// ftWhereNow - «где преподаватель сейчас»: текущее или ближайшее занятие по расписанию
+func ftWhereNow(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	var t models.Teacher
+	if err := sc.DB.Preload("Department.Faculty").First(&t, p.ID("id")).Error; err != nil {
+		msg := maxbot.NewMessage()
+		setRecipient(msg, upd.Message.Recipient)
+		msg.SetText("Преподаватель не найден.")
+		_, err := sc.API.Send(ctx, msg)
+		return err
+	}
+
+	now := sc.now()
+	sem, err := currentSemester(sc, now)
+	if err != nil {
+		return err
+	}
+	lessons, err := teacherLessons(sc, t.ID, sem)
+	if err != nil {
+		return err
+	}
+	cur, next, nextDay := locateTeacher(lessons, now, sem)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📍 %s\n\n", t.FullName)
+	switch {
+	case cur != nil:
+		fmt.Fprintf(&b, "🟢 Сейчас на занятии (до %s):\n%s\n", models.FormatMinute(cur.EndMinute), formatWhere(*cur))
+	case next != nil:
+		fmt.Fprintf(&b, "⚪ Сейчас занятия нет. %s\n", departmentHint(t.Department))
+	}
+	if next != nil {
+		fmt.Fprintf(&b, "\n⏭ Дальше: %s, %s–%s\n%s\n",
+			relativeDay(now, nextDay), models.FormatMinute(next.StartMinute), models.FormatMinute(next.EndMinute),
+			formatWhere(*next))
+	}
+	if cur == nil && next == nil {
+		b.WriteString("По расписанию занятий сейчас и в ближайшие две недели нет.\n")
+		b.WriteString(departmentHint(t.Department))
+	}
+
+	kb := sc.API.NewKeyboardBuilder()
+	kb.AddRow().AddCallback("🔄 Обновить", schemes.POSITIVE, payload(FT_WhereNow, t.ID))
+	kb.AddRow().AddCallback("🏠 Главное меню", schemes.NEGATIVE, BackToMenu)
+
+	msg := maxbot.NewMessage()
+	setRecipient(msg, upd.Message.Recipient)
+	msg.SetText(b.String()).AddKeyboard(kb)
+	_, err = sc.API.Send(ctx, msg)
+	return err
+}
+
+// teacherLessons - занятия преподавателя в семестре (и занятия без семестра)
+func teacherLessons(sc Ctx, teacherID uint, sem *models.Semester) ([]models.Lesson, error) {
+	q := sc.DB.Where("teacher_id = ?", teacherID).
+		Preload("Campus").
+		Order("weekday, start_minute")
+	if sem != nil {
+		q = q.Where("semester_id = ? OR semester_id IS NULL", sem.ID)
+	}
+	var lessons []models.Lesson
+	if err := q.Find(&lessons).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch lessons: %w", err)
+	}
+	return lessons, nil
+}
+
+// locateTeacher возвращает идущее сейчас занятие и ближайшее следующее (с его датой).
+// lessons должны быть отсортированы по времени начала.
+func locateTeacher(lessons []models.Lesson, now time.Time, sem *models.Semester) (cur, next *models.Lesson, nextDay time.Time) {
+	nowMin := now.Hour()*60 + now.Minute()
+	for d := 0; d < whereNowHorizonDays; d++ {
+		day := now.AddDate(0, 0, d)
+		for _, l := range lessonsOn(lessons, day, sem) {
+			if d == 0 && l.StartMinute <= nowMin {
+				if nowMin < l.EndMinute {
+					cur = &l
+				}
+				continue
+			}
+			return cur, &l, day
+		}
+	}
+	return cur, nil, time.Time{}
+}
+
+// formatWhere - предмет, аудитория и корпус с адресом
+func formatWhere(l models.Lesson) string {
+	var b strings.Builder
+	b.WriteString(l.Subject)
+	if l.LessonType != "" {
+		fmt.Fprintf(&b, " (%s)", models.LessonTypeName(l.LessonType))
+	}
+	if l.Room != "" {
+		fmt.Fprintf(&b, "\nАуд. %s", l.Room)
+	}
+	if l.Campus != nil && l.Campus.ID != 0 {
+		fmt.Fprintf(&b, "\n🏫 %s", l.Campus.FullName)
+		if l.Campus.Address != "" {
+			fmt.Fprintf(&b, ", %s", l.Campus.Address)
+		}
+	}
+	return b.String()
+}
+
+// departmentHint - куда идти, если занятий нет
+func departmentHint(d models.Department) string {
+	if d.ID == 0 {
+		return "Попробуйте связаться с преподавателем по почте."
+	}
+	hint := fmt.Sprintf("Лучше поискать преподавателя на кафедре %s", d.Name)
+	if d.Office != "" {
+		hint += fmt.Sprintf(" (%s)", d.Office)
+	}
+	if d.Faculty.ID != 0 {
+		hint += fmt.Sprintf(", факультет %s", d.Faculty.Name)
+	}
+	return hint + "."
+}
+
+// relativeDay - «сегодня», «завтра» или «Ср 17.09»
+func relativeDay(now, day time.Time) string {
+	y1, m1, d1 := now.Date()
+	y2, m2, d2 := day.Date()
+	today := time.Date(y1, m1, d1, 0, 0, 0, 0, time.UTC)
+	other := time.Date(y2, m2, d2, 0, 0, 0, 0, time.UTC)
+	switch int(other.Sub(today).Hours() / 24) {
+	case 0:
+		return "сегодня"
+	case 1:
+		return "завтра"
+	default:
+		return fmt.Sprintf("%s %s", weekdayName(models.IsoWeekday(day)), day.Format("02.01"))
+	}
+}
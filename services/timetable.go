@@ -0,0 +1,354 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	"github.com/max-messenger/max-bot-api-client-go/schemes"
+	"gorm.io/gorm"
+
+	"github.com/Karielka/Hackaton_MAX/models"
+)
+
+// Маршруты «Моё расписание»
+const (
+	TT_SelectGroup = "tt/group/{id}"      // выбрать и запомнить группу
+	TT_ChangeGroup = "tt_change_group"    // ввести другую группу
+	TT_Show        = "tt/{id}/{view:str}" // расписание группы: today | tomorrow | week
+)
+
+// Виды расписания
+const (
+	ttToday    = "today"
+	ttTomorrow = "tomorrow"
+	ttWeek     = "week"
+)
+
+func registerTimetableRoutes(r *Router) {
+	r.Handle(ServiceMyTimetable, noParams(TT_Handle), resetDialog)
+	r.Handle(TT_ChangeGroup, noParams(ttAskForGroup), resetDialog)
+	r.Handle(TT_SelectGroup, ttSelectGroup, resetDialog)
+	r.Handle(TT_Show, ttShow)
+}
+
+// TT_Handle - кнопка «Моё расписание»: сразу показываем сегодня, если группа уже выбрана
+func TT_Handle(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate) error {
+	var user models.User
+	err := sc.DB.Preload("Group").First(&user, upd.Callback.User.UserId).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && user.Group == nil) {
+		return ttAskForGroup(ctx, sc, upd)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch user: %w", err)
+	}
+	return ttSendTimetable(ctx, sc, upd.Message.Recipient, *user.Group, ttToday)
+}
+
+// ttAskForGroup - просим ввести группу (состояние tt.group)
+func ttAskForGroup(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate) error {
+	if err := sc.Dialogs.Enter(ctx, peerFromCallback(upd), StateTTGroup, nil); err != nil {
+		return err
+	}
+	msg := maxbot.NewMessage()
+	setRecipient(msg, upd.Message.Recipient)
+	msg.SetText("Введите номер группы (например, «ИУ1-31Б»):")
+	_, err := sc.API.Send(ctx, msg)
+	return err
+}
+
+// TT_OnMessage - ввод группы (состояние tt.group)
+func TT_OnMessage(ctx context.Context, sc Ctx, upd *schemes.MessageCreatedUpdate, _ Dialog) (bool, error) {
+	recipient := recipientFromMessage(upd)
+	query := strings.TrimSpace(upd.GetText())
+	if query == "" {
+		return true, ttReply(ctx, sc, recipient, "Введите номер группы.")
+	}
+
+	ids, err := matchIDs(sc, &models.Group{}, "name", query)
+	if err != nil {
+		return true, ttReply(ctx, sc, recipient, failedText(err, "timetable: find group"))
+	}
+	var groups []models.Group
+	if err := sc.DB.Where("id IN ?", ids).
+		Order("name").Limit(10).Find(&groups).Error; err != nil {
+		return true, ttReply(ctx, sc, recipient, failedText(err, "timetable: find group"))
+	}
+	if len(groups) == 0 {
+		return true, ttReply(ctx, sc, recipient, "Группа не найдена. Попробуйте иначе.")
+	}
+
+	chosen := -1
+	if len(groups) == 1 {
+		chosen = 0
+	}
+	for i, g := range groups {
+		if sameName(g.Name, query) {
+			chosen = i
+		}
+	}
+	if chosen >= 0 {
+		if err := ttRememberGroup(sc, upd.Message.Sender, groups[chosen].ID); err != nil {
+			return true, err
+		}
+		if err := sc.Dialogs.Reset(ctx, peerFromMessage(upd)); err != nil {
+			return true, err
+		}
+		return true, ttSendTimetable(ctx, sc, recipient, groups[chosen], ttToday)
+	}
+
+	// несколько вариантов — предлагаем выбрать кнопкой, ввод тоже остаётся доступен
+	kb := sc.API.NewKeyboardBuilder()
+	for _, g := range groups {
+		kb.AddRow().AddCallback(g.Name, schemes.POSITIVE, payload(TT_SelectGroup, g.ID))
+	}
+	kb.AddRow().AddCallback("🏠 Главное меню", schemes.NEGATIVE, BackToMenu)
+
+	msg := maxbot.NewMessage()
+	setRecipient(msg, recipient)
+	msg.SetText("Нашлось несколько групп — выберите свою или уточните номер:").AddKeyboard(kb)
+	_, err = sc.API.Send(ctx, msg)
+	return true, err
+}
+
+// ttSelectGroup - выбор группы кнопкой
+func ttSelectGroup(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	var group models.Group
+	if err := sc.DB.First(&group, p.ID("id")).Error; err != nil {
+		return ttReply(ctx, sc, upd.Message.Recipient, "Группа не найдена.")
+	}
+	if err := ttRememberGroup(sc, upd.Callback.User, group.ID); err != nil {
+		return err
+	}
+	return ttSendTimetable(ctx, sc, upd.Message.Recipient, group, ttToday)
+}
+
+// ttShow - переключение «сегодня / завтра / неделя»
+func ttShow(ctx context.Context, sc Ctx, upd *schemes.MessageCallbackUpdate, p Params) error {
+	view := p.Str("view")
+	switch view {
+	case ttToday, ttTomorrow, ttWeek:
+	default:
+		return fmt.Errorf("unknown timetable view: %s", view)
+	}
+	var group models.Group
+	if err := sc.DB.First(&group, p.ID("id")).Error; err != nil {
+		return ttReply(ctx, sc, upd.Message.Recipient, "Группа не найдена.")
+	}
+	return ttSendTimetable(ctx, sc, upd.Message.Recipient, group, view)
+}
+
+// ttRememberGroup запоминает группу пользователя, чтобы дальше хватало одного нажатия
+func ttRememberGroup(sc Ctx, who schemes.User, groupID uint) error {
+	return updateUser(sc, who, map[string]any{"group_id": groupID})
+}
+
+func ttSendTimetable(ctx context.Context, sc Ctx, recipient schemes.Recipient, group models.Group, view string) error {
+	now := sc.now()
+	sem, err := currentSemester(sc, now)
+	if err != nil {
+		return err
+	}
+	lessons, err := groupLessons(sc, group.ID, sem)
+	if err != nil {
+		return err
+	}
+
+	var text string
+	switch view {
+	case ttToday:
+		text = fmt.Sprintf("📅 %s — сегодня\n\n%s", group.Name, formatDay(lessons, now, sem))
+	case ttTomorrow:
+		text = fmt.Sprintf("📅 %s — завтра\n\n%s", group.Name, formatDay(lessons, now.AddDate(0, 0, 1), sem))
+	case ttWeek:
+		text = fmt.Sprintf("📅 %s — эта неделя\n\n%s", group.Name, formatWeek(lessons, now, sem))
+	}
+
+	kb := sc.API.NewKeyboardBuilder()
+	kb.AddRow().
+		AddCallback("Сегодня", schemes.POSITIVE, payload(TT_Show, group.ID, ttToday)).
+		AddCallback("Завтра", schemes.POSITIVE, payload(TT_Show, group.ID, ttTomorrow)).
+		AddCallback("Неделя", schemes.POSITIVE, payload(TT_Show, group.ID, ttWeek))
+	if len(lessons) > 0 {
+		kb.AddRow().AddCallback("📅 Экспорт в календарь", schemes.DEFAULT, payload(ICS_Group, group.ID))
+	}
+	kb.AddRow().
+		AddCallback("🔄 Сменить группу", schemes.NEGATIVE, TT_ChangeGroup).
+		AddCallback("🏠 Главное меню", schemes.NEGATIVE, BackToMenu)
+
+	msg := maxbot.NewMessage()
+	setRecipient(msg, recipient)
+	msg.SetText(text).AddKeyboard(kb)
+	_, err = sc.API.Send(ctx, msg)
+	return err
+}
+
+func ttReply(ctx context.Context, sc Ctx, recipient schemes.Recipient, text string) error {
+	msg := maxbot.NewMessage()
+	setRecipient(msg, recipient)
+	msg.SetText(text)
+	_, err := sc.API.Send(ctx, msg)
+	return err
+}
+
+// ---- общие помощники расписания ----
+
+// currentSemester - семестр, идущий на дату t (последний начавшийся); nil, если семестров нет
+func currentSemester(sc Ctx, t time.Time) (*models.Semester, error) {
+	var sem models.Semester
+	err := sc.DB.Where("start_date <= ?", t.Format(time.DateOnly)).
+		Order("start_date DESC").First(&sem).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch semester: %w", err)
+	}
+	return &sem, nil
+}
+
+// weekParity - чётность недели даты t; пусто, если семестр неизвестен
+func weekParity(sem *models.Semester, t time.Time) string {
+	if sem == nil {
+		return models.ParityAny
+	}
+	return models.ParityAt(sem.StartDate, t)
+}
+
+// groupLessons - занятия группы в семестре (и занятия без семестра)
+func groupLessons(sc Ctx, groupID uint, sem *models.Semester) ([]models.Lesson, error) {
+	q := sc.DB.Model(&models.Lesson{}).
+		Joins("JOIN lesson_groups lg ON lg.lesson_id = lessons.id").
+		Where("lg.group_id = ?", groupID).
+		Preload("Campus").
+		Preload("Teacher").
+		Order("weekday, start_minute")
+	if sem != nil {
+		q = q.Where("lessons.semester_id = ? OR lessons.semester_id IS NULL", sem.ID)
+	}
+	var lessons []models.Lesson
+	if err := q.Find(&lessons).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch lessons: %w", err)
+	}
+	return lessons, nil
+}
+
+// lessonsOn - занятия, которые проходят в конкретный день (с учётом чётности)
+func lessonsOn(lessons []models.Lesson, day time.Time, sem *models.Semester) []models.Lesson {
+	if sem != nil && day.After(sem.EndDate.AddDate(0, 0, 1)) {
+		return nil // семестр закончился
+	}
+	parity := weekParity(sem, day)
+	wd := models.IsoWeekday(day)
+	var out []models.Lesson
+	for _, l := range lessons {
+		if l.Weekday == wd && l.OccursIn(parity) {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+func formatDay(lessons []models.Lesson, day time.Time, sem *models.Semester) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s", weekdayName(models.IsoWeekday(day)), day.Format("02.01"))
+	if p := parityName(weekParity(sem, day)); p != "" {
+		fmt.Fprintf(&b, " (%s неделя)", p)
+	}
+	b.WriteString("\n")
+	todays := lessonsOn(lessons, day, sem)
+	if len(todays) == 0 {
+		b.WriteString("Занятий нет 🎉")
+		return b.String()
+	}
+	for _, l := range todays {
+		fmt.Fprintf(&b, "%s\n", formatLesson(l))
+	}
+	return b.String()
+}
+
+func formatWeek(lessons []models.Lesson, now time.Time, sem *models.Semester) string {
+	monday := now.AddDate(0, 0, 1-models.IsoWeekday(now))
+	var b strings.Builder
+	if p := parityName(weekParity(sem, monday)); p != "" {
+		fmt.Fprintf(&b, "Неделя: %s\n\n", p)
+	}
+	empty := true
+	for i := 0; i < 7; i++ {
+		day := monday.AddDate(0, 0, i)
+		dayLessons := lessonsOn(lessons, day, sem)
+		if len(dayLessons) == 0 {
+			continue
+		}
+		empty = false
+		fmt.Fprintf(&b, "%s %s\n", weekdayName(models.IsoWeekday(day)), day.Format("02.01"))
+		for _, l := range dayLessons {
+			fmt.Fprintf(&b, "  %s\n", formatLesson(l))
+		}
+	}
+	if empty {
+		b.WriteString("На этой неделе занятий нет 🎉")
+	}
+	return b.String()
+}
+
+// formatLesson - одна строка занятия: время, предмет, тип, аудитория, корпус, чётность
+// (и преподаватель, если он подгружен)
+func formatLesson(l models.Lesson) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s–%s %s", models.FormatMinute(l.StartMinute), models.FormatMinute(l.EndMinute), l.Subject)
+	if l.LessonType != "" {
+		fmt.Fprintf(&b, " (%s)", models.LessonTypeName(l.LessonType))
+	}
+	if l.Room != "" {
+		fmt.Fprintf(&b, ", ауд. %s", l.Room)
+	}
+	if l.Campus != nil && l.Campus.ID != 0 {
+		fmt.Fprintf(&b, ", %s", l.Campus.ShortName)
+	}
+	switch l.WeekParity {
+	case models.ParityOdd:
+		b.WriteString(" [нечёт.]")
+	case models.ParityEven:
+		b.WriteString(" [чёт.]")
+	}
+	if l.Teacher.ID != 0 {
+		fmt.Fprintf(&b, " — %s", shortFIO(l.Teacher.FullName))
+	}
+	return b.String()
+}
+
+func weekdayName(wd int) string {
+	if wd < 1 || wd >= len(models.WeekdayShort) {
+		return "?"
+	}
+	return models.WeekdayShort[wd]
+}
+
+func parityName(p string) string {
+	switch p {
+	case models.ParityOdd:
+		return "нечётная"
+	case models.ParityEven:
+		return "чётная"
+	default:
+		return ""
+	}
+}
+
+// shortFIO: «Иванов Иван Иванович» -> «Иванов И. И.»
+func shortFIO(full string) string {
+	parts := strings.Fields(full)
+	if len(parts) == 0 {
+		return full
+	}
+	out := parts[0]
+	for _, p := range parts[1:] {
+		r := []rune(p)
+		out += " " + string(r[0]) + "."
+	}
+	return out
+}